@@ -0,0 +1,222 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var drsBehaviorAllowedValues = []string{
+	string(types.DrsBehaviorManual),
+	string(types.DrsBehaviorPartiallyAutomated),
+	string(types.DrsBehaviorFullyAutomated),
+}
+
+var clusterDasConfigInfoServiceStateAllowedValues = []string{
+	string(types.ClusterDasConfigInfoServiceStateEnabled),
+	string(types.ClusterDasConfigInfoServiceStateDisabled),
+}
+
+var clusterDasConfigInfoVMMonitoringStateAllowedValues = []string{
+	string(types.ClusterDasConfigInfoVmMonitoringStateVmMonitoringDisabled),
+	string(types.ClusterDasConfigInfoVmMonitoringStateVmMonitoringOnly),
+	string(types.ClusterDasConfigInfoVmMonitoringStateVmAndAppMonitoring),
+}
+
+var clusterDasVMSettingsIsolationResponseAllowedValues = []string{
+	string(types.ClusterDasVmSettingsIsolationResponseNone),
+	string(types.ClusterDasVmSettingsIsolationResponsePowerOff),
+	string(types.ClusterDasVmSettingsIsolationResponseShutdown),
+}
+
+var clusterDasVMSettingsRestartPriorityAllowedValues = []string{
+	string(types.ClusterDasVmSettingsRestartPriorityDisabled),
+	string(types.ClusterDasVmSettingsRestartPriorityLowest),
+	string(types.ClusterDasVmSettingsRestartPriorityLow),
+	string(types.ClusterDasVmSettingsRestartPriorityMedium),
+	string(types.ClusterDasVmSettingsRestartPriorityHigh),
+	string(types.ClusterDasVmSettingsRestartPriorityHighest),
+}
+
+// schemaClusterDrsConfigInfo returns schema items for resources that need to
+// work with a ClusterDrsConfigInfo.
+func schemaClusterDrsConfigInfo() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"drs_enabled": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Enable DRS for this cluster.",
+		},
+		"drs_automation_level": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.DrsBehaviorManual),
+			Description:  "The default automation level for all virtual machines in this cluster. Can be one of manual, partiallyAutomated, or fullyAutomated.",
+			ValidateFunc: validation.StringInSlice(drsBehaviorAllowedValues, false),
+		},
+		"drs_migration_threshold": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      3,
+			Description:  "A value between 1 and 5 indicating the threshold of imbalance tolerated between hosts. A lower setting will tolerate more imbalance while a higher setting will tolerate less.",
+			ValidateFunc: validation.IntBetween(1, 5),
+		},
+		"drs_enable_vm_overrides": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "When true, allows individual VM overrides within this cluster to be set.",
+		},
+	}
+}
+
+// expandClusterDrsConfigInfo reads certain ResourceData keys and returns a
+// ClusterDrsConfigInfo.
+func expandClusterDrsConfigInfo(d *schema.ResourceData) *types.ClusterDrsConfigInfo {
+	obj := &types.ClusterDrsConfigInfo{
+		Enabled:                   boolPtr(d.Get("drs_enabled").(bool)),
+		EnableVmBehaviorOverrides: boolPtr(d.Get("drs_enable_vm_overrides").(bool)),
+		DefaultVmBehavior:         types.DrsBehavior(d.Get("drs_automation_level").(string)),
+		VmotionRate:               int32(d.Get("drs_migration_threshold").(int)),
+	}
+	return obj
+}
+
+// flattenClusterDrsConfigInfo reads various fields from a
+// ClusterDrsConfigInfo into the passed in ResourceData.
+func flattenClusterDrsConfigInfo(d *schema.ResourceData, obj types.ClusterDrsConfigInfo) error {
+	if obj.Enabled != nil {
+		d.Set("drs_enabled", *obj.Enabled)
+	}
+	if obj.EnableVmBehaviorOverrides != nil {
+		d.Set("drs_enable_vm_overrides", *obj.EnableVmBehaviorOverrides)
+	}
+	d.Set("drs_automation_level", obj.DefaultVmBehavior)
+	d.Set("drs_migration_threshold", obj.VmotionRate)
+	return nil
+}
+
+// schemaClusterDasConfigInfo returns schema items for resources that need to
+// work with a ClusterDasConfigInfo.
+func schemaClusterDasConfigInfo() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"ha_enabled": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Enable vSphere HA for this cluster.",
+		},
+		"ha_host_monitoring": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.ClusterDasConfigInfoServiceStateEnabled),
+			Description:  "Global setting that controls whether vSphere HA remediates VMs on host failure. Can be one of enabled or disabled.",
+			ValidateFunc: validation.StringInSlice(clusterDasConfigInfoServiceStateAllowedValues, false),
+		},
+		"ha_vm_monitoring": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.ClusterDasConfigInfoVmMonitoringStateVmMonitoringDisabled),
+			Description:  "The type of virtual machine monitoring to use when HA is enabled in the cluster. Can be one of vmMonitoringDisabled, vmMonitoringOnly, or vmAndAppMonitoring.",
+			ValidateFunc: validation.StringInSlice(clusterDasConfigInfoVMMonitoringStateAllowedValues, false),
+		},
+		"ha_admission_control_enabled": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Enable admission control, which ensures that enough resources are reserved in the cluster to tolerate the configured number of host failures.",
+		},
+		"ha_admission_control_host_failure_tolerance": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      1,
+			Description:  "The maximum number of failed hosts that admission control tolerates when making decisions on whether to permit virtual machine operations.",
+			ValidateFunc: validation.IntBetween(1, 31),
+		},
+		"ha_host_isolation_response": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.ClusterDasVmSettingsIsolationResponseNone),
+			Description:  "The action to take on virtual machines when a host has detected that it has been isolated from the rest of the cluster. Can be one of none, powerOff, or shutdown.",
+			ValidateFunc: validation.StringInSlice(clusterDasVMSettingsIsolationResponseAllowedValues, false),
+		},
+		"ha_vm_restart_priority": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.ClusterDasVmSettingsRestartPriorityMedium),
+			Description:  "The default restart priority for affected virtual machines when vSphere detects a host failure. Can be one of disabled, lowest, low, medium, high, or highest.",
+			ValidateFunc: validation.StringInSlice(clusterDasVMSettingsRestartPriorityAllowedValues, false),
+		},
+	}
+}
+
+// expandClusterDasConfigInfo reads certain ResourceData keys and returns a
+// ClusterDasConfigInfo.
+func expandClusterDasConfigInfo(d *schema.ResourceData) *types.ClusterDasConfigInfo {
+	obj := &types.ClusterDasConfigInfo{
+		Enabled:                 boolPtr(d.Get("ha_enabled").(bool)),
+		HostMonitoring:          d.Get("ha_host_monitoring").(string),
+		VmMonitoring:            d.Get("ha_vm_monitoring").(string),
+		AdmissionControlEnabled: boolPtr(d.Get("ha_admission_control_enabled").(bool)),
+		AdmissionControlPolicy: &types.ClusterFailoverLevelAdmissionControlPolicy{
+			FailoverLevel: int32(d.Get("ha_admission_control_host_failure_tolerance").(int)),
+		},
+		DefaultVmSettings: &types.ClusterDasVmSettings{
+			IsolationResponse: d.Get("ha_host_isolation_response").(string),
+			RestartPriority:   d.Get("ha_vm_restart_priority").(string),
+		},
+	}
+	return obj
+}
+
+// flattenClusterDasConfigInfo reads various fields from a
+// ClusterDasConfigInfo into the passed in ResourceData.
+func flattenClusterDasConfigInfo(d *schema.ResourceData, obj types.ClusterDasConfigInfo) error {
+	if obj.Enabled != nil {
+		d.Set("ha_enabled", *obj.Enabled)
+	}
+	if obj.AdmissionControlEnabled != nil {
+		d.Set("ha_admission_control_enabled", *obj.AdmissionControlEnabled)
+	}
+	d.Set("ha_host_monitoring", obj.HostMonitoring)
+	d.Set("ha_vm_monitoring", obj.VmMonitoring)
+	if policy, ok := obj.AdmissionControlPolicy.(*types.ClusterFailoverLevelAdmissionControlPolicy); ok {
+		d.Set("ha_admission_control_host_failure_tolerance", policy.FailoverLevel)
+	}
+	if obj.DefaultVmSettings != nil {
+		d.Set("ha_host_isolation_response", obj.DefaultVmSettings.IsolationResponse)
+		d.Set("ha_vm_restart_priority", obj.DefaultVmSettings.RestartPriority)
+	}
+	return nil
+}
+
+// schemaClusterConfigSpecEx returns schema items for resources that need to
+// work with a ClusterConfigSpecEx, such as clusters.
+func schemaClusterConfigSpecEx() map[string]*schema.Schema {
+	s := schemaClusterDrsConfigInfo()
+	mergeSchema(s, schemaClusterDasConfigInfo())
+	return s
+}
+
+// expandClusterConfigSpecEx reads certain ResourceData keys and returns a
+// ClusterConfigSpecEx.
+func expandClusterConfigSpecEx(d *schema.ResourceData) *types.ClusterConfigSpecEx {
+	obj := &types.ClusterConfigSpecEx{
+		DrsConfig: expandClusterDrsConfigInfo(d),
+		DasConfig: expandClusterDasConfigInfo(d),
+	}
+	return obj
+}
+
+// flattenClusterConfigInfoEx reads various fields from a ClusterConfigInfoEx
+// into the passed in ResourceData.
+func flattenClusterConfigInfoEx(d *schema.ResourceData, obj *types.ClusterConfigInfoEx) error {
+	if err := flattenClusterDrsConfigInfo(d, obj.DrsConfig); err != nil {
+		return err
+	}
+	if err := flattenClusterDasConfigInfo(d, obj.DasConfig); err != nil {
+		return err
+	}
+	return nil
+}
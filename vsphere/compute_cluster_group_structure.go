@@ -0,0 +1,102 @@
+package vsphere
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const clusterGroupIDPrefix = "tf-ClusterGroup"
+
+// schemaClusterGroupInfo returns schema items for resources that need to work
+// with the common parts of a ClusterGroupInfo, such as VM and host groups.
+func schemaClusterGroupInfo() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"compute_cluster_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the cluster to put the group in.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The name of the group. This must be unique in the cluster.",
+			Required:    true,
+			ForceNew:    true,
+		},
+	}
+}
+
+// expandClusterGroupInfo reads certain ResourceData keys and returns a
+// ClusterGroupInfo.
+func expandClusterGroupInfo(d *schema.ResourceData) *types.ClusterGroupInfo {
+	return &types.ClusterGroupInfo{
+		Name:        d.Get("name").(string),
+		UserCreated: boolPtr(true),
+	}
+}
+
+// expandClusterVmGroup reads certain ResourceData keys and returns a
+// ClusterVmGroup.
+func expandClusterVmGroup(client *govmomi.Client, d *schema.ResourceData) (*types.ClusterVmGroup, error) {
+	vms, err := expandClusterVirtualMachineIDs(client, d)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ClusterVmGroup{
+		ClusterGroupInfo: *expandClusterGroupInfo(d),
+		Vm:               vms,
+	}, nil
+}
+
+// expandClusterHostGroup reads certain ResourceData keys and returns a
+// ClusterHostGroup.
+func expandClusterHostGroup(d *schema.ResourceData) *types.ClusterHostGroup {
+	var hosts []types.ManagedObjectReference
+	for _, v := range d.Get("host_system_ids").(*schema.Set).List() {
+		hosts = append(hosts, types.ManagedObjectReference{
+			Type:  "HostSystem",
+			Value: v.(string),
+		})
+	}
+	return &types.ClusterHostGroup{
+		ClusterGroupInfo: *expandClusterGroupInfo(d),
+		Host:             hosts,
+	}
+}
+
+// flattenClusterHostGroup reads various fields from a ClusterHostGroup into
+// the passed in ResourceData.
+func flattenClusterHostGroup(d *schema.ResourceData, obj *types.ClusterHostGroup) error {
+	var hosts []string
+	for _, ref := range obj.Host {
+		hosts = append(hosts, ref.Value)
+	}
+	d.Set("name", obj.Name)
+	return d.Set("host_system_ids", sliceStringsToInterfaces(hosts))
+}
+
+// saveClusterGroupID sets a special ID for a cluster group, composed of the
+// cluster ID and the group name.
+func saveClusterGroupID(d *schema.ResourceData, clusterID, name string) {
+	d.SetId(fmt.Sprintf("%s:%s:%s", clusterGroupIDPrefix, clusterID, name))
+}
+
+// splitClusterGroupID splits a vsphere cluster group resource ID into its
+// respective cluster ID and group name.
+func splitClusterGroupID(raw string) (string, string, error) {
+	s := strings.SplitN(raw, ":", 3)
+	if len(s) != 3 || s[0] != clusterGroupIDPrefix || s[1] == "" || s[2] == "" {
+		return "", "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], s[2], nil
+}
+
+// clusterGroupIDsFromResourceID passes a resource's ID through
+// splitClusterGroupID.
+func clusterGroupIDsFromResourceID(d *schema.ResourceData) (string, string, error) {
+	return splitClusterGroupID(d.Id())
+}
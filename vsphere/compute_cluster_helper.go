@@ -0,0 +1,289 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// clusterFromID locates a ClusterComputeResource by its managed object
+// reference ID.
+func clusterFromID(client *govmomi.Client, id string) (*object.ClusterComputeResource, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "ClusterComputeResource",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	obj, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*object.ClusterComputeResource), nil
+}
+
+// clusterFromAbsolutePath returns an *object.ClusterComputeResource from a
+// given absolute inventory path. If no such cluster is found, an appropriate
+// error will be returned.
+func clusterFromAbsolutePath(client *govmomi.Client, path string) (*object.ClusterComputeResource, error) {
+	finder := find.NewFinder(client.Client, false)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.ClusterComputeResource(ctx, path)
+}
+
+// clusterProperties is a convenience method that wraps fetching the
+// ClusterComputeResource MO from its higher-level object.
+func clusterProperties(cluster *object.ClusterComputeResource) (*mo.ClusterComputeResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ClusterComputeResource
+	if err := cluster.Properties(ctx, cluster.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// createCluster creates a cluster in the supplied host folder with the
+// supplied name and configuration spec.
+func createCluster(f *object.Folder, name string, spec types.ClusterConfigSpecEx) (*object.ClusterComputeResource, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	cluster, err := f.CreateCluster(ctx, name, spec)
+	if err != nil {
+		return nil, err
+	}
+	// CreateCluster returns a nil cluster if we are not connected to vCenter.
+	if cluster == nil {
+		return nil, errors.New(errVirtualCenterOnly)
+	}
+	return cluster, nil
+}
+
+// reconfigureCluster reconfigures a cluster with the supplied spec. The
+// changes are applied incrementally - items not set in the spec are left
+// unchanged.
+func reconfigureCluster(cluster *object.ClusterComputeResource, spec types.BaseComputeResourceConfigSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := cluster.Reconfigure(ctx, spec, true)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// deleteCluster destroys the supplied cluster.
+func deleteCluster(cluster *object.ClusterComputeResource) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := cluster.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// moveClusterToFolder moves a cluster to a given relative host folder path.
+// "Relative" here means relative to a datacenter, which is discovered from the
+// current cluster path.
+func moveClusterToFolder(client *govmomi.Client, cluster *object.ClusterComputeResource, relative string) error {
+	folder, err := hostFolderFromObject(client, cluster, relative)
+	if err != nil {
+		return err
+	}
+	return moveObjectToFolder(cluster.Reference(), folder)
+}
+
+// clusterRules returns the rules currently configured on the supplied
+// cluster.
+func clusterRules(cluster *object.ClusterComputeResource) ([]types.BaseClusterRuleInfo, error) {
+	props, err := clusterProperties(cluster)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := props.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	if !ok {
+		return nil, fmt.Errorf("unexpected configuration type for cluster: %T", props.ConfigurationEx)
+	}
+	return info.Rule, nil
+}
+
+// clusterRuleFromKey locates a rule on the supplied cluster by its key. A nil
+// rule with no error is returned if the rule does not exist.
+func clusterRuleFromKey(cluster *object.ClusterComputeResource, key int32) (types.BaseClusterRuleInfo, error) {
+	rules, err := clusterRules(cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.GetClusterRuleInfo().Key == key {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// clusterRuleFromName locates a rule on the supplied cluster by its name. An
+// error is returned if there is no rule by that name, or if the name matches
+// more than one rule.
+func clusterRuleFromName(cluster *object.ClusterComputeResource, name string) (types.BaseClusterRuleInfo, error) {
+	rules, err := clusterRules(cluster)
+	if err != nil {
+		return nil, err
+	}
+	var found types.BaseClusterRuleInfo
+	for _, rule := range rules {
+		if rule.GetClusterRuleInfo().Name != name {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("multiple rules named %q found on cluster", name)
+		}
+		found = rule
+	}
+	if found == nil {
+		return nil, fmt.Errorf("could not find rule %q on cluster", name)
+	}
+	return found, nil
+}
+
+// reconfigureClusterRule applies a single rule operation to the supplied
+// cluster.
+func reconfigureClusterRule(cluster *object.ClusterComputeResource, op types.ArrayUpdateOperation, info types.BaseClusterRuleInfo, removeKey int32) error {
+	spec := &types.ClusterConfigSpecEx{
+		RulesSpec: []types.ClusterRuleSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: op,
+				},
+				Info: info,
+			},
+		},
+	}
+	if op == types.ArrayUpdateOperationRemove {
+		spec.RulesSpec[0].RemoveKey = removeKey
+	}
+	return reconfigureCluster(cluster, spec)
+}
+
+// createClusterRule adds the supplied rule to the cluster and returns the key
+// that was assigned to it. The key is discovered by comparing the rule keys on
+// the cluster before and after the rule was added, as the reconfigure task
+// does not return it.
+func createClusterRule(cluster *object.ClusterComputeResource, info types.BaseClusterRuleInfo) (int32, error) {
+	before, err := clusterRules(cluster)
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[int32]bool)
+	for _, rule := range before {
+		existing[rule.GetClusterRuleInfo().Key] = true
+	}
+	if err := reconfigureClusterRule(cluster, types.ArrayUpdateOperationAdd, info, 0); err != nil {
+		return 0, err
+	}
+	after, err := clusterRules(cluster)
+	if err != nil {
+		return 0, err
+	}
+	name := info.GetClusterRuleInfo().Name
+	for _, rule := range after {
+		ri := rule.GetClusterRuleInfo()
+		if !existing[ri.Key] && ri.Name == name {
+			return ri.Key, nil
+		}
+	}
+	return 0, fmt.Errorf("could not find newly created rule %q on cluster", name)
+}
+
+// updateClusterRule updates the rule on the cluster that matches the key in
+// the supplied rule info.
+func updateClusterRule(cluster *object.ClusterComputeResource, info types.BaseClusterRuleInfo) error {
+	return reconfigureClusterRule(cluster, types.ArrayUpdateOperationEdit, info, 0)
+}
+
+// deleteClusterRule removes the rule with the supplied key from the cluster.
+func deleteClusterRule(cluster *object.ClusterComputeResource, key int32) error {
+	return reconfigureClusterRule(cluster, types.ArrayUpdateOperationRemove, nil, key)
+}
+
+// clusterGroups returns the VM and host groups currently configured on the
+// supplied cluster.
+func clusterGroups(cluster *object.ClusterComputeResource) ([]types.BaseClusterGroupInfo, error) {
+	props, err := clusterProperties(cluster)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := props.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	if !ok {
+		return nil, fmt.Errorf("unexpected configuration type for cluster: %T", props.ConfigurationEx)
+	}
+	return info.Group, nil
+}
+
+// clusterGroupFromName locates a group on the supplied cluster by its name,
+// which is the group's key. A nil group with no error is returned if the
+// group does not exist.
+func clusterGroupFromName(cluster *object.ClusterComputeResource, name string) (types.BaseClusterGroupInfo, error) {
+	groups, err := clusterGroups(cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		if group.GetClusterGroupInfo().Name == name {
+			return group, nil
+		}
+	}
+	return nil, nil
+}
+
+// reconfigureClusterGroup applies a single group operation to the supplied
+// cluster. Only the group in the operation is sent to the cluster, so other
+// groups and the rest of the cluster configuration are left untouched.
+func reconfigureClusterGroup(cluster *object.ClusterComputeResource, op types.ArrayUpdateOperation, info types.BaseClusterGroupInfo, removeKey string) error {
+	spec := &types.ClusterConfigSpecEx{
+		GroupSpec: []types.ClusterGroupSpec{
+			{
+				ArrayUpdateSpec: types.ArrayUpdateSpec{
+					Operation: op,
+				},
+				Info: info,
+			},
+		},
+	}
+	if op == types.ArrayUpdateOperationRemove {
+		spec.GroupSpec[0].RemoveKey = removeKey
+	}
+	return reconfigureCluster(cluster, spec)
+}
+
+// createClusterGroup adds the supplied group to the cluster.
+func createClusterGroup(cluster *object.ClusterComputeResource, info types.BaseClusterGroupInfo) error {
+	return reconfigureClusterGroup(cluster, types.ArrayUpdateOperationAdd, info, "")
+}
+
+// updateClusterGroup updates the group on the cluster that matches the name
+// in the supplied group info.
+func updateClusterGroup(cluster *object.ClusterComputeResource, info types.BaseClusterGroupInfo) error {
+	return reconfigureClusterGroup(cluster, types.ArrayUpdateOperationEdit, info, "")
+}
+
+// deleteClusterGroup removes the group with the supplied name from the
+// cluster.
+func deleteClusterGroup(cluster *object.ClusterComputeResource, name string) error {
+	return reconfigureClusterGroup(cluster, types.ArrayUpdateOperationRemove, nil, name)
+}
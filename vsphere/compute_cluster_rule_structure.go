@@ -0,0 +1,211 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const clusterRuleIDPrefix = "tf-ClusterRule"
+
+// schemaClusterRuleInfo returns schema items for resources that need to work
+// with the common parts of a ClusterRuleInfo, such as VM affinity rules.
+func schemaClusterRuleInfo() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"compute_cluster_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the cluster to put the rule in.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The name of the rule.",
+			Required:    true,
+		},
+		"enabled": &schema.Schema{
+			Type:        schema.TypeBool,
+			Description: "Enable this rule.",
+			Optional:    true,
+			Default:     true,
+		},
+		"mandatory": &schema.Schema{
+			Type:        schema.TypeBool,
+			Description: "When true, prevents any virtual machine operations that may violate this rule.",
+			Optional:    true,
+			Default:     false,
+		},
+	}
+}
+
+// schemaClusterVMRuleVirtualMachineIDs returns the schema for the set of
+// virtual machines that a VM-to-VM rule applies to.
+func schemaClusterVMRuleVirtualMachineIDs() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Description: "The UUIDs of the virtual machines to run this rule on.",
+		Required:    true,
+		MinItems:    2,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// expandClusterRuleInfo reads certain ResourceData keys and returns a
+// ClusterRuleInfo. The key is populated from the resource ID if the resource
+// has one.
+func expandClusterRuleInfo(d *schema.ResourceData) (*types.ClusterRuleInfo, error) {
+	obj := &types.ClusterRuleInfo{
+		Name:        d.Get("name").(string),
+		Enabled:     boolPtr(d.Get("enabled").(bool)),
+		Mandatory:   boolPtr(d.Get("mandatory").(bool)),
+		UserCreated: boolPtr(true),
+	}
+	if d.Id() != "" {
+		_, key, err := clusterRuleIDsFromResourceID(d)
+		if err != nil {
+			return nil, err
+		}
+		obj.Key = key
+	}
+	return obj, nil
+}
+
+// flattenClusterRuleInfo reads various fields from a ClusterRuleInfo into the
+// passed in ResourceData.
+func flattenClusterRuleInfo(d *schema.ResourceData, obj *types.ClusterRuleInfo) error {
+	d.Set("name", obj.Name)
+	d.Set("enabled", obj.Enabled != nil && *obj.Enabled)
+	d.Set("mandatory", obj.Mandatory != nil && *obj.Mandatory)
+	return nil
+}
+
+// expandClusterVirtualMachineIDs reads the set of virtual machine UUIDs in
+// virtual_machine_ids and returns their managed object references.
+func expandClusterVirtualMachineIDs(client *govmomi.Client, d *schema.ResourceData) ([]types.ManagedObjectReference, error) {
+	var refs []types.ManagedObjectReference
+	for _, v := range d.Get("virtual_machine_ids").(*schema.Set).List() {
+		vm, err := virtualMachineFromUUID(client, v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("error locating virtual machine %q: %s", v.(string), err)
+		}
+		refs = append(refs, vm.Reference())
+	}
+	return refs, nil
+}
+
+// flattenClusterVirtualMachineIDs saves the UUIDs of the supplied
+// virtual machine references to virtual_machine_ids.
+func flattenClusterVirtualMachineIDs(client *govmomi.Client, d *schema.ResourceData, refs []types.ManagedObjectReference) error {
+	var uuids []string
+	for _, ref := range refs {
+		uuid, err := virtualMachineUUIDFromManagedObjectID(client, ref.Value)
+		if err != nil {
+			return fmt.Errorf("error fetching UUID for virtual machine %q: %s", ref.Value, err)
+		}
+		uuids = append(uuids, uuid)
+	}
+	return d.Set("virtual_machine_ids", sliceStringsToInterfaces(uuids))
+}
+
+// expandClusterAffinityRuleSpec reads certain ResourceData keys and returns a
+// ClusterAffinityRuleSpec.
+func expandClusterAffinityRuleSpec(client *govmomi.Client, d *schema.ResourceData) (*types.ClusterAffinityRuleSpec, error) {
+	info, err := expandClusterRuleInfo(d)
+	if err != nil {
+		return nil, err
+	}
+	vms, err := expandClusterVirtualMachineIDs(client, d)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ClusterAffinityRuleSpec{
+		ClusterRuleInfo: *info,
+		Vm:              vms,
+	}, nil
+}
+
+// expandClusterAntiAffinityRuleSpec reads certain ResourceData keys and
+// returns a ClusterAntiAffinityRuleSpec.
+func expandClusterAntiAffinityRuleSpec(client *govmomi.Client, d *schema.ResourceData) (*types.ClusterAntiAffinityRuleSpec, error) {
+	info, err := expandClusterRuleInfo(d)
+	if err != nil {
+		return nil, err
+	}
+	vms, err := expandClusterVirtualMachineIDs(client, d)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ClusterAntiAffinityRuleSpec{
+		ClusterRuleInfo: *info,
+		Vm:              vms,
+	}, nil
+}
+
+// expandClusterVmHostRuleInfo reads certain ResourceData keys and returns a
+// ClusterVmHostRuleInfo.
+func expandClusterVmHostRuleInfo(d *schema.ResourceData) (*types.ClusterVmHostRuleInfo, error) {
+	info, err := expandClusterRuleInfo(d)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ClusterVmHostRuleInfo{
+		ClusterRuleInfo:         *info,
+		VmGroupName:             d.Get("vm_group_name").(string),
+		AffineHostGroupName:     d.Get("affinity_host_group_name").(string),
+		AntiAffineHostGroupName: d.Get("anti_affinity_host_group_name").(string),
+	}, nil
+}
+
+// flattenClusterVmHostRuleInfo reads various fields from a
+// ClusterVmHostRuleInfo into the passed in ResourceData.
+func flattenClusterVmHostRuleInfo(d *schema.ResourceData, obj *types.ClusterVmHostRuleInfo) error {
+	d.Set("vm_group_name", obj.VmGroupName)
+	d.Set("affinity_host_group_name", obj.AffineHostGroupName)
+	d.Set("anti_affinity_host_group_name", obj.AntiAffineHostGroupName)
+	return flattenClusterRuleInfo(d, &obj.ClusterRuleInfo)
+}
+
+// saveClusterRuleID sets a special ID for a cluster rule, composed of the
+// cluster ID and the rule key.
+func saveClusterRuleID(d *schema.ResourceData, clusterID string, key int32) {
+	d.SetId(fmt.Sprintf("%s:%s:%d", clusterRuleIDPrefix, clusterID, key))
+}
+
+// splitClusterRuleID splits a vsphere cluster rule resource ID into its
+// respective cluster ID and rule key.
+func splitClusterRuleID(raw string) (string, int32, error) {
+	s := strings.SplitN(raw, ":", 3)
+	if len(s) != 3 || s[0] != clusterRuleIDPrefix || s[1] == "" || s[2] == "" {
+		return "", 0, fmt.Errorf("corrupt ID: %s", raw)
+	}
+	key, err := strconv.ParseInt(s[2], 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], int32(key), nil
+}
+
+// clusterRuleIDsFromResourceID passes a resource's ID through
+// splitClusterRuleID.
+func clusterRuleIDsFromResourceID(d *schema.ResourceData) (string, int32, error) {
+	return splitClusterRuleID(d.Id())
+}
+
+// splitClusterObjectImportID splits the ID used to import a cluster rule or
+// group, which is the absolute path to the cluster and the name of the rule or
+// group, separated by a colon, ie: /dc1/host/cluster1:rule1.
+func splitClusterObjectImportID(raw string) (string, string, error) {
+	s := strings.SplitN(raw, ":", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return "", "", fmt.Errorf("import ID must be in the format CLUSTER_PATH:NAME, got %q", raw)
+	}
+	if !strings.HasPrefix(s[0], "/") {
+		return "", "", errors.New("cluster path must start with a trailing slash")
+	}
+	return s[0], s[1], nil
+}
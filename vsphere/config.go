@@ -24,6 +24,9 @@ type VSphereClient struct {
 
 	// The specialized tags client SDK imported from vmware/vic.
 	tagsClient *tags.RestClient
+
+	// The guest customization defaults for virtual machines.
+	customizationDefaults customizationDefaults
 }
 
 // TagsClient returns the embedded REST client used for tags, after determining
@@ -67,11 +70,16 @@ type Config struct {
 	Debug         bool
 	DebugPath     string
 	DebugPathRun  string
+
+	// CustomizationDefaults holds the guest customization settings applied to
+	// virtual machines that do not set them.
+	CustomizationDefaults customizationDefaults
 }
 
 // Client returns a new client for accessing VMWare vSphere.
 func (c *Config) Client() (*VSphereClient, error) {
 	client := new(VSphereClient)
+	client.customizationDefaults = c.CustomizationDefaults
 
 	u, err := url.Parse("https://" + c.VSphereServer + "/sdk")
 	if err != nil {
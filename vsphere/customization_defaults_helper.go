@@ -0,0 +1,105 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	// customizationDefaultDomain is the domain used for guest customization
+	// when neither the virtual machine nor the provider set one. Linux guest
+	// customization requires a domain.
+	customizationDefaultDomain = "vsphere.local"
+
+	// customizationDefaultTimeZone is the time zone used for guest
+	// customization when neither the virtual machine nor the provider set one.
+	customizationDefaultTimeZone = "Etc/UTC"
+)
+
+// customizationDefaults holds the guest customization settings from the
+// customization_defaults block of the provider. They apply to all virtual
+// machines that do not set the respective settings themselves.
+type customizationDefaults struct {
+	dnsServers  []string
+	dnsSuffixes []string
+	domain      string
+	timeZone    string
+}
+
+// schemaCustomizationDefaults returns the schema of the
+// customization_defaults block of the provider.
+func schemaCustomizationDefaults() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Guest customization settings for virtual machines that do not set them.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"dns_servers": &schema.Schema{
+					Type:        schema.TypeList,
+					Optional:    true,
+					Description: "The list of DNS servers of customized virtual machines.",
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				"dns_suffixes": &schema.Schema{
+					Type:        schema.TypeList,
+					Optional:    true,
+					Description: "The list of DNS search domains of customized virtual machines.",
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				"domain": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The domain of customized virtual machines.",
+				},
+				"time_zone": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The time zone of customized virtual machines.",
+				},
+			},
+		},
+	}
+}
+
+// expandCustomizationDefaults reads the customization_defaults block of the
+// provider configuration.
+func expandCustomizationDefaults(d *schema.ResourceData) customizationDefaults {
+	var defaults customizationDefaults
+	l := d.Get("customization_defaults").([]interface{})
+	if len(l) < 1 || l[0] == nil {
+		return defaults
+	}
+	raw := l[0].(map[string]interface{})
+	defaults.dnsServers = sliceInterfacesToStrings(raw["dns_servers"].([]interface{}))
+	defaults.dnsSuffixes = sliceInterfacesToStrings(raw["dns_suffixes"].([]interface{}))
+	defaults.domain = raw["domain"].(string)
+	defaults.timeZone = raw["time_zone"].(string)
+	return defaults
+}
+
+// applyCustomizationDefaults fills in the customization settings of a virtual
+// machine that are not set in its configuration, first from the provider
+// defaults, and then from the built-in domain and time zone. DNS servers and
+// suffixes are left empty if neither the virtual machine nor the provider set
+// them.
+func (vm *virtualMachine) applyCustomizationDefaults(defaults customizationDefaults) {
+	if len(vm.dnsServers) < 1 {
+		vm.dnsServers = defaults.dnsServers
+	}
+	if len(vm.dnsSuffixes) < 1 {
+		vm.dnsSuffixes = defaults.dnsSuffixes
+	}
+	if vm.domain == "" {
+		vm.domain = defaults.domain
+	}
+	if vm.domain == "" {
+		vm.domain = customizationDefaultDomain
+	}
+	if vm.timeZone == "" {
+		vm.timeZone = defaults.timeZone
+	}
+	if vm.timeZone == "" {
+		vm.timeZone = customizationDefaultTimeZone
+	}
+}
@@ -0,0 +1,108 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestExpandCustomizationDefaults(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"customization_defaults": schemaCustomizationDefaults(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{})
+	if actual := expandCustomizationDefaults(d); !reflect.DeepEqual(customizationDefaults{}, actual) {
+		t.Fatalf("expected empty defaults, got %#v", actual)
+	}
+
+	d = schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"customization_defaults": []interface{}{
+			map[string]interface{}{
+				"dns_servers":  []interface{}{"10.0.0.2", "10.0.0.3"},
+				"dns_suffixes": []interface{}{"corp.example.com"},
+				"domain":       "corp.example.com",
+				"time_zone":    "America/Toronto",
+			},
+		},
+	})
+	expected := customizationDefaults{
+		dnsServers:  []string{"10.0.0.2", "10.0.0.3"},
+		dnsSuffixes: []string{"corp.example.com"},
+		domain:      "corp.example.com",
+		timeZone:    "America/Toronto",
+	}
+	if actual := expandCustomizationDefaults(d); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+type testApplyCustomizationDefaults struct {
+	Name string
+
+	vm       virtualMachine
+	defaults customizationDefaults
+	expected virtualMachine
+}
+
+func (tc *testApplyCustomizationDefaults) Test(t *testing.T) {
+	tc.vm.applyCustomizationDefaults(tc.defaults)
+	if !reflect.DeepEqual(tc.expected, tc.vm) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, tc.vm)
+	}
+}
+
+func TestApplyCustomizationDefaults(t *testing.T) {
+	cases := []testApplyCustomizationDefaults{
+		{
+			Name: "no defaults",
+			vm:   virtualMachine{},
+			expected: virtualMachine{
+				domain:   "vsphere.local",
+				timeZone: "Etc/UTC",
+			},
+		},
+		{
+			Name: "provider defaults",
+			vm:   virtualMachine{},
+			defaults: customizationDefaults{
+				dnsServers:  []string{"10.0.0.2"},
+				dnsSuffixes: []string{"corp.example.com"},
+				domain:      "corp.example.com",
+				timeZone:    "America/Toronto",
+			},
+			expected: virtualMachine{
+				dnsServers:  []string{"10.0.0.2"},
+				dnsSuffixes: []string{"corp.example.com"},
+				domain:      "corp.example.com",
+				timeZone:    "America/Toronto",
+			},
+		},
+		{
+			Name: "virtual machine settings win",
+			vm: virtualMachine{
+				dnsServers:  []string{"10.1.0.2"},
+				dnsSuffixes: []string{"dmz.example.com"},
+				domain:      "dmz.example.com",
+				timeZone:    "035",
+			},
+			defaults: customizationDefaults{
+				dnsServers:  []string{"10.0.0.2"},
+				dnsSuffixes: []string{"corp.example.com"},
+				domain:      "corp.example.com",
+				timeZone:    "America/Toronto",
+			},
+			expected: virtualMachine{
+				dnsServers:  []string{"10.1.0.2"},
+				dnsSuffixes: []string{"dmz.example.com"},
+				domain:      "dmz.example.com",
+				timeZone:    "035",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
@@ -0,0 +1,425 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// customizationSpecTypeLinux is the type of a customization spec that
+	// customizes Linux guests.
+	customizationSpecTypeLinux = "Linux"
+
+	// customizationSpecTypeWindows is the type of a customization spec that
+	// customizes Windows guests.
+	customizationSpecTypeWindows = "Windows"
+)
+
+const (
+	// virtualMachineIPv6ModeDHCPv6 configures IPv6 addresses with stateful
+	// DHCPv6.
+	virtualMachineIPv6ModeDHCPv6 = "dhcpv6"
+
+	// virtualMachineIPv6ModeSLAAC configures IPv6 addresses with stateless
+	// address autoconfiguration.
+	virtualMachineIPv6ModeSLAAC = "slaac"
+
+	// virtualMachineIPv6ModeStatelessDHCPv6 configures IPv6 addresses with
+	// stateless address autoconfiguration, and other settings with DHCPv6.
+	virtualMachineIPv6ModeStatelessDHCPv6 = "stateless_dhcpv6"
+
+	// virtualMachineIPv6ModeStatic only configures the static IPv6 addresses
+	// of a network interface, if any.
+	virtualMachineIPv6ModeStatic = "static"
+)
+
+var virtualMachineIPv6ModeAllowedValues = []string{
+	virtualMachineIPv6ModeDHCPv6,
+	virtualMachineIPv6ModeSLAAC,
+	virtualMachineIPv6ModeStatelessDHCPv6,
+	virtualMachineIPv6ModeStatic,
+}
+
+// customizationSpecItemFromName fetches the customization spec named name
+// from the CustomizationSpecManager. This requires vCenter.
+func customizationSpecItemFromName(client *govmomi.Client, name string) (*types.CustomizationSpecItem, error) {
+	if err := validateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	csm := object.NewCustomizationSpecManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return csm.GetCustomizationSpec(ctx, name)
+}
+
+// customizationSpecExists checks to see if a customization spec named name
+// exists in the CustomizationSpecManager.
+func customizationSpecExists(client *govmomi.Client, name string) (bool, error) {
+	if err := validateVirtualCenter(client); err != nil {
+		return false, err
+	}
+	csm := object.NewCustomizationSpecManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return csm.DoesCustomizationSpecExist(ctx, name)
+}
+
+// storedCustomizationSpecForVirtualMachine fetches the customization spec
+// named name and overlays the settings that are specific to a single virtual
+// machine on it, namely its host name and the IP settings of its network
+// interfaces. See overlayCustomizationSpec for details.
+func storedCustomizationSpecForVirtualMachine(client *govmomi.Client, name, hostname string, nics []types.CustomizationAdapterMapping) (types.CustomizationSpec, error) {
+	item, err := customizationSpecItemFromName(client, name)
+	if err != nil {
+		return types.CustomizationSpec{}, fmt.Errorf("error fetching customization spec %q: %s", name, err)
+	}
+	log.Printf("[DEBUG] Using stored customization spec %q (type %s)", name, item.Info.Type)
+	return overlayCustomizationSpec(item.Spec, hostname, nics), nil
+}
+
+// overlayCustomizationSpec overlays the host name and the network interface
+// settings of a single virtual machine on a customization spec.
+//
+// The host name replaces the computer name of a Sysprep identity, or the
+// host name of a LinuxPrep identity. A SysprepText identity carries its own
+// answer file and is left alone.
+//
+// The spec gets exactly one adapter mapping per network interface. The IP
+// settings of each interface replace the address, subnet mask, gateway and
+// IPv6 settings of the stored adapter mapping at the same index, if any. The
+// DNS and WINS settings of the stored mapping are kept, unless they are set
+// on the interface.
+func overlayCustomizationSpec(spec types.CustomizationSpec, hostname string, nics []types.CustomizationAdapterMapping) types.CustomizationSpec {
+	if hostname != "" {
+		switch identity := spec.Identity.(type) {
+		case *types.CustomizationSysprep:
+			identity.UserData.ComputerName = &types.CustomizationFixedName{Name: hostname}
+		case *types.CustomizationLinuxPrep:
+			identity.HostName = &types.CustomizationFixedName{Name: hostname}
+		default:
+			log.Printf("[DEBUG] Not setting host name on customization identity of type %T", identity)
+		}
+	}
+
+	nicSettingMap := make([]types.CustomizationAdapterMapping, len(nics))
+	for i, nic := range nics {
+		if i >= len(spec.NicSettingMap) {
+			nicSettingMap[i] = nic
+			continue
+		}
+		stored := spec.NicSettingMap[i]
+		stored.Adapter.Ip = nic.Adapter.Ip
+		stored.Adapter.SubnetMask = nic.Adapter.SubnetMask
+		stored.Adapter.Gateway = nic.Adapter.Gateway
+		stored.Adapter.IpV6Spec = nic.Adapter.IpV6Spec
+		if len(nic.Adapter.DnsServerList) > 0 {
+			stored.Adapter.DnsServerList = nic.Adapter.DnsServerList
+		}
+		if nic.Adapter.DnsDomain != "" {
+			stored.Adapter.DnsDomain = nic.Adapter.DnsDomain
+		}
+		if nic.Adapter.PrimaryWINS != "" {
+			stored.Adapter.PrimaryWINS = nic.Adapter.PrimaryWINS
+		}
+		if nic.Adapter.SecondaryWINS != "" {
+			stored.Adapter.SecondaryWINS = nic.Adapter.SecondaryWINS
+		}
+		nicSettingMap[i] = stored
+	}
+	spec.NicSettingMap = nicSettingMap
+	return spec
+}
+
+// customizationAdapterMapping returns the guest customization settings of a
+// network interface of a virtual machine. The interface uses DHCP if it has
+// no IPv4 address.
+func (n networkInterface) customizationAdapterMapping() (types.CustomizationAdapterMapping, error) {
+	var ipSetting types.CustomizationIPSettings
+	if n.ipv4Address == "" {
+		ipSetting.Ip = &types.CustomizationDhcpIpGenerator{}
+	} else {
+		if n.ipv4PrefixLength == 0 {
+			return types.CustomizationAdapterMapping{}, fmt.Errorf("Error: ipv4_prefix_length argument is empty.")
+		}
+		m := net.CIDRMask(n.ipv4PrefixLength, 32)
+		ipSetting.Ip = &types.CustomizationFixedIp{
+			IpAddress: n.ipv4Address,
+		}
+		ipSetting.SubnetMask = net.IPv4(m[0], m[1], m[2], m[3]).String()
+		ipSetting.Gateway = []string{n.ipv4Gateway}
+	}
+
+	ipv6Spec, err := n.customizationIPv6Spec()
+	if err != nil {
+		return types.CustomizationAdapterMapping{}, err
+	}
+	ipSetting.IpV6Spec = ipv6Spec
+
+	ipSetting.DnsServerList = n.dnsServers
+	ipSetting.DnsDomain = n.dnsDomain
+	ipSetting.PrimaryWINS = n.primaryWINS
+	ipSetting.SecondaryWINS = n.secondaryWINS
+	return types.CustomizationAdapterMapping{Adapter: ipSetting}, nil
+}
+
+// customizationIPv6Spec returns the IPv6 customization settings of a network
+// interface of a virtual machine. The static addresses of the interface are
+// combined with the address generator of its IPv6 mode. When no mode is set,
+// the interface uses DHCPv6 if it has no static addresses. A nil spec is
+// returned if the interface is left without IPv6 settings.
+func (n networkInterface) customizationIPv6Spec() (*types.CustomizationIPSettingsIpV6AddressSpec, error) {
+	spec := &types.CustomizationIPSettingsIpV6AddressSpec{}
+	if n.ipv6Address != "" {
+		spec.Ip = append(spec.Ip, &types.CustomizationFixedIpV6{
+			IpAddress:  n.ipv6Address,
+			SubnetMask: int32(n.ipv6PrefixLength),
+		})
+	}
+	for _, addr := range n.ipv6Addresses {
+		ip, ipnet, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IPv6 address %q: %s", addr, err)
+		}
+		ones, _ := ipnet.Mask.Size()
+		spec.Ip = append(spec.Ip, &types.CustomizationFixedIpV6{
+			IpAddress:  ip.String(),
+			SubnetMask: int32(ones),
+		})
+	}
+	if len(spec.Ip) > 0 && n.ipv6Gateway != "" {
+		spec.Gateway = []string{n.ipv6Gateway}
+	}
+
+	switch n.ipv6Mode {
+	case virtualMachineIPv6ModeDHCPv6:
+		spec.Ip = append(spec.Ip, &types.CustomizationDhcpIpV6Generator{})
+	case virtualMachineIPv6ModeSLAAC:
+		spec.Ip = append(spec.Ip, &types.CustomizationAutoIpV6Generator{})
+	case virtualMachineIPv6ModeStatelessDHCPv6:
+		spec.Ip = append(spec.Ip, &types.CustomizationStatelessIpV6Generator{})
+	case virtualMachineIPv6ModeStatic:
+	case "":
+		if len(spec.Ip) < 1 {
+			spec.Ip = append(spec.Ip, &types.CustomizationDhcpIpV6Generator{})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported ipv6_mode %q", n.ipv6Mode)
+	}
+	if len(spec.Ip) < 1 {
+		return nil, nil
+	}
+	return spec, nil
+}
+
+// validateIPv6CIDR is a schema.SchemaValidateFunc that checks that a string
+// is an IPv6 address with a prefix length, such as 2001:db8::10/64.
+func validateIPv6CIDR(v interface{}, k string) ([]string, []error) {
+	ip, _, err := net.ParseCIDR(v.(string))
+	if err != nil || ip.To4() != nil {
+		return nil, []error{fmt.Errorf("%s: %q is not an IPv6 address with a prefix length, such as 2001:db8::10/64", k, v.(string))}
+	}
+	return nil, nil
+}
+
+// expandCustomizationSpecItem reads the settings of a
+// vsphere_customization_spec resource into a CustomizationSpecItem.
+func expandCustomizationSpecItem(d *schema.ResourceData) (types.CustomizationSpecItem, error) {
+	item := types.CustomizationSpecItem{
+		Info: types.CustomizationSpecInfo{
+			Name:        d.Get("name").(string),
+			Description: d.Get("description").(string),
+		},
+		Spec: types.CustomizationSpec{
+			GlobalIPSettings: types.CustomizationGlobalIPSettings{
+				DnsServerList: sliceInterfacesToStrings(d.Get("dns_server_list").([]interface{})),
+				DnsSuffixList: sliceInterfacesToStrings(d.Get("dns_suffix_list").([]interface{})),
+			},
+		},
+	}
+
+	linux := d.Get("linux_options").([]interface{})
+	windows := d.Get("windows_options").([]interface{})
+	switch {
+	case len(linux) > 0:
+		item.Info.Type = customizationSpecTypeLinux
+		item.Spec.Identity = expandCustomizationLinuxPrep(linux[0].(map[string]interface{}))
+	case len(windows) > 0:
+		item.Info.Type = customizationSpecTypeWindows
+		item.Spec.Identity = expandCustomizationSysprep(windows[0].(map[string]interface{}))
+	default:
+		return item, fmt.Errorf("one of linux_options or windows_options must be set")
+	}
+
+	for _, raw := range d.Get("network_interface").([]interface{}) {
+		nic, err := expandCustomizationAdapterMapping(raw.(map[string]interface{}))
+		if err != nil {
+			return item, err
+		}
+		item.Spec.NicSettingMap = append(item.Spec.NicSettingMap, nic)
+	}
+	return item, nil
+}
+
+// expandCustomizationLinuxPrep reads the linux_options block of a
+// vsphere_customization_spec resource into a LinuxPrep identity. The host
+// name is taken from the virtual machine name, and is normally replaced by
+// the host name of the virtual machine the spec is applied to.
+func expandCustomizationLinuxPrep(raw map[string]interface{}) *types.CustomizationLinuxPrep {
+	return &types.CustomizationLinuxPrep{
+		HostName:   &types.CustomizationVirtualMachineName{},
+		Domain:     raw["domain"].(string),
+		TimeZone:   raw["time_zone"].(string),
+		HwClockUTC: types.NewBool(raw["hw_clock_utc"].(bool)),
+	}
+}
+
+// expandCustomizationSysprep reads the windows_options block of a
+// vsphere_customization_spec resource into a Sysprep identity. The computer
+// name is taken from the virtual machine name, and is normally replaced by
+// the host name of the virtual machine the spec is applied to.
+func expandCustomizationSysprep(raw map[string]interface{}) *types.CustomizationSysprep {
+	sysprep := &types.CustomizationSysprep{
+		GuiUnattended: types.CustomizationGuiUnattended{
+			AutoLogon:      raw["auto_logon"].(bool),
+			AutoLogonCount: int32(raw["auto_logon_count"].(int)),
+			TimeZone:       int32(raw["time_zone"].(int)),
+		},
+		UserData: types.CustomizationUserData{
+			FullName:     raw["full_name"].(string),
+			OrgName:      raw["organization_name"].(string),
+			ComputerName: &types.CustomizationVirtualMachineName{},
+			ProductId:    raw["product_key"].(string),
+		},
+		Identification: types.CustomizationIdentification{
+			JoinWorkgroup: raw["workgroup"].(string),
+			JoinDomain:    raw["join_domain"].(string),
+			DomainAdmin:   raw["domain_admin_user"].(string),
+		},
+	}
+	if v := raw["admin_password"].(string); v != "" {
+		sysprep.GuiUnattended.Password = &types.CustomizationPassword{
+			PlainText: true,
+			Value:     v,
+		}
+	}
+	if v := raw["domain_admin_password"].(string); v != "" {
+		sysprep.Identification.DomainAdminPassword = &types.CustomizationPassword{
+			PlainText: true,
+			Value:     v,
+		}
+	}
+	if cmds := sliceInterfacesToStrings(raw["run_once_command_list"].([]interface{})); len(cmds) > 0 {
+		sysprep.GuiRunOnce = &types.CustomizationGuiRunOnce{
+			CommandList: cmds,
+		}
+	}
+	return sysprep
+}
+
+// expandCustomizationAdapterMapping reads a network_interface block of a
+// vsphere_customization_spec resource into an adapter mapping. An interface
+// without an IPv4 address uses DHCP.
+func expandCustomizationAdapterMapping(raw map[string]interface{}) (types.CustomizationAdapterMapping, error) {
+	var ipSetting types.CustomizationIPSettings
+	if addr := raw["ipv4_address"].(string); addr != "" {
+		prefix := raw["ipv4_netmask"].(int)
+		if prefix == 0 {
+			return types.CustomizationAdapterMapping{}, fmt.Errorf("ipv4_netmask must be set when ipv4_address is %q", addr)
+		}
+		m := net.CIDRMask(prefix, 32)
+		ipSetting.Ip = &types.CustomizationFixedIp{IpAddress: addr}
+		ipSetting.SubnetMask = net.IPv4(m[0], m[1], m[2], m[3]).String()
+		if gw := raw["ipv4_gateway"].(string); gw != "" {
+			ipSetting.Gateway = []string{gw}
+		}
+	} else {
+		ipSetting.Ip = &types.CustomizationDhcpIpGenerator{}
+	}
+	ipSetting.DnsServerList = sliceInterfacesToStrings(raw["dns_server_list"].([]interface{}))
+	ipSetting.DnsDomain = raw["dns_domain"].(string)
+	return types.CustomizationAdapterMapping{Adapter: ipSetting}, nil
+}
+
+// flattenCustomizationSpecItem saves the supplied CustomizationSpecItem to
+// the attributes of a vsphere_customization_spec resource. vCenter stores
+// passwords encrypted, so admin_password and domain_admin_password are not
+// read back and keep their values from the configuration.
+func flattenCustomizationSpecItem(d *schema.ResourceData, item *types.CustomizationSpecItem) error {
+	d.Set("name", item.Info.Name)
+	d.Set("description", item.Info.Description)
+	if err := d.Set("dns_server_list", item.Spec.GlobalIPSettings.DnsServerList); err != nil {
+		return fmt.Errorf("error setting dns_server_list: %s", err)
+	}
+	if err := d.Set("dns_suffix_list", item.Spec.GlobalIPSettings.DnsSuffixList); err != nil {
+		return fmt.Errorf("error setting dns_suffix_list: %s", err)
+	}
+
+	var linux, windows []interface{}
+	switch identity := item.Spec.Identity.(type) {
+	case *types.CustomizationLinuxPrep:
+		hwClockUTC := false
+		if identity.HwClockUTC != nil {
+			hwClockUTC = *identity.HwClockUTC
+		}
+		linux = append(linux, map[string]interface{}{
+			"domain":       identity.Domain,
+			"time_zone":    identity.TimeZone,
+			"hw_clock_utc": hwClockUTC,
+		})
+	case *types.CustomizationSysprep:
+		var cmds []string
+		if identity.GuiRunOnce != nil {
+			cmds = identity.GuiRunOnce.CommandList
+		}
+		windows = append(windows, map[string]interface{}{
+			"full_name":             identity.UserData.FullName,
+			"organization_name":     identity.UserData.OrgName,
+			"product_key":           identity.UserData.ProductId,
+			"admin_password":        d.Get("windows_options.0.admin_password").(string),
+			"auto_logon":            identity.GuiUnattended.AutoLogon,
+			"auto_logon_count":      int(identity.GuiUnattended.AutoLogonCount),
+			"time_zone":             int(identity.GuiUnattended.TimeZone),
+			"workgroup":             identity.Identification.JoinWorkgroup,
+			"join_domain":           identity.Identification.JoinDomain,
+			"domain_admin_user":     identity.Identification.DomainAdmin,
+			"domain_admin_password": d.Get("windows_options.0.domain_admin_password").(string),
+			"run_once_command_list": cmds,
+		})
+	default:
+		log.Printf("[DEBUG] Unsupported customization identity type %T in spec %q", identity, item.Info.Name)
+	}
+	if err := d.Set("linux_options", linux); err != nil {
+		return fmt.Errorf("error setting linux_options: %s", err)
+	}
+	if err := d.Set("windows_options", windows); err != nil {
+		return fmt.Errorf("error setting windows_options: %s", err)
+	}
+
+	var nics []interface{}
+	for _, nic := range item.Spec.NicSettingMap {
+		m := map[string]interface{}{
+			"dns_server_list": nic.Adapter.DnsServerList,
+			"dns_domain":      nic.Adapter.DnsDomain,
+		}
+		if ip, ok := nic.Adapter.Ip.(*types.CustomizationFixedIp); ok {
+			m["ipv4_address"] = ip.IpAddress
+			ones, _ := net.IPMask(net.ParseIP(nic.Adapter.SubnetMask).To4()).Size()
+			m["ipv4_netmask"] = ones
+			if len(nic.Adapter.Gateway) > 0 {
+				m["ipv4_gateway"] = nic.Adapter.Gateway[0]
+			}
+		}
+		nics = append(nics, m)
+	}
+	if err := d.Set("network_interface", nics); err != nil {
+		return fmt.Errorf("error setting network_interface: %s", err)
+	}
+	return nil
+}
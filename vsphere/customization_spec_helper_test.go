@@ -0,0 +1,310 @@
+package vsphere
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testOverlayCustomizationSpec struct {
+	Name string
+
+	spec     types.CustomizationSpec
+	hostname string
+	nics     []types.CustomizationAdapterMapping
+	expected types.CustomizationSpec
+}
+
+func (tc *testOverlayCustomizationSpec) Test(t *testing.T) {
+	actual := overlayCustomizationSpec(tc.spec, tc.hostname, tc.nics)
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func testCustomizationFixedIPMapping(addr, mask, gw string) types.CustomizationAdapterMapping {
+	return types.CustomizationAdapterMapping{
+		Adapter: types.CustomizationIPSettings{
+			Ip:         &types.CustomizationFixedIp{IpAddress: addr},
+			SubnetMask: mask,
+			Gateway:    []string{gw},
+		},
+	}
+}
+
+func TestOverlayCustomizationSpec(t *testing.T) {
+	cases := []testOverlayCustomizationSpec{
+		{
+			Name: "sysprep computer name",
+			spec: types.CustomizationSpec{
+				Identity: &types.CustomizationSysprep{
+					UserData: types.CustomizationUserData{
+						FullName:     "ops",
+						ComputerName: &types.CustomizationVirtualMachineName{},
+					},
+				},
+			},
+			hostname: "win01",
+			expected: types.CustomizationSpec{
+				Identity: &types.CustomizationSysprep{
+					UserData: types.CustomizationUserData{
+						FullName:     "ops",
+						ComputerName: &types.CustomizationFixedName{Name: "win01"},
+					},
+				},
+				NicSettingMap: []types.CustomizationAdapterMapping{},
+			},
+		},
+		{
+			Name: "linux host name",
+			spec: types.CustomizationSpec{
+				Identity: &types.CustomizationLinuxPrep{
+					HostName: &types.CustomizationVirtualMachineName{},
+					Domain:   "example.com",
+				},
+			},
+			hostname: "linux01",
+			expected: types.CustomizationSpec{
+				Identity: &types.CustomizationLinuxPrep{
+					HostName: &types.CustomizationFixedName{Name: "linux01"},
+					Domain:   "example.com",
+				},
+				NicSettingMap: []types.CustomizationAdapterMapping{},
+			},
+		},
+		{
+			Name: "nic settings overlaid, stored DNS kept",
+			spec: types.CustomizationSpec{
+				Identity: &types.CustomizationSysprepText{Value: "<unattend/>"},
+				NicSettingMap: []types.CustomizationAdapterMapping{
+					{
+						Adapter: types.CustomizationIPSettings{
+							Ip:            &types.CustomizationDhcpIpGenerator{},
+							DnsServerList: []string{"10.0.0.2"},
+							DnsDomain:     "corp.example.com",
+						},
+					},
+					{
+						Adapter: types.CustomizationIPSettings{
+							Ip: &types.CustomizationDhcpIpGenerator{},
+						},
+					},
+				},
+			},
+			hostname: "win01",
+			nics: []types.CustomizationAdapterMapping{
+				testCustomizationFixedIPMapping("10.0.0.10", "255.255.255.0", "10.0.0.1"),
+			},
+			expected: types.CustomizationSpec{
+				Identity: &types.CustomizationSysprepText{Value: "<unattend/>"},
+				NicSettingMap: []types.CustomizationAdapterMapping{
+					{
+						Adapter: types.CustomizationIPSettings{
+							Ip:            &types.CustomizationFixedIp{IpAddress: "10.0.0.10"},
+							SubnetMask:    "255.255.255.0",
+							Gateway:       []string{"10.0.0.1"},
+							DnsServerList: []string{"10.0.0.2"},
+							DnsDomain:     "corp.example.com",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "nic DNS and WINS settings override stored ones",
+			spec: types.CustomizationSpec{
+				NicSettingMap: []types.CustomizationAdapterMapping{
+					{
+						Adapter: types.CustomizationIPSettings{
+							Ip:            &types.CustomizationDhcpIpGenerator{},
+							DnsServerList: []string{"10.0.0.2"},
+							DnsDomain:     "corp.example.com",
+							PrimaryWINS:   "10.0.0.4",
+						},
+					},
+				},
+			},
+			nics: []types.CustomizationAdapterMapping{
+				{
+					Adapter: types.CustomizationIPSettings{
+						Ip:            &types.CustomizationDhcpIpGenerator{},
+						DnsServerList: []string{"10.1.0.2"},
+					},
+				},
+			},
+			expected: types.CustomizationSpec{
+				NicSettingMap: []types.CustomizationAdapterMapping{
+					{
+						Adapter: types.CustomizationIPSettings{
+							Ip:            &types.CustomizationDhcpIpGenerator{},
+							DnsServerList: []string{"10.1.0.2"},
+							DnsDomain:     "corp.example.com",
+							PrimaryWINS:   "10.0.0.4",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "more nics than stored mappings",
+			spec: types.CustomizationSpec{},
+			nics: []types.CustomizationAdapterMapping{
+				testCustomizationFixedIPMapping("10.0.0.10", "255.255.255.0", "10.0.0.1"),
+			},
+			expected: types.CustomizationSpec{
+				NicSettingMap: []types.CustomizationAdapterMapping{
+					testCustomizationFixedIPMapping("10.0.0.10", "255.255.255.0", "10.0.0.1"),
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+type testNetworkInterfaceCustomizationAdapterMapping struct {
+	Name string
+
+	nic         networkInterface
+	expected    types.CustomizationAdapterMapping
+	expectedErr *regexp.Regexp
+}
+
+func (tc *testNetworkInterfaceCustomizationAdapterMapping) Test(t *testing.T) {
+	actual, err := tc.nic.customizationAdapterMapping()
+	if err != nil && tc.expectedErr == nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if tc.expectedErr != nil {
+		testMatchError(t, err, tc.expectedErr)
+		return
+	}
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestNetworkInterfaceCustomizationAdapterMapping(t *testing.T) {
+	cases := []testNetworkInterfaceCustomizationAdapterMapping{
+		{
+			Name: "dhcp",
+			nic:  networkInterface{},
+			expected: types.CustomizationAdapterMapping{
+				Adapter: types.CustomizationIPSettings{
+					Ip: &types.CustomizationDhcpIpGenerator{},
+					IpV6Spec: &types.CustomizationIPSettingsIpV6AddressSpec{
+						Ip: []types.BaseCustomizationIpV6Generator{
+							&types.CustomizationDhcpIpV6Generator{},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "dual stack, multiple IPv6 addresses, DNS and WINS",
+			nic: networkInterface{
+				ipv4Address:      "10.0.0.10",
+				ipv4PrefixLength: 24,
+				ipv4Gateway:      "10.0.0.1",
+				ipv6Addresses:    []string{"2001:db8::10/64", "2001:db8:1::10/56"},
+				ipv6Gateway:      "2001:db8::1",
+				dnsServers:       []string{"10.0.0.2", "2001:db8::2"},
+				dnsDomain:        "corp.example.com",
+				primaryWINS:      "10.0.0.4",
+				secondaryWINS:    "10.0.0.5",
+			},
+			expected: types.CustomizationAdapterMapping{
+				Adapter: types.CustomizationIPSettings{
+					Ip:         &types.CustomizationFixedIp{IpAddress: "10.0.0.10"},
+					SubnetMask: "255.255.255.0",
+					Gateway:    []string{"10.0.0.1"},
+					IpV6Spec: &types.CustomizationIPSettingsIpV6AddressSpec{
+						Ip: []types.BaseCustomizationIpV6Generator{
+							&types.CustomizationFixedIpV6{IpAddress: "2001:db8::10", SubnetMask: 64},
+							&types.CustomizationFixedIpV6{IpAddress: "2001:db8:1::10", SubnetMask: 56},
+						},
+						Gateway: []string{"2001:db8::1"},
+					},
+					DnsServerList: []string{"10.0.0.2", "2001:db8::2"},
+					DnsDomain:     "corp.example.com",
+					PrimaryWINS:   "10.0.0.4",
+					SecondaryWINS: "10.0.0.5",
+				},
+			},
+		},
+		{
+			Name: "static address with SLAAC",
+			nic: networkInterface{
+				ipv6Address:      "2001:db8::10",
+				ipv6PrefixLength: 64,
+				ipv6Mode:         virtualMachineIPv6ModeSLAAC,
+			},
+			expected: types.CustomizationAdapterMapping{
+				Adapter: types.CustomizationIPSettings{
+					Ip: &types.CustomizationDhcpIpGenerator{},
+					IpV6Spec: &types.CustomizationIPSettingsIpV6AddressSpec{
+						Ip: []types.BaseCustomizationIpV6Generator{
+							&types.CustomizationFixedIpV6{IpAddress: "2001:db8::10", SubnetMask: 64},
+							&types.CustomizationAutoIpV6Generator{},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "stateless DHCPv6",
+			nic: networkInterface{
+				ipv6Mode: virtualMachineIPv6ModeStatelessDHCPv6,
+			},
+			expected: types.CustomizationAdapterMapping{
+				Adapter: types.CustomizationIPSettings{
+					Ip: &types.CustomizationDhcpIpGenerator{},
+					IpV6Spec: &types.CustomizationIPSettingsIpV6AddressSpec{
+						Ip: []types.BaseCustomizationIpV6Generator{
+							&types.CustomizationStatelessIpV6Generator{},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "static mode without addresses leaves IPv6 unconfigured",
+			nic: networkInterface{
+				ipv6Mode: virtualMachineIPv6ModeStatic,
+			},
+			expected: types.CustomizationAdapterMapping{
+				Adapter: types.CustomizationIPSettings{
+					Ip: &types.CustomizationDhcpIpGenerator{},
+				},
+			},
+		},
+		{
+			Name: "missing IPv4 prefix length",
+			nic: networkInterface{
+				ipv4Address: "10.0.0.10",
+			},
+			expectedErr: regexp.MustCompile("ipv4_prefix_length argument is empty"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestValidateIPv6CIDR(t *testing.T) {
+	for _, v := range []string{"2001:db8::10/64", "fd00::1/128"} {
+		if _, errs := validateIPv6CIDR(v, "ipv6_addresses"); len(errs) > 0 {
+			t.Fatalf("expected %q to be valid, got %s", v, errs[0])
+		}
+	}
+	for _, v := range []string{"2001:db8::10", "10.0.0.10/24", "bogus"} {
+		if _, errs := validateIPv6CIDR(v, "ipv6_addresses"); len(errs) < 1 {
+			t.Fatalf("expected %q to be invalid", v)
+		}
+	}
+}
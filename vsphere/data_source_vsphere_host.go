@@ -1,6 +1,7 @@
 package vsphere
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/hashicorp/terraform/helper/schema"
@@ -21,6 +22,11 @@ func dataSourceVSphereHost() *schema.Resource {
 				Description: "The managed object ID of the datacenter to look for the host in.",
 				Required:    true,
 			},
+			"resource_pool_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host's root resource pool.",
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -41,5 +47,13 @@ func dataSourceVSphereHostRead(d *schema.ResourceData, meta interface{}) error {
 	id := hs.Reference().Value
 	d.SetId(id)
 
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	rp, err := hs.ResourcePool(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching resource pool for host: %s", err)
+	}
+	d.Set("resource_pool_id", rp.Reference().Value)
+
 	return nil
 }
@@ -32,6 +32,11 @@ func TestAccDataSourceVSphereHost(t *testing.T) {
 								"id",
 								testAccDataSourceVSphereHostExpectedRegexp(),
 							),
+							resource.TestMatchResourceAttr(
+								"data.vsphere_host.host",
+								"resource_pool_id",
+								regexp.MustCompile("^(resgroup-[0-9]+|ha-root-pool)$"),
+							),
 						),
 					},
 				},
@@ -69,3 +69,13 @@ func moveDatastoreToFolderRelativeHostSystemID(client *govmomi.Client, ds *objec
 	}
 	return moveObjectToFolder(ds.Reference(), folder)
 }
+
+// moveDatastoreToStoragePod moves a datastore into the datastore cluster
+// (StoragePod) with the supplied managed object ID.
+func moveDatastoreToStoragePod(client *govmomi.Client, ds *object.Datastore, podID string) error {
+	pod, err := storagePodFromID(client, podID)
+	if err != nil {
+		return err
+	}
+	return moveObjectToFolder(ds.Reference(), pod.Folder)
+}
@@ -0,0 +1,163 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// dvPortgroupFromMOID locates a DistributedVirtualPortgroup by its managed
+// object reference ID.
+func dvPortgroupFromMOID(client *govmomi.Client, id string) (*object.DistributedVirtualPortgroup, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "DistributedVirtualPortgroup",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	obj, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*object.DistributedVirtualPortgroup), nil
+}
+
+// dvPortgroupFromAbsolutePath returns a DistributedVirtualPortgroup from a
+// given absolute inventory path. If no such port group is found, or if the
+// network at the path is not a distributed port group, an appropriate error
+// will be returned.
+func dvPortgroupFromAbsolutePath(client *govmomi.Client, path string) (*object.DistributedVirtualPortgroup, error) {
+	finder := find.NewFinder(client.Client, false)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	net, err := finder.Network(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if net.Reference().Type != "DistributedVirtualPortgroup" {
+		return nil, fmt.Errorf("%q is not a distributed port group", path)
+	}
+	return dvPortgroupFromMOID(client, net.Reference().Value)
+}
+
+// dvPortgroupProperties is a convenience method that wraps fetching the
+// DistributedVirtualPortgroup MO from its higher-level object.
+func dvPortgroupProperties(pg *object.DistributedVirtualPortgroup) (*mo.DistributedVirtualPortgroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.DistributedVirtualPortgroup
+	if err := pg.Properties(ctx, pg.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// createDVPortgroup creates a DistributedVirtualPortgroup on the supplied
+// distributed virtual switch with the supplied spec.
+func createDVPortgroup(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, spec types.DVPortgroupConfigSpec) (*object.DistributedVirtualPortgroup, error) {
+	req := &types.CreateDVPortgroup_Task{
+		This: dvs.Reference(),
+		Spec: spec,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.CreateDVPortgroup_Task(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	task := object.NewTask(client.Client, resp.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return dvPortgroupFromMOID(client, info.Result.(types.ManagedObjectReference).Value)
+}
+
+// updateDVPortgroup reconfigures a DistributedVirtualPortgroup with the
+// supplied spec. The spec's ConfigVersion must match the current
+// configuration version of the port group.
+func updateDVPortgroup(pg *object.DistributedVirtualPortgroup, spec types.DVPortgroupConfigSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := pg.Reconfigure(ctx, spec)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// deleteDVPortgroup destroys the supplied DistributedVirtualPortgroup.
+func deleteDVPortgroup(pg *object.DistributedVirtualPortgroup) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := pg.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// dvPortgroupFromKey gets a portgroup object from its key, which is the value
+// that is used in virtual machine network adapter backings along with the
+// switch UUID.
+func dvPortgroupFromKey(client *govmomi.Client, dvsUUID, key string) (*object.DistributedVirtualPortgroup, error) {
+	req := &types.DVSManagerLookupDvPortGroup{
+		This:         *client.ServiceContent.DvSwitchManager,
+		SwitchUuid:   dvsUUID,
+		PortgroupKey: key,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.DVSManagerLookupDvPortGroup(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Returnval == nil {
+		return nil, fmt.Errorf("could not find port group with key %q on switch %q", key, dvsUUID)
+	}
+	return dvPortgroupFromMOID(client, resp.Returnval.Value)
+}
+
+// dvPortgroupPortConnection returns a DistributedVirtualSwitchPortConnection
+// for the port group with the supplied managed object ID, for use in network
+// adapter backings and VMkernel network adapters.
+func dvPortgroupPortConnection(client *govmomi.Client, id string) (*types.DistributedVirtualSwitchPortConnection, error) {
+	pg, err := dvPortgroupFromMOID(client, id)
+	if err != nil {
+		return nil, err
+	}
+	props, err := dvPortgroupProperties(pg)
+	if err != nil {
+		return nil, err
+	}
+	if props.Config.DistributedVirtualSwitch == nil {
+		return nil, fmt.Errorf("port group %q has no distributed virtual switch", id)
+	}
+	dvs, err := dvsFromMOID(client, props.Config.DistributedVirtualSwitch.Value)
+	if err != nil {
+		return nil, err
+	}
+	dvsProps, err := dvsProperties(dvs)
+	if err != nil {
+		return nil, err
+	}
+	return &types.DistributedVirtualSwitchPortConnection{
+		SwitchUuid:   dvsProps.Uuid,
+		PortgroupKey: props.Key,
+	}, nil
+}
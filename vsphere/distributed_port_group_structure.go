@@ -0,0 +1,94 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var distributedVirtualPortgroupPortgroupTypeAllowedValues = []string{
+	string(types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding),
+	string(types.DistributedVirtualPortgroupPortgroupTypeEphemeral),
+}
+
+// schemaDVPortgroupConfigSpec returns schema items for resources that need to
+// work with a DVPortgroupConfigSpec, such as distributed port groups.
+func schemaDVPortgroupConfigSpec() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The name of the port group.",
+		},
+		"description": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The description of the port group.",
+		},
+		"type": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding),
+			Description:  "The port group type. Can be one of earlyBinding (static binding) or ephemeral.",
+			ValidateFunc: validation.StringInSlice(distributedVirtualPortgroupPortgroupTypeAllowedValues, false),
+		},
+		"number_of_ports": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The number of ports in this port group. Cannot be set on ephemeral port groups.",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"auto_expand": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Auto-expands the port group beyond the port count configured in number_of_ports when necessary. Only applicable to static binding port groups.",
+		},
+		"port_name_format": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "A template string to use when creating ports in the port group.",
+		},
+	}
+	mergeSchema(s, schemaVMwareDVSPortSetting())
+	return s
+}
+
+// expandDVPortgroupConfigSpec reads certain ResourceData keys and returns a
+// DVPortgroupConfigSpec. The ConfigVersion of the spec is not populated.
+func expandDVPortgroupConfigSpec(d *schema.ResourceData) types.DVPortgroupConfigSpec {
+	obj := types.DVPortgroupConfigSpec{
+		Name:              d.Get("name").(string),
+		Description:       d.Get("description").(string),
+		Type:              d.Get("type").(string),
+		PortNameFormat:    d.Get("port_name_format").(string),
+		DefaultPortConfig: expandVMwareDVSPortSetting(d),
+	}
+	if obj.Type != string(types.DistributedVirtualPortgroupPortgroupTypeEphemeral) {
+		obj.NumPorts = int32(d.Get("number_of_ports").(int))
+		obj.AutoExpand = boolPtr(d.Get("auto_expand").(bool))
+	}
+	return obj
+}
+
+// flattenDVPortgroupConfigInfo reads various fields from a
+// DVPortgroupConfigInfo into the passed in ResourceData.
+func flattenDVPortgroupConfigInfo(d *schema.ResourceData, obj types.DVPortgroupConfigInfo) error {
+	d.Set("name", obj.Name)
+	d.Set("description", obj.Description)
+	d.Set("type", obj.Type)
+	d.Set("number_of_ports", obj.NumPorts)
+	d.Set("port_name_format", obj.PortNameFormat)
+	d.Set("key", obj.Key)
+	d.Set("config_version", obj.ConfigVersion)
+	if obj.AutoExpand != nil {
+		d.Set("auto_expand", *obj.AutoExpand)
+	}
+	if portConfig, ok := obj.DefaultPortConfig.(*types.VMwareDVSPortSetting); ok {
+		if err := flattenVMwareDVSPortSetting(d, portConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// dvsFromMOID locates a VmwareDistributedVirtualSwitch by its managed object
+// reference ID.
+func dvsFromMOID(client *govmomi.Client, id string) (*object.VmwareDistributedVirtualSwitch, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "VmwareDistributedVirtualSwitch",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	obj, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*object.VmwareDistributedVirtualSwitch), nil
+}
+
+// dvsFromUUID locates a VmwareDistributedVirtualSwitch by its UUID.
+func dvsFromUUID(client *govmomi.Client, uuid string) (*object.VmwareDistributedVirtualSwitch, error) {
+	req := &types.QueryDvsByUuid{
+		This: *client.ServiceContent.DvSwitchManager,
+		Uuid: uuid,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.QueryDvsByUuid(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Returnval == nil {
+		return nil, fmt.Errorf("could not find distributed virtual switch with UUID %q", uuid)
+	}
+	return dvsFromMOID(client, resp.Returnval.Value)
+}
+
+// dvsFromAbsolutePath returns a VmwareDistributedVirtualSwitch from a given
+// absolute inventory path. If no such switch is found, or if the network at
+// the path is not a distributed virtual switch, an appropriate error will be
+// returned.
+func dvsFromAbsolutePath(client *govmomi.Client, path string) (*object.VmwareDistributedVirtualSwitch, error) {
+	finder := find.NewFinder(client.Client, false)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	net, err := finder.Network(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if net.Reference().Type != "VmwareDistributedVirtualSwitch" {
+		return nil, fmt.Errorf("%q is not a VMware distributed virtual switch", path)
+	}
+	return dvsFromMOID(client, net.Reference().Value)
+}
+
+// dvsProperties is a convenience method that wraps fetching the
+// VmwareDistributedVirtualSwitch MO from its higher-level object.
+func dvsProperties(dvs *object.VmwareDistributedVirtualSwitch) (*mo.VmwareDistributedVirtualSwitch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.VmwareDistributedVirtualSwitch
+	if err := dvs.Properties(ctx, dvs.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// createDVS creates a VmwareDistributedVirtualSwitch in the supplied network
+// folder with the supplied spec.
+func createDVS(client *govmomi.Client, f *object.Folder, spec types.DVSCreateSpec) (*object.VmwareDistributedVirtualSwitch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := f.CreateDVS(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return dvsFromMOID(client, info.Result.(types.ManagedObjectReference).Value)
+}
+
+// updateDVSConfiguration reconfigures a VmwareDistributedVirtualSwitch with
+// the supplied spec. The spec's ConfigVersion must match the current
+// configuration version of the switch.
+func updateDVSConfiguration(dvs *object.VmwareDistributedVirtualSwitch, spec *types.VMwareDVSConfigSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := dvs.Reconfigure(ctx, spec)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// enableDVSNetworkResourceManagement enables or disables Network I/O Control
+// on a VmwareDistributedVirtualSwitch.
+func enableDVSNetworkResourceManagement(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, enabled bool) error {
+	req := &types.EnableNetworkResourceManagement{
+		This:   dvs.Reference(),
+		Enable: enabled,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.EnableNetworkResourceManagement(ctx, client, req)
+	return err
+}
+
+// upgradeDVS upgrades a VmwareDistributedVirtualSwitch to the supplied
+// product version.
+func upgradeDVS(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, version string) error {
+	req := &types.PerformDvsProductSpecOperation_Task{
+		This:      dvs.Reference(),
+		Operation: string(types.DistributedVirtualSwitchProductSpecOperationTypeUpgrade),
+		ProductSpec: &types.DistributedVirtualSwitchProductSpec{
+			Version: version,
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	resp, err := methods.PerformDvsProductSpecOperation_Task(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	task := object.NewTask(client.Client, resp.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// deleteDVS destroys the supplied VmwareDistributedVirtualSwitch.
+func deleteDVS(dvs *object.VmwareDistributedVirtualSwitch) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := dvs.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// moveDVSToFolder moves a distributed virtual switch to a given relative
+// network folder path. "Relative" here means relative to a datacenter, which
+// is discovered from the current switch path.
+func moveDVSToFolder(client *govmomi.Client, dvs *object.VmwareDistributedVirtualSwitch, relative string) error {
+	folder, err := networkFolderFromObject(client, dvs, relative)
+	if err != nil {
+		return err
+	}
+	return moveObjectToFolder(dvs.Reference(), folder)
+}
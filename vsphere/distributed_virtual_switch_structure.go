@@ -0,0 +1,331 @@
+package vsphere
+
+import (
+	"reflect"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var vmwareDvsLacpAPIVersionAllowedValues = []string{
+	string(types.VMwareDvsLacpApiVersionSingleLag),
+	string(types.VMwareDvsLacpApiVersionMultipleLag),
+}
+
+var vmwareUplinkLacpModeAllowedValues = []string{
+	string(types.VMwareUplinkLacpModeActive),
+	string(types.VMwareUplinkLacpModePassive),
+}
+
+var distributedVirtualSwitchNetworkResourceControlVersionAllowedValues = []string{
+	string(types.DistributedVirtualSwitchNetworkResourceControlVersionVersion2),
+	string(types.DistributedVirtualSwitchNetworkResourceControlVersionVersion3),
+}
+
+// schemaDistributedVirtualSwitchHostMemberConfigSpec returns the schema for
+// the host member set of a distributed virtual switch.
+func schemaDistributedVirtualSwitchHostMemberConfigSpec() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "A host member specification.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"host_system_id": &schema.Schema{
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "The managed object ID of the host this specification applies to.",
+					ValidateFunc: validation.NoZeroValues,
+				},
+				"devices": &schema.Schema{
+					Type:        schema.TypeList,
+					Required:    true,
+					Description: "Name of the physical NIC to be added to the proxy switch.",
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+// expandDistributedVirtualSwitchHostMemberPnicBacking reads a list of device
+// names and returns a DistributedVirtualSwitchHostMemberPnicBacking.
+func expandDistributedVirtualSwitchHostMemberPnicBacking(devices []interface{}) *types.DistributedVirtualSwitchHostMemberPnicBacking {
+	obj := &types.DistributedVirtualSwitchHostMemberPnicBacking{}
+	for _, device := range devices {
+		obj.PnicSpec = append(obj.PnicSpec, types.DistributedVirtualSwitchHostMemberPnicSpec{
+			PnicDevice: device.(string),
+		})
+	}
+	return obj
+}
+
+// flattenDistributedVirtualSwitchHostMemberPnicBacking returns the device
+// names from a DistributedVirtualSwitchHostMemberPnicBacking.
+func flattenDistributedVirtualSwitchHostMemberPnicBacking(obj *types.DistributedVirtualSwitchHostMemberPnicBacking) []interface{} {
+	var devices []interface{}
+	for _, spec := range obj.PnicSpec {
+		devices = append(devices, spec.PnicDevice)
+	}
+	return devices
+}
+
+// dvsHostMembersFromSet returns a map of host system IDs to device lists from
+// a host member set.
+func dvsHostMembersFromSet(s *schema.Set) map[string][]interface{} {
+	m := make(map[string][]interface{})
+	for _, v := range s.List() {
+		host := v.(map[string]interface{})
+		m[host["host_system_id"].(string)] = host["devices"].([]interface{})
+	}
+	return m
+}
+
+// expandDistributedVirtualSwitchHostMemberConfigSpecs diffs the old and new
+// host member sets and returns a list of
+// DistributedVirtualSwitchHostMemberConfigSpecs. Hosts that are in old but
+// missing from new are removed, hosts that are in new but missing from old are
+// added, and hosts present in both with a different device list are edited to
+// match the new list.
+func expandDistributedVirtualSwitchHostMemberConfigSpecs(d *schema.ResourceData) []types.DistributedVirtualSwitchHostMemberConfigSpec {
+	o, n := d.GetChange("host")
+	oldHosts := dvsHostMembersFromSet(o.(*schema.Set))
+	newHosts := dvsHostMembersFromSet(n.(*schema.Set))
+
+	var specs []types.DistributedVirtualSwitchHostMemberConfigSpec
+	for hsID := range oldHosts {
+		if _, ok := newHosts[hsID]; !ok {
+			specs = append(specs, types.DistributedVirtualSwitchHostMemberConfigSpec{
+				Operation: string(types.ConfigSpecOperationRemove),
+				Host: types.ManagedObjectReference{
+					Type:  "HostSystem",
+					Value: hsID,
+				},
+			})
+		}
+	}
+	for hsID, devices := range newHosts {
+		op := types.ConfigSpecOperationAdd
+		if oldDevices, ok := oldHosts[hsID]; ok {
+			if reflect.DeepEqual(oldDevices, devices) {
+				// Nothing to do
+				continue
+			}
+			op = types.ConfigSpecOperationEdit
+		}
+		specs = append(specs, types.DistributedVirtualSwitchHostMemberConfigSpec{
+			Operation: string(op),
+			Host: types.ManagedObjectReference{
+				Type:  "HostSystem",
+				Value: hsID,
+			},
+			Backing: expandDistributedVirtualSwitchHostMemberPnicBacking(devices),
+		})
+	}
+	return specs
+}
+
+// flattenDistributedVirtualSwitchHostMembers reads the host members of a
+// distributed virtual switch into the passed in ResourceData.
+func flattenDistributedVirtualSwitchHostMembers(d *schema.ResourceData, members []types.DistributedVirtualSwitchHostMember) error {
+	var hosts []map[string]interface{}
+	for _, member := range members {
+		if member.Config.Host == nil {
+			continue
+		}
+		host := map[string]interface{}{
+			"host_system_id": member.Config.Host.Value,
+		}
+		if backing, ok := member.Config.Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking); ok {
+			host["devices"] = flattenDistributedVirtualSwitchHostMemberPnicBacking(backing)
+		}
+		hosts = append(hosts, host)
+	}
+	return d.Set("host", hosts)
+}
+
+// expandDVSNameArrayUplinkPortPolicy reads certain ResourceData keys and
+// returns a DVSNameArrayUplinkPortPolicy.
+func expandDVSNameArrayUplinkPortPolicy(d *schema.ResourceData) *types.DVSNameArrayUplinkPortPolicy {
+	uplinks := sliceInterfacesToStrings(d.Get("uplinks").([]interface{}))
+	if len(uplinks) < 1 {
+		return nil
+	}
+	obj := &types.DVSNameArrayUplinkPortPolicy{
+		UplinkPortName: uplinks,
+	}
+	return obj
+}
+
+// flattenDVSNameArrayUplinkPortPolicy reads various fields from a
+// DVSNameArrayUplinkPortPolicy into the passed in ResourceData.
+func flattenDVSNameArrayUplinkPortPolicy(d *schema.ResourceData, obj *types.DVSNameArrayUplinkPortPolicy) error {
+	return d.Set("uplinks", sliceStringsToInterfaces(obj.UplinkPortName))
+}
+
+// expandVMwareUplinkLacpPolicy reads certain ResourceData keys and returns a
+// VMwareUplinkLacpPolicy. The policy can only be set on switches using the
+// singleLag LACP API version, so nil is returned otherwise.
+func expandVMwareUplinkLacpPolicy(d *schema.ResourceData) *types.VMwareUplinkLacpPolicy {
+	if d.Get("lacp_api_version").(string) != string(types.VMwareDvsLacpApiVersionSingleLag) {
+		return nil
+	}
+	obj := &types.VMwareUplinkLacpPolicy{
+		Enable: &types.BoolPolicy{
+			Value: boolPtr(d.Get("lacp_enabled").(bool)),
+		},
+		Mode: &types.StringPolicy{
+			Value: d.Get("lacp_mode").(string),
+		},
+	}
+	return obj
+}
+
+// flattenVMwareUplinkLacpPolicy reads various fields from a
+// VMwareUplinkLacpPolicy into the passed in ResourceData.
+func flattenVMwareUplinkLacpPolicy(d *schema.ResourceData, obj *types.VMwareUplinkLacpPolicy) error {
+	if obj.Enable != nil && obj.Enable.Value != nil {
+		d.Set("lacp_enabled", *obj.Enable.Value)
+	}
+	if obj.Mode != nil {
+		d.Set("lacp_mode", obj.Mode.Value)
+	}
+	return nil
+}
+
+// schemaVMwareDVSConfigSpec returns schema items for resources that need to
+// work with a VMwareDVSConfigSpec, such as distributed virtual switches.
+func schemaVMwareDVSConfigSpec() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"description": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "A detailed description for the distributed virtual switch.",
+		},
+		"max_mtu": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The maximum transmission unit (MTU) for the distributed virtual switch.",
+			ValidateFunc: validation.IntBetween(1, 9000),
+		},
+		"uplinks": &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			Description: "A list of uplink ports. The contents of this list control both the number of uplinks that are configured on the distributed virtual switch, and their names.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"lacp_api_version": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The Link Aggregation Control Protocol group version in the switch. Can be one of singleLag or multipleLag.",
+			ValidateFunc: validation.StringInSlice(vmwareDvsLacpAPIVersionAllowedValues, false),
+		},
+		"lacp_enabled": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Whether or not to enable LACP on all uplink ports. Only applicable when lacp_api_version is singleLag.",
+		},
+		"lacp_mode": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.VMwareUplinkLacpModePassive),
+			Description:  "The uplink LACP mode to use. Can be one of active or passive. Only applicable when lacp_api_version is singleLag.",
+			ValidateFunc: validation.StringInSlice(vmwareUplinkLacpModeAllowedValues, false),
+		},
+		"network_resource_control_enabled": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Whether or not to enable network resource control, enabling advanced traffic shaping and resource control features.",
+		},
+		"network_resource_control_version": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The network I/O control version to use. Can be one of version2 or version3.",
+			ValidateFunc: validation.StringInSlice(distributedVirtualSwitchNetworkResourceControlVersionAllowedValues, false),
+		},
+		"host": schemaDistributedVirtualSwitchHostMemberConfigSpec(),
+	}
+	mergeSchema(s, schemaLinkDiscoveryProtocolConfig())
+	return s
+}
+
+// expandVMwareDVSConfigSpec reads certain ResourceData keys and returns a
+// VMwareDVSConfigSpec. The ConfigVersion of the spec is not populated.
+func expandVMwareDVSConfigSpec(d *schema.ResourceData) *types.VMwareDVSConfigSpec {
+	obj := &types.VMwareDVSConfigSpec{
+		DVSConfigSpec: types.DVSConfigSpec{
+			Name:                          d.Get("name").(string),
+			Description:                   d.Get("description").(string),
+			Host:                          expandDistributedVirtualSwitchHostMemberConfigSpecs(d),
+			NetworkResourceControlVersion: d.Get("network_resource_control_version").(string),
+		},
+		MaxMtu:                      int32(d.Get("max_mtu").(int)),
+		LinkDiscoveryProtocolConfig: expandLinkDiscoveryProtocolConfig(d),
+		LacpApiVersion:              d.Get("lacp_api_version").(string),
+	}
+	// Only set the uplink policy if we actually have uplinks defined, otherwise
+	// we will get an error about an invalid argument.
+	if policy := expandDVSNameArrayUplinkPortPolicy(d); policy != nil {
+		obj.UplinkPortPolicy = policy
+	}
+	if policy := expandVMwareUplinkLacpPolicy(d); policy != nil {
+		obj.DefaultPortConfig = &types.VMwareDVSPortSetting{
+			LacpPolicy: policy,
+		}
+	}
+	return obj
+}
+
+// flattenVMwareDVSConfigInfo reads various fields from a VMwareDVSConfigInfo
+// into the passed in ResourceData.
+func flattenVMwareDVSConfigInfo(d *schema.ResourceData, obj *types.VMwareDVSConfigInfo) error {
+	d.Set("name", obj.Name)
+	d.Set("description", obj.Description)
+	d.Set("max_mtu", obj.MaxMtu)
+	d.Set("lacp_api_version", obj.LacpApiVersion)
+	d.Set("network_resource_control_version", obj.NetworkResourceControlVersion)
+	d.Set("version", obj.ProductInfo.Version)
+	d.Set("config_version", obj.ConfigVersion)
+	if obj.NetworkResourceManagementEnabled != nil {
+		d.Set("network_resource_control_enabled", *obj.NetworkResourceManagementEnabled)
+	}
+	if obj.LinkDiscoveryProtocolConfig != nil {
+		if err := flattenLinkDiscoveryProtocolConfig(d, obj.LinkDiscoveryProtocolConfig); err != nil {
+			return err
+		}
+	}
+	if policy, ok := obj.UplinkPortPolicy.(*types.DVSNameArrayUplinkPortPolicy); ok {
+		if err := flattenDVSNameArrayUplinkPortPolicy(d, policy); err != nil {
+			return err
+		}
+	}
+	if portConfig, ok := obj.DefaultPortConfig.(*types.VMwareDVSPortSetting); ok && portConfig.LacpPolicy != nil {
+		if obj.LacpApiVersion == string(types.VMwareDvsLacpApiVersionSingleLag) {
+			if err := flattenVMwareUplinkLacpPolicy(d, portConfig.LacpPolicy); err != nil {
+				return err
+			}
+		}
+	}
+	return flattenDistributedVirtualSwitchHostMembers(d, obj.Host)
+}
+
+// expandDVSCreateSpec reads certain ResourceData keys and returns a
+// DVSCreateSpec.
+func expandDVSCreateSpec(d *schema.ResourceData) types.DVSCreateSpec {
+	obj := types.DVSCreateSpec{
+		ConfigSpec: expandVMwareDVSConfigSpec(d),
+	}
+	if v, ok := d.GetOk("version"); ok {
+		obj.ProductInfo = &types.DistributedVirtualSwitchProductSpec{
+			Version: v.(string),
+		}
+	}
+	return obj
+}
@@ -2,7 +2,6 @@ package vsphere
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
@@ -21,6 +20,71 @@ const (
 // virtualMachineCustomizationWaiter waits for a success or failure event.
 const virtualMachineCustomizationWaiterTimeout = time.Minute * 10
 
+// The kinds of customization failure that customizationFailedError can
+// describe. The first four correspond to the subtypes of the
+// CustomizationFailed event.
+const (
+	customizationFailureNetworkSetup  = "network setup failed"
+	customizationFailureUnknown       = "unknown failure"
+	customizationFailureSysprep       = "sysprep failed"
+	customizationFailureLinuxIdentity = "Linux identity failed"
+	customizationFailureGeneric       = "failed"
+	customizationFailureTimeout       = "timed out"
+)
+
+// customizationFailedError is the error returned by
+// virtualMachineCustomizationWaiter when the customization of the guest
+// fails, or does not complete in time.
+type customizationFailedError struct {
+	// The kind of failure, one of the customizationFailure constants.
+	kind string
+
+	// The message of the failure event, or of the timeout.
+	message string
+
+	// The path of the customization log in the guest, if the failure event
+	// carried one.
+	logLocation string
+}
+
+// newCustomizationFailedError returns a customizationFailedError for the
+// supplied CustomizationFailed event.
+func newCustomizationFailedError(be types.BaseCustomizationFailed) *customizationFailedError {
+	kind := customizationFailureGeneric
+	switch be.(type) {
+	case *types.CustomizationNetworkSetupFailed:
+		kind = customizationFailureNetworkSetup
+	case *types.CustomizationUnknownFailure:
+		kind = customizationFailureUnknown
+	case *types.CustomizationSysprepFailed:
+		kind = customizationFailureSysprep
+	case *types.CustomizationLinuxIdentityFailed:
+		kind = customizationFailureLinuxIdentity
+	}
+	e := be.GetCustomizationFailed()
+	return &customizationFailedError{
+		kind:        kind,
+		message:     e.FullFormattedMessage,
+		logLocation: e.LogLocation,
+	}
+}
+
+// Error implements error for customizationFailedError.
+func (e *customizationFailedError) Error() string {
+	msg := fmt.Sprintf("guest customization %s: %s", e.kind, e.message)
+	if e.logLocation != "" {
+		msg += fmt.Sprintf(" (customization log in guest: %s)", e.logLocation)
+	}
+	return msg
+}
+
+// isCustomizationFailedError checks an error to see if it is a
+// customizationFailedError.
+func isCustomizationFailedError(err error) bool {
+	_, ok := err.(*customizationFailedError)
+	return ok
+}
+
 // virtualMachineCustomizationWaiter is an object that waits for customization
 // of a VirtualMachine to complete, by watching for success or failure events.
 //
@@ -53,12 +117,14 @@ func (w *virtualMachineCustomizationWaiter) Err() error {
 //
 // This should be called **before** the start of the customization task to be
 // 100% certain that completion events are not missed.
-func newVirtualMachineCustomizationWaiter(client *govmomi.Client, vm *object.VirtualMachine) *virtualMachineCustomizationWaiter {
+//
+// The waiter waits for at most the supplied timeout.
+func newVirtualMachineCustomizationWaiter(client *govmomi.Client, vm *object.VirtualMachine, timeout time.Duration) *virtualMachineCustomizationWaiter {
 	w := &virtualMachineCustomizationWaiter{
 		done: make(chan struct{}),
 	}
 	go func() {
-		w.err = w.wait(client, vm)
+		w.err = w.wait(client, vm, timeout)
 		close(w.done)
 	}()
 	return w
@@ -66,19 +132,28 @@ func newVirtualMachineCustomizationWaiter(client *govmomi.Client, vm *object.Vir
 
 // wait waits for the customization of a supplied VirtualMachine to complete,
 // either due to success or error. It does this by watching specifically for
-// CustomizationSucceeded and CustomizationFailed events. If the customization
-// failed due to some sort of error, the full formatted message is returned as
-// an error.
-func (w *virtualMachineCustomizationWaiter) wait(client *govmomi.Client, vm *object.VirtualMachine) error {
-	// Our listener loop callback.
+// CustomizationSucceeded events, and CustomizationFailed events and their
+// subtypes. If the customization failed, or did not complete within the
+// supplied timeout, a customizationFailedError is returned.
+func (w *virtualMachineCustomizationWaiter) wait(client *govmomi.Client, vm *object.VirtualMachine, timeout time.Duration) error {
+	// Our listener loop callback. Failures are passed back on a channel, as
+	// the event manager does not stop on errors returned by the callback.
 	success := make(chan struct{})
+	failure := make(chan error, 1)
+	var succeeded bool
 	cb := func(obj types.ManagedObjectReference, page []types.BaseEvent) error {
 		for _, be := range page {
 			switch e := be.(type) {
-			case *types.CustomizationFailed:
-				return errors.New(e.GetEvent().FullFormattedMessage)
+			case types.BaseCustomizationFailed:
+				select {
+				case failure <- newCustomizationFailedError(e):
+				default:
+				}
 			case *types.CustomizationSucceeded:
-				close(success)
+				if !succeeded {
+					succeeded = true
+					close(success)
+				}
 			}
 		}
 		return nil
@@ -98,14 +173,19 @@ func (w *virtualMachineCustomizationWaiter) wait(client *govmomi.Client, vm *obj
 	// This is our waiter. We want to wait on all of these conditions. We also
 	// use a different context so that we can give a better error message on
 	// timeout without interfering with the subscriber's context.
-	ctx, cancel := context.WithTimeout(context.Background(), virtualMachineCustomizationWaiterTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 	select {
 	case err := <-mgrErr:
 		return err
+	case err := <-failure:
+		return err
 	case <-ctx.Done():
 		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("timeout waiting for customization to complete")
+			return &customizationFailedError{
+				kind:    customizationFailureTimeout,
+				message: fmt.Sprintf("no success or failure event received within %s", timeout),
+			}
 		}
 	case <-success:
 		// Pass case to break to success
@@ -0,0 +1,66 @@
+package vsphere
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testNewCustomizationFailedError struct {
+	Name string
+
+	event    types.BaseCustomizationFailed
+	expected string
+}
+
+func testCustomizationFailedEvent(message, logLocation string) types.CustomizationFailed {
+	var e types.CustomizationFailed
+	e.FullFormattedMessage = message
+	e.LogLocation = logLocation
+	return e
+}
+
+func (tc *testNewCustomizationFailedError) Test(t *testing.T) {
+	err := newCustomizationFailedError(tc.event)
+	if !isCustomizationFailedError(err) {
+		t.Fatalf("expected customizationFailedError, got %T", err)
+	}
+	if actual := err.Error(); actual != tc.expected {
+		t.Fatalf("expected %q, got %q", tc.expected, actual)
+	}
+}
+
+func TestNewCustomizationFailedError(t *testing.T) {
+	cases := []testNewCustomizationFailedError{
+		{
+			Name:     "generic",
+			event:    &types.CustomizationFailed{},
+			expected: "guest customization failed: ",
+		},
+		{
+			Name: "network setup with log",
+			event: &types.CustomizationNetworkSetupFailed{
+				CustomizationFailed: testCustomizationFailedEvent("Network setup failed in the guest", "C:/Windows/Temp/vmware-imc/guestcust.log"),
+			},
+			expected: "guest customization network setup failed: Network setup failed in the guest (customization log in guest: C:/Windows/Temp/vmware-imc/guestcust.log)",
+		},
+		{
+			Name: "unknown",
+			event: &types.CustomizationUnknownFailure{
+				CustomizationFailed: testCustomizationFailedEvent("An error occurred while customizing", "/var/log/vmware-imc/toolsDeployPkg.log"),
+			},
+			expected: "guest customization unknown failure: An error occurred while customizing (customization log in guest: /var/log/vmware-imc/toolsDeployPkg.log)",
+		},
+		{
+			Name: "sysprep",
+			event: &types.CustomizationSysprepFailed{
+				CustomizationFailed: testCustomizationFailedEvent("Sysprep failed", ""),
+			},
+			expected: "guest customization sysprep failed: Sysprep failed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
@@ -172,8 +172,18 @@ func folderFromObject(client *govmomi.Client, obj interface{}, folderType rootPa
 	switch o := obj.(type) {
 	case (*object.Datastore):
 		p, err = rootPathParticleDatastore.PathFromNewRoot(o.InventoryPath, folderType, relative)
+	case (*object.StoragePod):
+		p, err = rootPathParticleDatastore.PathFromNewRoot(o.InventoryPath, folderType, relative)
 	case (*object.HostSystem):
 		p, err = rootPathParticleHost.PathFromNewRoot(o.InventoryPath, folderType, relative)
+	case (*object.ClusterComputeResource):
+		p, err = rootPathParticleHost.PathFromNewRoot(o.InventoryPath, folderType, relative)
+	case (*object.VirtualMachine):
+		p, err = rootPathParticleVM.PathFromNewRoot(o.InventoryPath, folderType, relative)
+	case (*object.VmwareDistributedVirtualSwitch):
+		p, err = rootPathParticleNetwork.PathFromNewRoot(o.InventoryPath, folderType, relative)
+	case (*object.Datacenter):
+		p = path.Clean(folderType.PathFromDatacenter(o, relative))
 	default:
 		return nil, fmt.Errorf("unsupported object type %T", o)
 	}
@@ -198,12 +208,72 @@ func datastoreFolderFromObject(client *govmomi.Client, obj interface{}, relative
 // validateDatastoreFolder checks to make sure the folder is a datastore
 // folder, and returns it if it is not, or an error if it isn't.
 func validateDatastoreFolder(folder *object.Folder) (*object.Folder, error) {
+	return validateFolderType(folder, vSphereFolderTypeDatastore)
+}
+
+// hostFolderFromObject returns an *object.Folder from a given object, and
+// relative host folder path. If no such folder is found, or if it is not a
+// host folder, an appropriate error will be returned.
+func hostFolderFromObject(client *govmomi.Client, obj interface{}, relative string) (*object.Folder, error) {
+	folder, err := folderFromObject(client, obj, rootPathParticleHost, relative)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateHostFolder(folder)
+}
+
+// validateHostFolder checks to make sure the folder is a host folder, and
+// returns it if it is, or an error if it isn't.
+func validateHostFolder(folder *object.Folder) (*object.Folder, error) {
+	return validateFolderType(folder, vSphereFolderTypeHost)
+}
+
+// networkFolderFromObject returns an *object.Folder from a given object, and
+// relative network folder path. If no such folder is found, or if it is not a
+// network folder, an appropriate error will be returned.
+func networkFolderFromObject(client *govmomi.Client, obj interface{}, relative string) (*object.Folder, error) {
+	folder, err := folderFromObject(client, obj, rootPathParticleNetwork, relative)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateNetworkFolder(folder)
+}
+
+// validateNetworkFolder checks to make sure the folder is a network folder,
+// and returns it if it is, or an error if it isn't.
+func validateNetworkFolder(folder *object.Folder) (*object.Folder, error) {
+	return validateFolderType(folder, vSphereFolderTypeNetwork)
+}
+
+// vmFolderFromObject returns an *object.Folder from a given object, and
+// relative VM folder path. If no such folder is found, or if it is not a VM
+// folder, an appropriate error will be returned.
+func vmFolderFromObject(client *govmomi.Client, obj interface{}, relative string) (*object.Folder, error) {
+	folder, err := folderFromObject(client, obj, rootPathParticleVM, relative)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateVMFolder(folder)
+}
+
+// validateVMFolder checks to make sure the folder is a VM folder, and returns
+// it if it is, or an error if it isn't.
+func validateVMFolder(folder *object.Folder) (*object.Folder, error) {
+	return validateFolderType(folder, vSphereFolderTypeVM)
+}
+
+// validateFolderType checks to make sure the folder is of the supplied folder
+// type, and returns it if it is, or an error if it isn't.
+func validateFolderType(folder *object.Folder, expected vSphereFolderType) (*object.Folder, error) {
 	ft, err := findFolderType(folder)
 	if err != nil {
 		return nil, err
 	}
-	if ft != vSphereFolderTypeDatastore {
-		return nil, fmt.Errorf("%q is not a datastore folder", folder.InventoryPath)
+	if ft != expected {
+		return nil, fmt.Errorf("%q is not a %s folder", folder.InventoryPath, expected)
 	}
 	return folder, nil
 }
@@ -117,6 +117,26 @@ func testGetPortGroup(s *terraform.State, resourceName string) (*types.HostPortG
 	return hostPortGroupFromName(tVars.client, ns, name)
 }
 
+// testGetVNic is a convenience method to fetch a VMkernel network adapter
+// resource for testing.
+func testGetVNic(s *terraform.State, resourceName string) (*types.HostVirtualNic, error) {
+	tVars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_vnic.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+
+	hsID, device, err := splitHostVirtualNicID(tVars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(tVars.client, hsID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	return hostVirtualNicFromDevice(tVars.client, ns, device)
+}
+
 // testGetVirtualMachine is a convenience method to fetch a virtual machine by
 // resource name.
 func testGetVirtualMachine(s *terraform.State, resourceName string) (*object.VirtualMachine, error) {
@@ -293,3 +313,167 @@ func testGetFolderProperties(s *terraform.State, resourceName string) (*mo.Folde
 	}
 	return folderProperties(folder)
 }
+
+// testGetDatastoreCluster is a convenience method to fetch a datastore
+// cluster by resource name.
+func testGetDatastoreCluster(s *terraform.State, resourceName string) (*object.StoragePod, error) {
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_datastore_cluster.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return storagePodFromID(vars.client, vars.resourceID)
+}
+
+// testGetDatastoreClusterProperties is a convenience method that adds an
+// extra step to testGetDatastoreCluster to get the properties of a datastore
+// cluster.
+func testGetDatastoreClusterProperties(s *terraform.State, resourceName string) (*mo.StoragePod, error) {
+	pod, err := testGetDatastoreCluster(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return storagePodProperties(pod)
+}
+
+// testGetComputeCluster is a convenience method to fetch a compute cluster by
+// resource name.
+func testGetComputeCluster(s *terraform.State, resourceName string) (*object.ClusterComputeResource, error) {
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_compute_cluster.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return clusterFromID(vars.client, vars.resourceID)
+}
+
+// testGetComputeClusterProperties is a convenience method that adds an extra
+// step to testGetComputeCluster to get the properties of a cluster.
+func testGetComputeClusterProperties(s *terraform.State, resourceName string) (*mo.ClusterComputeResource, error) {
+	cluster, err := testGetComputeCluster(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return clusterProperties(cluster)
+}
+
+// testGetComputeClusterRule is a convenience method to fetch a cluster rule
+// by resource address. A nil rule is returned if the rule no longer exists on
+// the cluster.
+func testGetComputeClusterRule(s *terraform.State, resAddr string) (types.BaseClusterRuleInfo, error) {
+	tVars, err := testClientVariablesForResource(s, resAddr)
+	if err != nil {
+		return nil, err
+	}
+	clusterID, key, err := splitClusterRuleID(tVars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := clusterFromID(tVars.client, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return clusterRuleFromKey(cluster, key)
+}
+
+// testGetComputeClusterGroup is a convenience method to fetch a cluster
+// group by resource address. A nil group is returned if the group no longer
+// exists on the cluster.
+func testGetComputeClusterGroup(s *terraform.State, resAddr string) (types.BaseClusterGroupInfo, error) {
+	tVars, err := testClientVariablesForResource(s, resAddr)
+	if err != nil {
+		return nil, err
+	}
+	clusterID, name, err := splitClusterGroupID(tVars.resourceID)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := clusterFromID(tVars.client, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return clusterGroupFromName(cluster, name)
+}
+
+// testGetComputeClusterObjectImportID builds the import ID for a cluster rule
+// or group resource from its cluster ID and name.
+func testGetComputeClusterObjectImportID(s *terraform.State, resAddr string) (string, error) {
+	tVars, err := testClientVariablesForResource(s, resAddr)
+	if err != nil {
+		return "", err
+	}
+	cluster, err := clusterFromID(tVars.client, tVars.resourceAttributes["compute_cluster_id"])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", cluster.InventoryPath, tVars.resourceAttributes["name"]), nil
+}
+
+// testGetResourcePool is a convenience method to fetch a resource pool by
+// resource name.
+func testGetResourcePool(s *terraform.State, resourceName string) (*object.ResourcePool, error) {
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_resource_pool.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return resourcePoolFromID(vars.client, vars.resourceID)
+}
+
+// testGetResourcePoolProperties is a convenience method that adds an extra
+// step to testGetResourcePool to get the properties of a resource pool.
+func testGetResourcePoolProperties(s *terraform.State, resourceName string) (*mo.ResourcePool, error) {
+	pool, err := testGetResourcePool(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return resourcePoolProperties(pool)
+}
+
+// testGetDVS is a convenience method to fetch a distributed virtual switch by
+// resource name.
+func testGetDVS(s *terraform.State, resourceName string) (*object.VmwareDistributedVirtualSwitch, error) {
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_distributed_virtual_switch.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return dvsFromMOID(vars.client, vars.resourceID)
+}
+
+// testGetDVSProperties is a convenience method that adds an extra step to
+// testGetDVS to get the properties of a distributed virtual switch.
+func testGetDVSProperties(s *terraform.State, resourceName string) (*mo.VmwareDistributedVirtualSwitch, error) {
+	dvs, err := testGetDVS(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return dvsProperties(dvs)
+}
+
+// testGetDVPortgroup is a convenience method to fetch a distributed port group
+// by resource name.
+func testGetDVPortgroup(s *terraform.State, resourceName string) (*object.DistributedVirtualPortgroup, error) {
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_distributed_port_group.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return dvPortgroupFromMOID(vars.client, vars.resourceID)
+}
+
+// testGetDVPortgroupProperties is a convenience method that adds an extra
+// step to testGetDVPortgroup to get the properties of a distributed port
+// group.
+func testGetDVPortgroupProperties(s *terraform.State, resourceName string) (*mo.DistributedVirtualPortgroup, error) {
+	pg, err := testGetDVPortgroup(s, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	return dvPortgroupProperties(pg)
+}
+
+// testGetCustomizationSpec is a convenience method to fetch a customization
+// spec by resource name.
+func testGetCustomizationSpec(s *terraform.State, resourceName string) (*types.CustomizationSpecItem, error) {
+	vars, err := testClientVariablesForResource(s, fmt.Sprintf("vsphere_customization_spec.%s", resourceName))
+	if err != nil {
+		return nil, err
+	}
+	return customizationSpecItemFromName(vars.client, vars.resourceID)
+}
@@ -1,6 +1,8 @@
 package vsphere
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/vmware/govmomi/vim25/types"
@@ -20,6 +22,18 @@ var hostNetworkPolicyNicTeamingPolicyAllowedValues = []string{
 	hostNetworkPolicyNicTeamingPolicyModeFailoverExplicit,
 }
 
+// vmwareUplinkPortTeamingPolicyModeLoadbalanceLoadbased is the load-based
+// teaming policy, which is only available on distributed virtual switches.
+const vmwareUplinkPortTeamingPolicyModeLoadbalanceLoadbased = "loadbalance_loadbased"
+
+var vmwareUplinkPortTeamingPolicyAllowedValues = []string{
+	hostNetworkPolicyNicTeamingPolicyModeLoadbalanceIP,
+	hostNetworkPolicyNicTeamingPolicyModeLoadbalanceSrcMac,
+	hostNetworkPolicyNicTeamingPolicyModeLoadbalanceSrcID,
+	hostNetworkPolicyNicTeamingPolicyModeFailoverExplicit,
+	vmwareUplinkPortTeamingPolicyModeLoadbalanceLoadbased,
+}
+
 // schemaHostNetworkPolicy returns schema items for resources that need to work
 // with a HostNetworkPolicy, such as virtual switches and port groups.
 func schemaHostNetworkPolicy() map[string]*schema.Schema {
@@ -285,3 +299,385 @@ func flattenHostNetworkPolicy(d *schema.ResourceData, obj *types.HostNetworkPoli
 	}
 	return nil
 }
+
+// schemaDVSTrafficShapingPolicy returns schema items for a
+// DVSTrafficShapingPolicy. The prefix is used to differentiate between the
+// ingress and egress shaping policies of a distributed port setting.
+func schemaDVSTrafficShapingPolicy(prefix string) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		fmt.Sprintf("%s_shaping_average_bandwidth", prefix): &schema.Schema{
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Computed:    true,
+			Description: fmt.Sprintf("The average %s traffic bandwidth in bits per second if %s shaping is enabled on the port.", prefix, prefix),
+		},
+		fmt.Sprintf("%s_shaping_burst_size", prefix): &schema.Schema{
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Computed:    true,
+			Description: fmt.Sprintf("The maximum %s traffic burst size in bytes if %s shaping is enabled on the port.", prefix, prefix),
+		},
+		fmt.Sprintf("%s_shaping_enabled", prefix): &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: fmt.Sprintf("True if the %s traffic shaper is enabled on the port.", prefix),
+		},
+		fmt.Sprintf("%s_shaping_peak_bandwidth", prefix): &schema.Schema{
+			Type:        schema.TypeInt,
+			Optional:    true,
+			Computed:    true,
+			Description: fmt.Sprintf("The peak %s traffic bandwidth during bursts in bits per second if %s traffic shaping is enabled on the port.", prefix, prefix),
+		},
+	}
+}
+
+// schemaVMwareDVSPortSetting returns schema items for resources that need to
+// work with a VMwareDVSPortSetting, such as distributed port groups. The
+// attribute names mirror those of schemaHostNetworkPolicy where possible.
+func schemaVMwareDVSPortSetting() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		// VmwareDistributedVirtualSwitchVlanIdSpec
+		"vlan_id": &schema.Schema{
+			Type:          schema.TypeInt,
+			Optional:      true,
+			Computed:      true,
+			Description:   "The VLAN ID for ports in this port group. An ID of 0 denotes no tagging.",
+			ConflictsWith: []string{"vlan_range", "port_private_secondary_vlan_id"},
+			ValidateFunc:  validation.IntBetween(0, 4094),
+		},
+
+		// VmwareDistributedVirtualSwitchTrunkVlanSpec
+		"vlan_range": &schema.Schema{
+			Type:          schema.TypeSet,
+			Optional:      true,
+			Description:   "The VLAN ID ranges to trunk on ports in this port group.",
+			ConflictsWith: []string{"vlan_id", "port_private_secondary_vlan_id"},
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"min_vlan": &schema.Schema{
+						Type:         schema.TypeInt,
+						Required:     true,
+						Description:  "The minimum VLAN to use in the range.",
+						ValidateFunc: validation.IntBetween(0, 4094),
+					},
+					"max_vlan": &schema.Schema{
+						Type:         schema.TypeInt,
+						Required:     true,
+						Description:  "The maximum VLAN to use in the range.",
+						ValidateFunc: validation.IntBetween(0, 4094),
+					},
+				},
+			},
+		},
+
+		// VmwareDistributedVirtualSwitchPvlanSpec
+		"port_private_secondary_vlan_id": &schema.Schema{
+			Type:          schema.TypeInt,
+			Optional:      true,
+			Description:   "The secondary VLAN ID for the private VLAN used on ports in this port group.",
+			ConflictsWith: []string{"vlan_id", "vlan_range"},
+			ValidateFunc:  validation.IntBetween(1, 4094),
+		},
+
+		// DVSFailureCriteria
+		"check_beacon": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Enable beacon probing on the ports this policy applies to.",
+		},
+
+		// VMwareUplinkPortOrderPolicy
+		"active_uplinks": &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			Description: "List of active uplinks used for load balancing, matching the names of the uplinks assigned in the distributed virtual switch.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"standby_uplinks": &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			Description: "List of standby uplinks used for load balancing, matching the names of the uplinks assigned in the distributed virtual switch.",
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+
+		// VmwareUplinkPortTeamingPolicy
+		"teaming_policy": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The uplink teaming policy. Can be one of loadbalance_ip, loadbalance_srcmac, loadbalance_srcid, failover_explicit, or loadbalance_loadbased.",
+			ValidateFunc: validation.StringInSlice(vmwareUplinkPortTeamingPolicyAllowedValues, false),
+		},
+		"notify_switches": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "If true, the teaming policy will notify the broadcast network of an uplink failover, triggering cache updates.",
+		},
+		"failback": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "If true, the teaming policy will re-activate failed uplinks higher in precedence when they come back up.",
+		},
+
+		// DVSSecurityPolicy
+		"allow_promiscuous": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Enable promiscuous mode on the network. This flag indicates whether or not all traffic is seen on a given port.",
+		},
+		"allow_forged_transmits": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Controls whether or not the virtual network adapter is allowed to send network traffic with a different MAC address than that of its own.",
+		},
+		"allow_mac_changes": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Controls whether or not the Media Access Control (MAC) address can be changed.",
+		},
+
+		// DVPortSetting
+		"block_all_ports": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+			Description: "Indicates whether to block all ports by default.",
+		},
+	}
+	mergeSchema(s, schemaDVSTrafficShapingPolicy("ingress"))
+	mergeSchema(s, schemaDVSTrafficShapingPolicy("egress"))
+	return s
+}
+
+// expandVmwareDistributedVirtualSwitchVlanSpec reads certain ResourceData keys
+// and returns the appropriate VLAN spec for a VMwareDVSPortSetting. Trunk
+// ranges take precedence, followed by private VLANs, followed by a single
+// VLAN ID.
+func expandVmwareDistributedVirtualSwitchVlanSpec(d *schema.ResourceData) types.BaseVmwareDistributedVirtualSwitchVlanSpec {
+	if v, ok := d.GetOk("vlan_range"); ok && v.(*schema.Set).Len() > 0 {
+		obj := &types.VmwareDistributedVirtualSwitchTrunkVlanSpec{}
+		for _, r := range v.(*schema.Set).List() {
+			rm := r.(map[string]interface{})
+			obj.VlanId = append(obj.VlanId, types.NumericRange{
+				Start: int32(rm["min_vlan"].(int)),
+				End:   int32(rm["max_vlan"].(int)),
+			})
+		}
+		return obj
+	}
+	if v, ok := d.GetOk("port_private_secondary_vlan_id"); ok {
+		return &types.VmwareDistributedVirtualSwitchPvlanSpec{
+			PvlanId: int32(v.(int)),
+		}
+	}
+	return &types.VmwareDistributedVirtualSwitchVlanIdSpec{
+		VlanId: int32(d.Get("vlan_id").(int)),
+	}
+}
+
+// flattenVmwareDistributedVirtualSwitchVlanSpec reads various fields from a
+// VLAN spec into the passed in ResourceData.
+func flattenVmwareDistributedVirtualSwitchVlanSpec(d *schema.ResourceData, obj types.BaseVmwareDistributedVirtualSwitchVlanSpec) error {
+	switch t := obj.(type) {
+	case *types.VmwareDistributedVirtualSwitchVlanIdSpec:
+		d.Set("vlan_id", t.VlanId)
+	case *types.VmwareDistributedVirtualSwitchTrunkVlanSpec:
+		var ranges []interface{}
+		for _, r := range t.VlanId {
+			ranges = append(ranges, map[string]interface{}{
+				"min_vlan": int(r.Start),
+				"max_vlan": int(r.End),
+			})
+		}
+		if err := d.Set("vlan_range", ranges); err != nil {
+			return err
+		}
+	case *types.VmwareDistributedVirtualSwitchPvlanSpec:
+		d.Set("port_private_secondary_vlan_id", t.PvlanId)
+	}
+	return nil
+}
+
+// expandDVSFailureCriteria reads certain ResourceData keys and returns a
+// DVSFailureCriteria.
+func expandDVSFailureCriteria(d *schema.ResourceData) *types.DVSFailureCriteria {
+	obj := &types.DVSFailureCriteria{
+		CheckBeacon: getBoolPolicy(d, "check_beacon"),
+	}
+	if obj.CheckBeacon == nil {
+		return nil
+	}
+	return obj
+}
+
+// flattenDVSFailureCriteria reads various fields from a DVSFailureCriteria
+// into the passed in ResourceData.
+func flattenDVSFailureCriteria(d *schema.ResourceData, obj *types.DVSFailureCriteria) error {
+	if obj == nil {
+		return nil
+	}
+	setBoolPolicy(d, "check_beacon", obj.CheckBeacon)
+	return nil
+}
+
+// expandVMwareUplinkPortOrderPolicy reads certain ResourceData keys and
+// returns a VMwareUplinkPortOrderPolicy.
+func expandVMwareUplinkPortOrderPolicy(d *schema.ResourceData) *types.VMwareUplinkPortOrderPolicy {
+	activeUplinks, activeOk := d.GetOk("active_uplinks")
+	standbyUplinks, standbyOk := d.GetOk("standby_uplinks")
+	if !activeOk && !standbyOk {
+		return nil
+	}
+	obj := &types.VMwareUplinkPortOrderPolicy{
+		ActiveUplinkPort:  sliceInterfacesToStrings(activeUplinks.([]interface{})),
+		StandbyUplinkPort: sliceInterfacesToStrings(standbyUplinks.([]interface{})),
+	}
+	return obj
+}
+
+// flattenVMwareUplinkPortOrderPolicy reads various fields from a
+// VMwareUplinkPortOrderPolicy into the passed in ResourceData.
+func flattenVMwareUplinkPortOrderPolicy(d *schema.ResourceData, obj *types.VMwareUplinkPortOrderPolicy) error {
+	if obj == nil {
+		return nil
+	}
+	if err := d.Set("active_uplinks", sliceStringsToInterfaces(obj.ActiveUplinkPort)); err != nil {
+		return err
+	}
+	if err := d.Set("standby_uplinks", sliceStringsToInterfaces(obj.StandbyUplinkPort)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// expandVmwareUplinkPortTeamingPolicy reads certain ResourceData keys and
+// returns a VmwareUplinkPortTeamingPolicy.
+func expandVmwareUplinkPortTeamingPolicy(d *schema.ResourceData) *types.VmwareUplinkPortTeamingPolicy {
+	obj := &types.VmwareUplinkPortTeamingPolicy{
+		Policy:          getStringPolicy(d, "teaming_policy"),
+		NotifySwitches:  getBoolPolicy(d, "notify_switches"),
+		FailureCriteria: expandDVSFailureCriteria(d),
+		UplinkPortOrder: expandVMwareUplinkPortOrderPolicy(d),
+	}
+	if v, ok := d.GetOkExists("failback"); ok {
+		obj.RollingOrder = &types.BoolPolicy{
+			Value: boolPtr(!v.(bool)),
+		}
+	}
+	return obj
+}
+
+// flattenVmwareUplinkPortTeamingPolicy reads various fields from a
+// VmwareUplinkPortTeamingPolicy into the passed in ResourceData.
+func flattenVmwareUplinkPortTeamingPolicy(d *schema.ResourceData, obj *types.VmwareUplinkPortTeamingPolicy) error {
+	if obj.RollingOrder != nil && obj.RollingOrder.Value != nil {
+		v := *obj.RollingOrder.Value
+		d.Set("failback", !v)
+	}
+	setBoolPolicy(d, "notify_switches", obj.NotifySwitches)
+	setStringPolicy(d, "teaming_policy", obj.Policy)
+	if err := flattenDVSFailureCriteria(d, obj.FailureCriteria); err != nil {
+		return err
+	}
+	if err := flattenVMwareUplinkPortOrderPolicy(d, obj.UplinkPortOrder); err != nil {
+		return err
+	}
+	return nil
+}
+
+// expandDVSSecurityPolicy reads certain ResourceData keys and returns a
+// DVSSecurityPolicy.
+func expandDVSSecurityPolicy(d *schema.ResourceData) *types.DVSSecurityPolicy {
+	obj := &types.DVSSecurityPolicy{
+		AllowPromiscuous: getBoolPolicy(d, "allow_promiscuous"),
+		ForgedTransmits:  getBoolPolicy(d, "allow_forged_transmits"),
+		MacChanges:       getBoolPolicy(d, "allow_mac_changes"),
+	}
+	return obj
+}
+
+// flattenDVSSecurityPolicy reads various fields from a DVSSecurityPolicy into
+// the passed in ResourceData.
+func flattenDVSSecurityPolicy(d *schema.ResourceData, obj *types.DVSSecurityPolicy) error {
+	setBoolPolicy(d, "allow_promiscuous", obj.AllowPromiscuous)
+	setBoolPolicy(d, "allow_forged_transmits", obj.ForgedTransmits)
+	setBoolPolicy(d, "allow_mac_changes", obj.MacChanges)
+	return nil
+}
+
+// expandDVSTrafficShapingPolicy reads certain ResourceData keys and returns a
+// DVSTrafficShapingPolicy for the supplied prefix (ingress or egress).
+func expandDVSTrafficShapingPolicy(d *schema.ResourceData, prefix string) *types.DVSTrafficShapingPolicy {
+	obj := &types.DVSTrafficShapingPolicy{
+		Enabled:          getBoolPolicy(d, fmt.Sprintf("%s_shaping_enabled", prefix)),
+		AverageBandwidth: getLongPolicy(d, fmt.Sprintf("%s_shaping_average_bandwidth", prefix)),
+		PeakBandwidth:    getLongPolicy(d, fmt.Sprintf("%s_shaping_peak_bandwidth", prefix)),
+		BurstSize:        getLongPolicy(d, fmt.Sprintf("%s_shaping_burst_size", prefix)),
+	}
+	return obj
+}
+
+// flattenDVSTrafficShapingPolicy reads various fields from a
+// DVSTrafficShapingPolicy into the passed in ResourceData for the supplied
+// prefix (ingress or egress).
+func flattenDVSTrafficShapingPolicy(d *schema.ResourceData, obj *types.DVSTrafficShapingPolicy, prefix string) error {
+	if obj == nil {
+		return nil
+	}
+	setBoolPolicy(d, fmt.Sprintf("%s_shaping_enabled", prefix), obj.Enabled)
+	setLongPolicy(d, fmt.Sprintf("%s_shaping_average_bandwidth", prefix), obj.AverageBandwidth)
+	setLongPolicy(d, fmt.Sprintf("%s_shaping_peak_bandwidth", prefix), obj.PeakBandwidth)
+	setLongPolicy(d, fmt.Sprintf("%s_shaping_burst_size", prefix), obj.BurstSize)
+	return nil
+}
+
+// expandVMwareDVSPortSetting reads certain ResourceData keys and returns a
+// VMwareDVSPortSetting.
+func expandVMwareDVSPortSetting(d *schema.ResourceData) *types.VMwareDVSPortSetting {
+	obj := &types.VMwareDVSPortSetting{
+		DVPortSetting: types.DVPortSetting{
+			Blocked:          getBoolPolicy(d, "block_all_ports"),
+			InShapingPolicy:  expandDVSTrafficShapingPolicy(d, "ingress"),
+			OutShapingPolicy: expandDVSTrafficShapingPolicy(d, "egress"),
+		},
+		Vlan:                expandVmwareDistributedVirtualSwitchVlanSpec(d),
+		UplinkTeamingPolicy: expandVmwareUplinkPortTeamingPolicy(d),
+		SecurityPolicy:      expandDVSSecurityPolicy(d),
+	}
+	return obj
+}
+
+// flattenVMwareDVSPortSetting reads various fields from a
+// VMwareDVSPortSetting into the passed in ResourceData.
+func flattenVMwareDVSPortSetting(d *schema.ResourceData, obj *types.VMwareDVSPortSetting) error {
+	setBoolPolicy(d, "block_all_ports", obj.Blocked)
+	if err := flattenDVSTrafficShapingPolicy(d, obj.InShapingPolicy, "ingress"); err != nil {
+		return err
+	}
+	if err := flattenDVSTrafficShapingPolicy(d, obj.OutShapingPolicy, "egress"); err != nil {
+		return err
+	}
+	if err := flattenVmwareDistributedVirtualSwitchVlanSpec(d, obj.Vlan); err != nil {
+		return err
+	}
+	if obj.UplinkTeamingPolicy != nil {
+		if err := flattenVmwareUplinkPortTeamingPolicy(d, obj.UplinkTeamingPolicy); err != nil {
+			return err
+		}
+	}
+	if obj.SecurityPolicy != nil {
+		if err := flattenDVSSecurityPolicy(d, obj.SecurityPolicy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
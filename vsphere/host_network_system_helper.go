@@ -69,6 +69,47 @@ func hostPortGroupFromName(client *govmomi.Client, ns *object.HostNetworkSystem,
 	return nil, fmt.Errorf("could not find port group %s", name)
 }
 
+// hostVirtualNicNotFoundError is an error type that is returned when a
+// VMkernel network adapter cannot be found on a host by its device name.
+type hostVirtualNicNotFoundError struct {
+	device string
+}
+
+// Error implements error for hostVirtualNicNotFoundError.
+func (e *hostVirtualNicNotFoundError) Error() string {
+	return fmt.Sprintf("could not find virtual NIC %s", e.device)
+}
+
+// isHostVirtualNicNotFoundError checks an error to see if it indicates that a
+// VMkernel network adapter could not be found, either by its device name or
+// because a managed object it lives on is gone.
+func isHostVirtualNicNotFoundError(err error) bool {
+	if _, ok := err.(*hostVirtualNicNotFoundError); ok {
+		return true
+	}
+	return isManagedObjectNotFoundError(err)
+}
+
+// hostVirtualNicFromDevice locates a VMkernel network adapter on the supplied
+// HostNetworkSystem by device name, ie: vmk1.
+func hostVirtualNicFromDevice(client *govmomi.Client, ns *object.HostNetworkSystem, device string) (*types.HostVirtualNic, error) {
+	var mns mo.HostNetworkSystem
+	pc := client.PropertyCollector()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := pc.RetrieveOne(ctx, ns.Reference(), []string{"networkInfo.vnic"}, &mns); err != nil {
+		return nil, fmt.Errorf("error fetching host network properties: %s", err)
+	}
+
+	for _, nic := range mns.NetworkInfo.Vnic {
+		if nic.Device == device {
+			return &nic, nil
+		}
+	}
+
+	return nil, &hostVirtualNicNotFoundError{device: device}
+}
+
 // networkProperties gets the properties for a specific Network.
 //
 // The Network type usually represents a standard port group in vCenter - it
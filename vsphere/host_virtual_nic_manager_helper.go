@@ -0,0 +1,76 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// hostVirtualNicManagerNicTypeAllowedValues is the list of services that can
+// be enabled on a VMkernel network adapter.
+var hostVirtualNicManagerNicTypeAllowedValues = []string{
+	string(types.HostVirtualNicManagerNicTypeVmotion),
+	string(types.HostVirtualNicManagerNicTypeFaultToleranceLogging),
+	string(types.HostVirtualNicManagerNicTypeVSphereReplication),
+	string(types.HostVirtualNicManagerNicTypeVSphereReplicationNFC),
+	string(types.HostVirtualNicManagerNicTypeManagement),
+	string(types.HostVirtualNicManagerNicTypeVsan),
+	string(types.HostVirtualNicManagerNicTypeVSphereProvisioning),
+}
+
+// hostVirtualNicManagerFromHostSystemID locates a HostVirtualNicManager from a
+// specified HostSystem managed object ID.
+func hostVirtualNicManagerFromHostSystemID(client *govmomi.Client, hsID string) (*object.HostVirtualNicManager, error) {
+	hs, err := hostSystemFromID(client, hsID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return hs.ConfigManager().VirtualNicManager(ctx)
+}
+
+// hostVirtualNicServices returns the list of services (NIC types) that are
+// currently enabled on the VMkernel network adapter with the supplied device
+// name.
+func hostVirtualNicServices(vnm *object.HostVirtualNicManager, device string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	info, err := vnm.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, nc := range info.NetConfig {
+		for _, candidate := range nc.CandidateVnic {
+			if candidate.Device != device {
+				continue
+			}
+			for _, key := range nc.SelectedVnic {
+				if key == candidate.Key {
+					services = append(services, nc.NicType)
+				}
+			}
+		}
+	}
+	return services, nil
+}
+
+// enableHostVirtualNicService enables the supplied service (NIC type) on the
+// VMkernel network adapter with the supplied device name.
+func enableHostVirtualNicService(vnm *object.HostVirtualNicManager, device, service string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return vnm.SelectVnic(ctx, service, device)
+}
+
+// disableHostVirtualNicService disables the supplied service (NIC type) on
+// the VMkernel network adapter with the supplied device name.
+func disableHostVirtualNicService(vnm *object.HostVirtualNicManager, device, service string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return vnm.DeselectVnic(ctx, service, device)
+}
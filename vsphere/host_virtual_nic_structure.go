@@ -0,0 +1,329 @@
+package vsphere
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const hostVirtualNicIDPrefix = "tf-HostVirtualNic"
+
+const (
+	hostVirtualNicNetStackDefault      = "defaultTcpipStack"
+	hostVirtualNicNetStackVmotion      = "vmotion"
+	hostVirtualNicNetStackProvisioning = "vSphereProvisioning"
+)
+
+var hostVirtualNicNetStackAllowedValues = []string{
+	hostVirtualNicNetStackDefault,
+	hostVirtualNicNetStackVmotion,
+	hostVirtualNicNetStackProvisioning,
+}
+
+// schemaHostVirtualNicSpec returns schema items for resources that need to
+// work with a HostVirtualNicSpec, such as VMkernel network adapters.
+func schemaHostVirtualNicSpec() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"portgroup": &schema.Schema{
+			Type:          schema.TypeString,
+			Optional:      true,
+			ForceNew:      true,
+			Description:   "The name of the standard port group to attach this NIC to.",
+			ConflictsWith: []string{"distributed_port_group"},
+		},
+		"distributed_port_group": &schema.Schema{
+			Type:          schema.TypeString,
+			Optional:      true,
+			ForceNew:      true,
+			Description:   "The managed object ID of the distributed port group to attach this NIC to.",
+			ConflictsWith: []string{"portgroup"},
+		},
+		"mac": &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "The MAC address of the NIC.",
+		},
+		"mtu": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The MTU of the NIC.",
+			ValidateFunc: validation.IntBetween(576, 9000),
+		},
+		"netstack": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ForceNew:     true,
+			Default:      hostVirtualNicNetStackDefault,
+			Description:  "The TCP/IP stack to attach this NIC to. Can be one of defaultTcpipStack, vmotion, or vSphereProvisioning.",
+			ValidateFunc: validation.StringInSlice(hostVirtualNicNetStackAllowedValues, false),
+		},
+		"ipv4": &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "The IPv4 configuration of the NIC.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"dhcp": &schema.Schema{
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     false,
+						Description: "Use DHCP to configure the IPv4 address of the NIC.",
+					},
+					"ip": &schema.Schema{
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The static IPv4 address of the NIC.",
+					},
+					"netmask": &schema.Schema{
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The subnet mask of the NIC's static IPv4 address.",
+					},
+					"gw": &schema.Schema{
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The IPv4 default gateway of the NIC. Overrides the default gateway of the TCP/IP stack.",
+					},
+				},
+			},
+		},
+		"ipv6": &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "The IPv6 configuration of the NIC.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"dhcp": &schema.Schema{
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     false,
+						Description: "Use DHCPv6 to configure the IPv6 addresses of the NIC.",
+					},
+					"autoconfig": &schema.Schema{
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     false,
+						Description: "Use IPv6 router advertisements (SLAAC) to configure the IPv6 addresses of the NIC.",
+					},
+					"addresses": &schema.Schema{
+						Type:        schema.TypeList,
+						Optional:    true,
+						Description: "The static IPv6 addresses of the NIC, in CIDR notation (ie: 2001:db8::10/64).",
+						Elem:        &schema.Schema{Type: schema.TypeString},
+					},
+					"gw": &schema.Schema{
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "The IPv6 default gateway of the NIC. Overrides the default gateway of the TCP/IP stack.",
+					},
+				},
+			},
+		},
+	}
+}
+
+// expandHostIpConfigIpV6Addresses returns the address operations required
+// to move a NIC from the old set of static IPv6 addresses to the new one.
+func expandHostIpConfigIpV6Addresses(d *schema.ResourceData) ([]types.HostIpConfigIpV6Address, error) {
+	o, n := d.GetChange("ipv6.0.addresses")
+	oldAddrs := sliceInterfacesToStrings(o.([]interface{}))
+	newAddrs := sliceInterfacesToStrings(n.([]interface{}))
+	oldSet := make(map[string]bool)
+	for _, addr := range oldAddrs {
+		oldSet[addr] = true
+	}
+	newSet := make(map[string]bool)
+	for _, addr := range newAddrs {
+		newSet[addr] = true
+	}
+
+	var result []types.HostIpConfigIpV6Address
+	for _, addr := range oldAddrs {
+		if newSet[addr] {
+			continue
+		}
+		a, err := parseHostIpConfigIpV6Address(addr)
+		if err != nil {
+			return nil, err
+		}
+		a.Operation = string(types.HostConfigChangeOperationRemove)
+		result = append(result, a)
+	}
+	for _, addr := range newAddrs {
+		if oldSet[addr] {
+			continue
+		}
+		a, err := parseHostIpConfigIpV6Address(addr)
+		if err != nil {
+			return nil, err
+		}
+		a.Operation = string(types.HostConfigChangeOperationAdd)
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+// parseHostIpConfigIpV6Address parses an IPv6 address in CIDR notation into
+// a HostIpConfigIpV6Address.
+func parseHostIpConfigIpV6Address(addr string) (types.HostIpConfigIpV6Address, error) {
+	ip, ipNet, err := net.ParseCIDR(addr)
+	if err != nil || ip.To4() != nil {
+		return types.HostIpConfigIpV6Address{}, fmt.Errorf("%q is not a valid IPv6 address in CIDR notation", addr)
+	}
+	prefix, _ := ipNet.Mask.Size()
+	return types.HostIpConfigIpV6Address{
+		IpAddress:    ip.String(),
+		PrefixLength: int32(prefix),
+	}, nil
+}
+
+// expandHostIpConfig reads certain ResourceData keys and returns a
+// HostIpConfig.
+func expandHostIpConfig(d *schema.ResourceData) (*types.HostIpConfig, error) {
+	obj := &types.HostIpConfig{}
+	if _, ok := d.GetOk("ipv4"); ok {
+		obj.Dhcp = d.Get("ipv4.0.dhcp").(bool)
+		if !obj.Dhcp {
+			obj.IpAddress = d.Get("ipv4.0.ip").(string)
+			obj.SubnetMask = d.Get("ipv4.0.netmask").(string)
+		}
+	}
+	if _, ok := d.GetOk("ipv6"); ok {
+		addrs, err := expandHostIpConfigIpV6Addresses(d)
+		if err != nil {
+			return nil, err
+		}
+		obj.IpV6Config = &types.HostIpConfigIpV6AddressConfiguration{
+			DhcpV6Enabled:            boolPtr(d.Get("ipv6.0.dhcp").(bool)),
+			AutoConfigurationEnabled: boolPtr(d.Get("ipv6.0.autoconfig").(bool)),
+			IpV6Address:              addrs,
+		}
+	}
+	return obj, nil
+}
+
+// expandHostVirtualNicIpRouteSpec reads certain ResourceData keys and returns
+// a HostVirtualNicIpRouteSpec. nil is returned if no gateways have been
+// defined.
+func expandHostVirtualNicIpRouteSpec(d *schema.ResourceData) *types.HostVirtualNicIpRouteSpec {
+	config := &types.HostIpRouteConfig{
+		DefaultGateway:     d.Get("ipv4.0.gw").(string),
+		IpV6DefaultGateway: d.Get("ipv6.0.gw").(string),
+	}
+	if config.DefaultGateway == "" && config.IpV6DefaultGateway == "" {
+		return nil
+	}
+	return &types.HostVirtualNicIpRouteSpec{
+		IpRouteConfig: config,
+	}
+}
+
+// expandHostVirtualNicSpec reads certain ResourceData keys and returns a
+// HostVirtualNicSpec. The distributed virtual port connection is not
+// populated, as it requires a lookup of the port group.
+func expandHostVirtualNicSpec(d *schema.ResourceData) (*types.HostVirtualNicSpec, error) {
+	ip, err := expandHostIpConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	obj := &types.HostVirtualNicSpec{
+		Ip:                  ip,
+		Mac:                 d.Get("mac").(string),
+		Mtu:                 int32(d.Get("mtu").(int)),
+		Portgroup:           d.Get("portgroup").(string),
+		NetStackInstanceKey: d.Get("netstack").(string),
+		IpRouteSpec:         expandHostVirtualNicIpRouteSpec(d),
+	}
+	return obj, nil
+}
+
+// flattenHostVirtualNicSpec reads various fields from a HostVirtualNicSpec
+// into the passed in ResourceData.
+func flattenHostVirtualNicSpec(d *schema.ResourceData, obj *types.HostVirtualNicSpec) error {
+	d.Set("mac", obj.Mac)
+	d.Set("mtu", obj.Mtu)
+	d.Set("netstack", obj.NetStackInstanceKey)
+
+	var ipv4gw, ipv6gw string
+	if obj.IpRouteSpec != nil {
+		if config, ok := obj.IpRouteSpec.IpRouteConfig.(*types.HostIpRouteConfig); ok {
+			ipv4gw = config.DefaultGateway
+			ipv6gw = config.IpV6DefaultGateway
+		}
+	}
+
+	if obj.Ip == nil {
+		return nil
+	}
+
+	if _, ok := d.GetOk("ipv4"); ok || obj.Ip.Dhcp || obj.Ip.IpAddress != "" {
+		ipv4 := map[string]interface{}{
+			"dhcp": obj.Ip.Dhcp,
+			"gw":   ipv4gw,
+		}
+		if !obj.Ip.Dhcp {
+			ipv4["ip"] = obj.Ip.IpAddress
+			ipv4["netmask"] = obj.Ip.SubnetMask
+		}
+		if err := d.Set("ipv4", []interface{}{ipv4}); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := d.GetOk("ipv6"); ok && obj.Ip.IpV6Config != nil {
+		var addrs []string
+		for _, addr := range obj.Ip.IpV6Config.IpV6Address {
+			// Only track statically assigned addresses.
+			if addr.Origin != string(types.HostIpConfigIpV6AddressConfigTypeManual) {
+				continue
+			}
+			addrs = append(addrs, fmt.Sprintf("%s/%d", addr.IpAddress, addr.PrefixLength))
+		}
+		ipv6 := map[string]interface{}{
+			"addresses": sliceStringsToInterfaces(addrs),
+			"gw":        ipv6gw,
+		}
+		if obj.Ip.IpV6Config.DhcpV6Enabled != nil {
+			ipv6["dhcp"] = *obj.Ip.IpV6Config.DhcpV6Enabled
+		}
+		if obj.Ip.IpV6Config.AutoConfigurationEnabled != nil {
+			ipv6["autoconfig"] = *obj.Ip.IpV6Config.AutoConfigurationEnabled
+		}
+		if err := d.Set("ipv6", []interface{}{ipv6}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveHostVirtualNicID sets a special ID for a host VMkernel network adapter,
+// composed of the MOID for the concerned HostSystem and the adapter's device
+// name.
+func saveHostVirtualNicID(d *schema.ResourceData, hsID, device string) {
+	d.SetId(fmt.Sprintf("%s:%s:%s", hostVirtualNicIDPrefix, hsID, device))
+}
+
+// splitHostVirtualNicID splits a vsphere_vnic resource ID into its
+// counterparts: the prefix, the HostSystem ID, and the device name.
+func splitHostVirtualNicID(raw string) (string, string, error) {
+	s := strings.SplitN(raw, ":", 3)
+	if len(s) != 3 || s[0] != hostVirtualNicIDPrefix || s[1] == "" || s[2] == "" {
+		return "", "", fmt.Errorf("corrupt ID: %s", raw)
+	}
+	return s[1], s[2], nil
+}
+
+// virtualNicIDsFromResourceID passes a resource's ID through
+// splitHostVirtualNicID.
+func virtualNicIDsFromResourceID(d *schema.ResourceData) (string, string, error) {
+	return splitHostVirtualNicID(d.Id())
+}
@@ -23,20 +23,11 @@ var linkDiscoveryProtocolConfigProtocolAllowedValues = []string{
 	string(types.LinkDiscoveryProtocolConfigProtocolTypeLldp),
 }
 
-// schemaHostVirtualSwitchBondBridge returns schema items for resources that
-// need to work with a HostVirtualSwitchBondBridge, such as virtual switches.
-func schemaHostVirtualSwitchBondBridge() map[string]*schema.Schema {
+// schemaLinkDiscoveryProtocolConfig returns schema items for resources that
+// need to work with a LinkDiscoveryProtocolConfig, such as standard and
+// distributed virtual switches.
+func schemaLinkDiscoveryProtocolConfig() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
-		// HostVirtualSwitchBeaconConfig
-		"beacon_interval": &schema.Schema{
-			Type:         schema.TypeInt,
-			Optional:     true,
-			Description:  "Determines how often, in seconds, a beacon should be sent to probe for the validity of a link.",
-			Default:      1,
-			ValidateFunc: validation.IntAtLeast(0),
-		},
-
-		// LinkDiscoveryProtocolConfig
 		"link_discovery_operation": &schema.Schema{
 			Type:         schema.TypeString,
 			Optional:     true,
@@ -51,6 +42,21 @@ func schemaHostVirtualSwitchBondBridge() map[string]*schema.Schema {
 			Default:      string(types.LinkDiscoveryProtocolConfigProtocolTypeCdp),
 			ValidateFunc: validation.StringInSlice(linkDiscoveryProtocolConfigProtocolAllowedValues, false),
 		},
+	}
+}
+
+// schemaHostVirtualSwitchBondBridge returns schema items for resources that
+// need to work with a HostVirtualSwitchBondBridge, such as virtual switches.
+func schemaHostVirtualSwitchBondBridge() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		// HostVirtualSwitchBeaconConfig
+		"beacon_interval": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Determines how often, in seconds, a beacon should be sent to probe for the validity of a link.",
+			Default:      1,
+			ValidateFunc: validation.IntAtLeast(0),
+		},
 
 		// HostVirtualSwitchBondBridge
 		"network_adapters": &schema.Schema{
@@ -60,6 +66,8 @@ func schemaHostVirtualSwitchBondBridge() map[string]*schema.Schema {
 			Elem:        &schema.Schema{Type: schema.TypeString},
 		},
 	}
+	mergeSchema(s, schemaLinkDiscoveryProtocolConfig())
+	return s
 }
 
 // expandHostVirtualSwitchBeaconConfig reads certain ResourceData keys and
@@ -0,0 +1,66 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// networkManagedObjectTypes is the list of managed object types that can be
+// used as a network backing for a virtual machine network adapter.
+var networkManagedObjectTypes = []string{
+	"DistributedVirtualPortgroup",
+	"Network",
+	"OpaqueNetwork",
+}
+
+// networkFromID locates a network by its managed object reference ID. As the
+// network may be one of several types, each network type is tried in turn
+// until a match is found.
+func networkFromID(client *govmomi.Client, id string) (object.NetworkReference, error) {
+	finder := find.NewFinder(client.Client, false)
+	var err error
+	for _, t := range networkManagedObjectTypes {
+		ref := types.ManagedObjectReference{
+			Type:  t,
+			Value: id,
+		}
+		var obj object.Reference
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		obj, err = finder.ObjectReference(ctx, ref)
+		cancel()
+		if err == nil {
+			return obj.(object.NetworkReference), nil
+		}
+		if !isManagedObjectNotFoundError(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// networkFromLabel locates a network from a label supplied to a virtual
+// machine network interface. The label is first searched for by name using
+// the supplied finder, which should be scoped to the virtual machine's
+// datacenter. If no network by that name exists, the label is treated as a
+// managed object ID.
+func networkFromLabel(client *govmomi.Client, finder *find.Finder, label string) (object.NetworkReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	net, err := finder.Network(ctx, "*"+label)
+	if err == nil {
+		return net, nil
+	}
+	if _, ok := err.(*find.NotFoundError); !ok {
+		return nil, err
+	}
+	if net, idErr := networkFromID(client, label); idErr == nil {
+		return net, nil
+	}
+	// Return the original not found error, which is more descriptive than any
+	// error that we would get from the ID lookup.
+	return nil, err
+}
@@ -0,0 +1,415 @@
+package vsphere
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ovfLeaseProgressInterval is the interval at which upload progress is
+// reported to an HttpNfcLease. The lease times out if progress is not
+// reported for a few minutes.
+const ovfLeaseProgressInterval = 5 * time.Second
+
+// ovfArchive is a source of the files that make up an OVF package.
+type ovfArchive interface {
+	// Open opens the file with the supplied name, relative to the OVF
+	// descriptor, and returns it along with its size.
+	Open(name string) (io.ReadCloser, int64, error)
+}
+
+// ovfFolderArchive is an ovfArchive for an OVF descriptor and its files in a
+// local directory.
+type ovfFolderArchive struct {
+	dir string
+}
+
+// Open implements ovfArchive for ovfFolderArchive.
+func (a *ovfFolderArchive) Open(name string) (io.ReadCloser, int64, error) {
+	f, err := os.Open(filepath.Join(a.dir, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, 0, err
+	}
+	s, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, s.Size(), nil
+}
+
+// ovfTapeArchive is an ovfArchive for an OVA, which is a tar archive of an
+// OVF descriptor and its files.
+type ovfTapeArchive struct {
+	path string
+}
+
+// ovfTapeArchiveEntry is an open file within an ovfTapeArchive.
+type ovfTapeArchiveEntry struct {
+	io.Reader
+	f *os.File
+}
+
+// Close implements io.Closer for ovfTapeArchiveEntry.
+func (e *ovfTapeArchiveEntry) Close() error {
+	return e.f.Close()
+}
+
+// Open implements ovfArchive for ovfTapeArchive. The archive is scanned from
+// the start on each call, so each file can be streamed independently.
+func (a *ovfTapeArchive) Open(name string) (io.ReadCloser, int64, error) {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	r := tar.NewReader(f)
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		if path.Clean(h.Name) == path.Clean(name) {
+			return &ovfTapeArchiveEntry{Reader: r, f: f}, h.Size, nil
+		}
+	}
+	f.Close()
+	return nil, 0, fmt.Errorf("%q not found in %q", name, a.path)
+}
+
+// ovfDescriptorName returns the name of the first OVF descriptor in an OVA.
+func (a *ovfTapeArchive) ovfDescriptorName() (string, error) {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	r := tar.NewReader(f)
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if strings.EqualFold(path.Ext(h.Name), ".ovf") {
+			return h.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no OVF descriptor found in %q", a.path)
+}
+
+// newOVFArchive returns an ovfArchive for the supplied local path to an .ovf
+// or .ova file, along with the name of the OVF descriptor in the archive.
+func newOVFArchive(p string) (ovfArchive, string, error) {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".ova":
+		a := &ovfTapeArchive{path: p}
+		name, err := a.ovfDescriptorName()
+		if err != nil {
+			return nil, "", err
+		}
+		return a, name, nil
+	case ".ovf":
+		return &ovfFolderArchive{dir: filepath.Dir(p)}, filepath.Base(p), nil
+	}
+	return nil, "", fmt.Errorf("%q is not an .ovf or .ova file", p)
+}
+
+// readOVFDescriptor reads the OVF descriptor from the supplied local .ovf or
+// .ova file, and returns it along with the archive holding the rest of the
+// files of the package.
+func readOVFDescriptor(p string) (string, ovfArchive, error) {
+	a, name, err := newOVFArchive(p)
+	if err != nil {
+		return "", nil, err
+	}
+	f, _, err := a.Open(name)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("error reading OVF descriptor: %s", err)
+	}
+	return string(b), a, nil
+}
+
+// createOVFImportSpec asks the OVF manager to create an import spec for the
+// supplied OVF descriptor. Errors reported in the result are returned as an
+// error, and warnings are logged.
+func createOVFImportSpec(client *govmomi.Client, desc string, rp *object.ResourcePool, ds *object.Datastore, cisp types.OvfCreateImportSpecParams) (*types.OvfCreateImportSpecResult, error) {
+	m := object.NewOvfManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	spec, err := m.CreateImportSpec(ctx, desc, rp, ds, cisp)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.Error) > 0 {
+		var msgs []string
+		for _, e := range spec.Error {
+			msgs = append(msgs, e.LocalizedMessage)
+		}
+		return nil, fmt.Errorf("error creating import spec: %s", strings.Join(msgs, "; "))
+	}
+	for _, w := range spec.Warning {
+		log.Printf("[WARN] OVF import spec: %s", w.LocalizedMessage)
+	}
+	return spec, nil
+}
+
+// ovfLeaseUpdater keeps an HttpNfcLease alive during an upload, by reporting
+// the combined progress of all uploaded files to it at a regular interval.
+// The progress is logged as well.
+type ovfLeaseUpdater struct {
+	lease *object.HttpNfcLease
+	name  string
+	total int64
+	done  int64
+}
+
+// newOVFLeaseUpdater returns an ovfLeaseUpdater for the supplied lease and
+// total upload size, in bytes.
+func newOVFLeaseUpdater(lease *object.HttpNfcLease, name string, total int64) *ovfLeaseUpdater {
+	return &ovfLeaseUpdater{
+		lease: lease,
+		name:  name,
+		total: total,
+	}
+}
+
+// Reader wraps the supplied reader so that bytes read from it count towards
+// the progress of the upload.
+func (u *ovfLeaseUpdater) Reader(r io.Reader) io.Reader {
+	return &ovfProgressReader{Reader: r, done: &u.done}
+}
+
+// percent returns the progress of the upload as a percentage.
+func (u *ovfLeaseUpdater) percent() int32 {
+	if u.total <= 0 {
+		return 0
+	}
+	p := int32(atomic.LoadInt64(&u.done) * 100 / u.total)
+	if p > 100 {
+		p = 100
+	}
+	return p
+}
+
+// Run reports progress to the lease until the supplied channel is closed.
+func (u *ovfLeaseUpdater) Run(done <-chan struct{}) {
+	tick := time.NewTicker(ovfLeaseProgressInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-tick.C:
+			p := u.percent()
+			log.Printf("[DEBUG] Uploading OVF files for %q: %d%% complete", u.name, p)
+			ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+			if err := u.lease.HttpNfcLeaseProgress(ctx, p); err != nil {
+				log.Printf("[WARN] Error reporting upload progress for %q: %s", u.name, err)
+			}
+			cancel()
+		}
+	}
+}
+
+// ovfProgressReader is an io.Reader that counts the bytes read through it.
+type ovfProgressReader struct {
+	io.Reader
+	done *int64
+}
+
+// Read implements io.Reader for ovfProgressReader.
+func (r *ovfProgressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	atomic.AddInt64(r.done, int64(n))
+	return n, err
+}
+
+// errOVFUploadCancelled is returned by an ovfCancelReader once its upload has
+// been cancelled.
+var errOVFUploadCancelled = errors.New("upload cancelled")
+
+// ovfCancelReader is an io.Reader that fails once the cancel channel is
+// closed, which ends the HTTP request that is streaming from it.
+type ovfCancelReader struct {
+	io.Reader
+	cancel <-chan struct{}
+}
+
+// Read implements io.Reader for ovfCancelReader.
+func (r *ovfCancelReader) Read(p []byte) (int, error) {
+	select {
+	case <-r.cancel:
+		return 0, errOVFUploadCancelled
+	default:
+	}
+	return r.Reader.Read(p)
+}
+
+// ovfUploadItem is a file of an OVF package, paired with the URL it is
+// uploaded to.
+type ovfUploadItem struct {
+	item types.OvfFileItem
+	url  string
+}
+
+// ovfUploadItems pairs the file items of an import spec with the device URLs
+// of the lease taken out to upload them.
+func ovfUploadItems(items []types.OvfFileItem, info *types.HttpNfcLeaseInfo) ([]ovfUploadItem, error) {
+	var result []ovfUploadItem
+	for _, item := range items {
+		var url string
+		for _, device := range info.DeviceUrl {
+			if device.ImportKey == item.DeviceId {
+				url = device.Url
+				break
+			}
+		}
+		if url == "" {
+			return nil, fmt.Errorf("no upload URL found for %q", item.Path)
+		}
+		result = append(result, ovfUploadItem{item: item, url: url})
+	}
+	return result, nil
+}
+
+// uploadOVFItem uploads a single file of an OVF package to the URL supplied
+// by the lease. Disks are streamed with POST, and any other files, such as
+// ISOs, are created with PUT.
+func uploadOVFItem(client *govmomi.Client, a ovfArchive, u *ovfLeaseUpdater, ui ovfUploadItem, cancel <-chan struct{}) error {
+	f, size, err := a.Open(ui.item.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	target, err := client.Client.ParseURL(ui.url)
+	if err != nil {
+		return err
+	}
+	opts := soap.Upload{
+		ContentLength: size,
+	}
+	if ui.item.Create {
+		opts.Method = "PUT"
+		opts.Headers = map[string]string{"Overwrite": "t"}
+	} else {
+		opts.Method = "POST"
+		opts.Type = "application/x-vnd.vmware-streamVmdk"
+	}
+	log.Printf("[DEBUG] Uploading %q (%d bytes) to %s", ui.item.Path, size, target.String())
+	return client.Client.Upload(u.Reader(&ovfCancelReader{Reader: f, cancel: cancel}), target, &opts)
+}
+
+// deployOVF deploys the OVF package at the supplied local .ovf or .ova path
+// as a new virtual machine, using the supplied import spec parameters.
+//
+// An import spec is created with the OVF manager, and the files of the package
+// are uploaded over an HttpNfcLease taken out by importing the spec into the
+// resource pool. The upload is bounded by the supplied timeout, and its
+// progress is logged.
+func deployOVF(client *govmomi.Client, p string, rp *object.ResourcePool, ds *object.Datastore, folder *object.Folder, host *object.HostSystem, cisp types.OvfCreateImportSpecParams, timeout time.Duration) (*object.VirtualMachine, error) {
+	desc, a, err := readOVFDescriptor(p)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := createOVFImportSpec(client, desc, rp, ds, cisp)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	lease, err := rp.ImportVApp(ctx, spec.ImportSpec, folder, host)
+	if err != nil {
+		return nil, fmt.Errorf("error importing OVF: %s", err)
+	}
+	info, err := lease.Wait(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for import lease: %s", err)
+	}
+
+	if err := uploadOVFItems(client, a, lease, info, spec.FileItem, cisp.EntityName, timeout); err != nil {
+		actx, acancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer acancel()
+		if abortErr := lease.HttpNfcLeaseAbort(actx, nil); abortErr != nil {
+			log.Printf("[WARN] Error aborting import lease: %s", abortErr)
+		}
+		return nil, err
+	}
+
+	cctx, ccancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer ccancel()
+	if err := lease.HttpNfcLeaseComplete(cctx); err != nil {
+		return nil, fmt.Errorf("error completing import lease: %s", err)
+	}
+	return object.NewVirtualMachine(client.Client, info.Entity), nil
+}
+
+// uploadOVFItems uploads the files of an OVF package over the supplied lease,
+// reporting progress to the lease while the upload runs. The upload is
+// cancelled if it does not complete within the supplied timeout.
+func uploadOVFItems(client *govmomi.Client, a ovfArchive, lease *object.HttpNfcLease, info *types.HttpNfcLeaseInfo, items []types.OvfFileItem, name string, timeout time.Duration) error {
+	uploads, err := ovfUploadItems(items, info)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, ui := range uploads {
+		total += ui.item.Size
+	}
+	u := newOVFLeaseUpdater(lease, name, total)
+	done := make(chan struct{})
+	go u.Run(done)
+
+	cancel := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		for _, ui := range uploads {
+			if err := uploadOVFItem(client, a, u, ui, cancel); err != nil {
+				errCh <- fmt.Errorf("error uploading %q: %s", ui.item.Path, err)
+				return
+			}
+		}
+		errCh <- nil
+	}()
+
+	select {
+	case err := <-errCh:
+		close(done)
+		return err
+	case <-time.After(timeout):
+	}
+	// Stop reporting progress, so that the lease expires if the upload is
+	// stuck, and cancel the upload. The upload is waited on so that it no longer
+	// streams to the lease or holds its file open once we return.
+	close(done)
+	close(cancel)
+	<-errCh
+	return errors.New("timeout waiting for OVF upload to complete")
+}
@@ -0,0 +1,152 @@
+package vsphere
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const testOVFDescriptor = `<?xml version="1.0" encoding="UTF-8"?><Envelope/>`
+
+// testWriteOVA writes an OVA with the supplied files to dir, and returns its
+// path.
+func testWriteOVA(t *testing.T, dir string, files map[string]string, order []string) string {
+	p := filepath.Join(dir, "test.ova")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatalf("error creating OVA: %s", err)
+	}
+	defer f.Close()
+	w := tar.NewWriter(f)
+	for _, name := range order {
+		body := files[name]
+		if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(body))}); err != nil {
+			t.Fatalf("error writing OVA header: %s", err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatalf("error writing OVA: %s", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing OVA: %s", err)
+	}
+	return p
+}
+
+func TestReadOVFDescriptorFromOVA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-vsphere-ovf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := testWriteOVA(t, dir, map[string]string{
+		"test.ovf":        testOVFDescriptor,
+		"test-disk1.vmdk": "disk1",
+		"test-disk2.vmdk": "disk22",
+		"test.mf":         "manifest",
+	}, []string{"test.ovf", "test.mf", "test-disk1.vmdk", "test-disk2.vmdk"})
+
+	desc, a, err := readOVFDescriptor(p)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if desc != testOVFDescriptor {
+		t.Fatalf("expected descriptor %q, got %q", testOVFDescriptor, desc)
+	}
+
+	f, size, err := a.Open("test-disk2.vmdk")
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if string(b) != "disk22" || size != 6 {
+		t.Fatalf("expected %q (6 bytes), got %q (%d bytes)", "disk22", string(b), size)
+	}
+
+	_, _, err = a.Open("missing.vmdk")
+	testMatchError(t, err, regexp.MustCompile("not found"))
+}
+
+func TestReadOVFDescriptorFromFolder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tf-vsphere-ovf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "test.ovf"), []byte(testOVFDescriptor), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "test-disk1.vmdk"), []byte("disk1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	desc, a, err := readOVFDescriptor(filepath.Join(dir, "test.ovf"))
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if desc != testOVFDescriptor {
+		t.Fatalf("expected descriptor %q, got %q", testOVFDescriptor, desc)
+	}
+	f, size, err := a.Open("test-disk1.vmdk")
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	f.Close()
+	if size != 5 {
+		t.Fatalf("expected size 5, got %d", size)
+	}
+}
+
+func TestReadOVFDescriptorBadExtension(t *testing.T) {
+	_, _, err := readOVFDescriptor("test.vmdk")
+	testMatchError(t, err, regexp.MustCompile("is not an .ovf or .ova file"))
+}
+
+func TestOVFUploadItems(t *testing.T) {
+	items := []types.OvfFileItem{
+		{DeviceId: "/vm/key=2000", Path: "disk1.vmdk"},
+		{DeviceId: "/vm/key=2001", Path: "disk2.vmdk"},
+	}
+	info := &types.HttpNfcLeaseInfo{
+		DeviceUrl: []types.HttpNfcLeaseDeviceUrl{
+			{ImportKey: "/vm/key=2001", Url: "https://*/nfc/disk-1.vmdk"},
+			{ImportKey: "/vm/key=2000", Url: "https://*/nfc/disk-0.vmdk"},
+		},
+	}
+	uploads, err := ovfUploadItems(items, info)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if uploads[0].url != "https://*/nfc/disk-0.vmdk" || uploads[1].url != "https://*/nfc/disk-1.vmdk" {
+		t.Fatalf("unexpected upload URLs: %#v", uploads)
+	}
+
+	info.DeviceUrl = info.DeviceUrl[:1]
+	_, err = ovfUploadItems(items, info)
+	testMatchError(t, err, regexp.MustCompile(`no upload URL found for "disk1.vmdk"`))
+}
+
+func TestOVFCancelReader(t *testing.T) {
+	cancel := make(chan struct{})
+	r := &ovfCancelReader{Reader: strings.NewReader("disk data"), cancel: cancel}
+	b := make([]byte, 4)
+	if _, err := r.Read(b); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	close(cancel)
+	if _, err := r.Read(b); err != errOVFUploadCancelled {
+		t.Fatalf("expected %q, got %v", errOVFUploadCancelled, err)
+	}
+}
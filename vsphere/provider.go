@@ -66,22 +66,37 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("VSPHERE_CLIENT_DEBUG_PATH", ""),
 				Description: "govomomi debug path for debug",
 			},
+			"customization_defaults": schemaCustomizationDefaults(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"vsphere_datacenter":               resourceVSphereDatacenter(),
-			"vsphere_file":                     resourceVSphereFile(),
-			"vsphere_folder":                   resourceVSphereFolder(),
-			"vsphere_host_port_group":          resourceVSphereHostPortGroup(),
-			"vsphere_host_virtual_switch":      resourceVSphereHostVirtualSwitch(),
-			"vsphere_license":                  resourceVSphereLicense(),
-			"vsphere_tag":                      resourceVSphereTag(),
-			"vsphere_tag_category":             resourceVSphereTagCategory(),
-			"vsphere_virtual_disk":             resourceVSphereVirtualDisk(),
-			"vsphere_virtual_machine":          resourceVSphereVirtualMachine(),
-			"vsphere_nas_datastore":            resourceVSphereNasDatastore(),
-			"vsphere_vmfs_datastore":           resourceVSphereVmfsDatastore(),
-			"vsphere_virtual_machine_snapshot": resourceVSphereVirtualMachineSnapshot(),
+			"vsphere_compute_cluster":                       resourceVSphereComputeCluster(),
+			"vsphere_compute_cluster_host_group":            resourceVSphereComputeClusterHostGroup(),
+			"vsphere_compute_cluster_vm_affinity_rule":      resourceVSphereComputeClusterVMAffinityRule(),
+			"vsphere_compute_cluster_vm_anti_affinity_rule": resourceVSphereComputeClusterVMAntiAffinityRule(),
+			"vsphere_compute_cluster_vm_group":              resourceVSphereComputeClusterVMGroup(),
+			"vsphere_compute_cluster_vm_host_rule":          resourceVSphereComputeClusterVMHostRule(),
+			"vsphere_customization_spec":                    resourceVSphereCustomizationSpec(),
+			"vsphere_datastore_cluster":                     resourceVSphereDatastoreCluster(),
+			"vsphere_datacenter":                            resourceVSphereDatacenter(),
+			"vsphere_distributed_port_group":                resourceVSphereDistributedPortGroup(),
+			"vsphere_distributed_virtual_switch":            resourceVSphereDistributedVirtualSwitch(),
+			"vsphere_file":                                  resourceVSphereFile(),
+			"vsphere_folder":                                resourceVSphereFolder(),
+			"vsphere_host_port_group":                       resourceVSphereHostPortGroup(),
+			"vsphere_host_virtual_switch":                   resourceVSphereHostVirtualSwitch(),
+			"vsphere_license":                               resourceVSphereLicense(),
+			"vsphere_resource_pool":                         resourceVSphereResourcePool(),
+			"vsphere_tag":                                   resourceVSphereTag(),
+			"vsphere_tag_category":                          resourceVSphereTagCategory(),
+			"vsphere_virtual_disk":                          resourceVSphereVirtualDisk(),
+			"vsphere_virtual_machine":                       resourceVSphereVirtualMachine(),
+			"vsphere_nas_datastore":                         resourceVSphereNasDatastore(),
+			"vsphere_ovf_virtual_machine":                   resourceVSphereOvfVirtualMachine(),
+			"vsphere_vmfs_datastore":                        resourceVSphereVmfsDatastore(),
+			"vsphere_virtual_machine_snapshot":              resourceVSphereVirtualMachineSnapshot(),
+			"vsphere_virtual_machine_snapshot_policy":       resourceVSphereVirtualMachineSnapshotPolicy(),
+			"vsphere_vnic":                                  resourceVSphereVNic(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
@@ -119,6 +134,8 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		Debug:         d.Get("client_debug").(bool),
 		DebugPathRun:  d.Get("client_debug_path_run").(string),
 		DebugPath:     d.Get("client_debug_path").(string),
+
+		CustomizationDefaults: expandCustomizationDefaults(d),
 	}
 
 	return config.Client()
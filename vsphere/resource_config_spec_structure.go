@@ -0,0 +1,123 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var sharesLevelAllowedValues = []string{
+	string(types.SharesLevelLow),
+	string(types.SharesLevelNormal),
+	string(types.SharesLevelHigh),
+	string(types.SharesLevelCustom),
+}
+
+// schemaResourceAllocationInfo returns the schema items for a
+// ResourceAllocationInfo, with each key prefixed by the supplied prefix (ie:
+// "cpu" or "memory").
+func schemaResourceAllocationInfo(prefix, unit string) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		fmt.Sprintf("%s_share_level", prefix): &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.SharesLevelNormal),
+			Description:  fmt.Sprintf("The %s allocation level. The level is a simplified view of shares. Levels map to a pre-determined set of numeric values for shares. Can be one of low, normal, high, or custom.", prefix),
+			ValidateFunc: validation.StringInSlice(sharesLevelAllowedValues, false),
+		},
+		fmt.Sprintf("%s_shares", prefix): &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  fmt.Sprintf("The number of shares allocated for %s. Used to determine resource allocation in case of resource contention. If this is set, %s_share_level must be custom.", prefix, prefix),
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		fmt.Sprintf("%s_reservation", prefix): &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      0,
+			Description:  fmt.Sprintf("Amount of %s (%s) that is guaranteed available to the resource pool.", prefix, unit),
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		fmt.Sprintf("%s_expandable", prefix): &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: fmt.Sprintf("Determines if the %s reservation of the resource pool can grow beyond the specified value if the parent resource pool has unreserved resources.", prefix),
+		},
+		fmt.Sprintf("%s_limit", prefix): &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      -1,
+			Description:  fmt.Sprintf("The utilization of the resource pool will not exceed this limit, even if there are available %s resources (%s). Set to -1 for unlimited.", prefix, unit),
+			ValidateFunc: validation.IntAtLeast(-1),
+		},
+	}
+}
+
+// expandResourceAllocationInfo reads certain ResourceData keys with the
+// supplied prefix and returns a ResourceAllocationInfo.
+func expandResourceAllocationInfo(d *schema.ResourceData, prefix string) *types.ResourceAllocationInfo {
+	obj := &types.ResourceAllocationInfo{
+		Reservation:           int64(d.Get(fmt.Sprintf("%s_reservation", prefix)).(int)),
+		ExpandableReservation: boolPtr(d.Get(fmt.Sprintf("%s_expandable", prefix)).(bool)),
+		Limit:                 int64(d.Get(fmt.Sprintf("%s_limit", prefix)).(int)),
+		Shares: &types.SharesInfo{
+			Level:  types.SharesLevel(d.Get(fmt.Sprintf("%s_share_level", prefix)).(string)),
+			Shares: int32(d.Get(fmt.Sprintf("%s_shares", prefix)).(int)),
+		},
+	}
+	return obj
+}
+
+// flattenResourceAllocationInfo reads various fields from a
+// ResourceAllocationInfo into the passed in ResourceData, using the supplied
+// key prefix.
+func flattenResourceAllocationInfo(d *schema.ResourceData, obj types.BaseResourceAllocationInfo, prefix string) error {
+	if obj == nil {
+		return nil
+	}
+	info := obj.GetResourceAllocationInfo()
+	d.Set(fmt.Sprintf("%s_reservation", prefix), info.Reservation)
+	d.Set(fmt.Sprintf("%s_limit", prefix), info.Limit)
+	if info.ExpandableReservation != nil {
+		d.Set(fmt.Sprintf("%s_expandable", prefix), *info.ExpandableReservation)
+	}
+	if info.Shares != nil {
+		d.Set(fmt.Sprintf("%s_share_level", prefix), info.Shares.Level)
+		d.Set(fmt.Sprintf("%s_shares", prefix), info.Shares.Shares)
+	}
+	return nil
+}
+
+// schemaResourceConfigSpec returns schema items for resources that need to
+// work with a ResourceConfigSpec, such as resource pools.
+func schemaResourceConfigSpec() map[string]*schema.Schema {
+	s := schemaResourceAllocationInfo("cpu", "MHz")
+	mergeSchema(s, schemaResourceAllocationInfo("memory", "MB"))
+	return s
+}
+
+// expandResourceConfigSpec reads certain ResourceData keys and returns a
+// ResourceConfigSpec.
+func expandResourceConfigSpec(d *schema.ResourceData) *types.ResourceConfigSpec {
+	obj := &types.ResourceConfigSpec{
+		CpuAllocation:    expandResourceAllocationInfo(d, "cpu"),
+		MemoryAllocation: expandResourceAllocationInfo(d, "memory"),
+	}
+	return obj
+}
+
+// flattenResourceConfigSpec reads various fields from a ResourceConfigSpec
+// into the passed in ResourceData.
+func flattenResourceConfigSpec(d *schema.ResourceData, obj types.ResourceConfigSpec) error {
+	if err := flattenResourceAllocationInfo(d, obj.CpuAllocation, "cpu"); err != nil {
+		return err
+	}
+	if err := flattenResourceAllocationInfo(d, obj.MemoryAllocation, "memory"); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package vsphere
+
+import (
+	"context"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// resourcePoolFromID locates a ResourcePool by its managed object reference
+// ID.
+func resourcePoolFromID(client *govmomi.Client, id string) (*object.ResourcePool, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "ResourcePool",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	obj, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*object.ResourcePool), nil
+}
+
+// resourcePoolFromAbsolutePath returns an *object.ResourcePool from a given
+// absolute inventory path. If no such resource pool is found, an appropriate
+// error will be returned.
+func resourcePoolFromAbsolutePath(client *govmomi.Client, path string) (*object.ResourcePool, error) {
+	finder := find.NewFinder(client.Client, false)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.ResourcePool(ctx, path)
+}
+
+// resourcePoolProperties is a convenience method that wraps fetching the
+// ResourcePool MO from its higher-level object.
+func resourcePoolProperties(pool *object.ResourcePool) (*mo.ResourcePool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.ResourcePool
+	if err := pool.Properties(ctx, pool.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// createResourcePool creates a resource pool as a child of the supplied parent
+// resource pool, with the supplied name and configuration spec.
+func createResourcePool(parent *object.ResourcePool, name string, spec types.ResourceConfigSpec) (*object.ResourcePool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return parent.Create(ctx, name, spec)
+}
+
+// updateResourcePool updates the name and configuration of the supplied
+// resource pool. An empty name leaves the name unchanged.
+func updateResourcePool(pool *object.ResourcePool, name string, spec *types.ResourceConfigSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return pool.UpdateConfig(ctx, name, spec)
+}
+
+// deleteResourcePool destroys the supplied resource pool. Any virtual machines
+// in the pool are moved to the parent resource pool.
+func deleteResourcePool(pool *object.ResourcePool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := pool.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// moveResourcePool moves the supplied resource pool into the supplied parent
+// resource pool. The parent must be part of the same compute resource.
+func moveResourcePool(pool *object.ResourcePool, parent *object.ResourcePool) error {
+	req := types.MoveIntoResourcePool{
+		This: parent.Reference(),
+		List: []types.ManagedObjectReference{pool.Reference()},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	_, err := methods.MoveIntoResourcePool(ctx, parent.Client(), &req)
+	return err
+}
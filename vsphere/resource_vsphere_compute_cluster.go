@@ -0,0 +1,250 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// formatComputeClusterCreateRollbackErrorUpdate defines the verbose error for
+// configuring a cluster on creation where rollback was not possible.
+const formatComputeClusterCreateRollbackErrorUpdate = `
+WARNING: Dangling resource!
+There was an error applying tags to your cluster:
+%s
+Additionally, there was an error removing the created cluster:
+%s
+You will need to remove this cluster manually before trying again.
+`
+
+func resourceVSphereComputeCluster() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The name of the cluster.",
+			Required:    true,
+		},
+		"datacenter_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the datacenter to put the cluster in.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"folder": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The path to the host folder to put the cluster in.",
+			Optional:    true,
+			StateFunc:   normalizeFolderPath,
+		},
+		"resource_pool_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the cluster's root resource pool.",
+			Computed:    true,
+		},
+	}
+	mergeSchema(s, schemaClusterConfigSpecEx())
+
+	// Add tags schema
+	s[vSphereTagAttributeKey] = tagsSchema()
+
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterCreate,
+		Read:   resourceVSphereComputeClusterRead,
+		Update: resourceVSphereComputeClusterUpdate,
+		Delete: resourceVSphereComputeClusterDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereComputeClusterImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereComputeClusterCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	folder, err := hostFolderFromObject(client, dc, d.Get("folder").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate folder: %s", err)
+	}
+
+	spec := expandClusterConfigSpecEx(d)
+	cluster, err := createCluster(folder, d.Get("name").(string), *spec)
+	if err != nil {
+		return fmt.Errorf("error creating cluster: %s", err)
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, cluster); err != nil {
+			if remErr := deleteCluster(cluster); remErr != nil {
+				// We could not destroy the created cluster and there is now a dangling
+				// resource. We need to instruct the user to remove the cluster
+				// manually.
+				return fmt.Errorf(formatComputeClusterCreateRollbackErrorUpdate, err, remErr)
+			}
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	d.SetId(cluster.Reference().Value)
+
+	return resourceVSphereComputeClusterRead(d, meta)
+}
+
+func resourceVSphereComputeClusterRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	cluster, err := clusterFromID(client, d.Id())
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+	props, err := clusterProperties(cluster)
+	if err != nil {
+		return fmt.Errorf("could not get properties for cluster: %s", err)
+	}
+
+	// Discover the datacenter and relative folder from the inventory path. We
+	// use the cluster as the source of truth here so that we can support
+	// import.
+	dcp, err := rootPathParticleHost.SplitDatacenter(cluster.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing datacenter from cluster path %q: %s", cluster.InventoryPath, err)
+	}
+	dc, err := getDatacenter(client, dcp)
+	if err != nil {
+		return fmt.Errorf("cannot find datacenter from path %q: %s", dcp, err)
+	}
+	folder, err := rootPathParticleHost.SplitRelativeFolder(cluster.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing cluster path %q: %s", cluster.InventoryPath, err)
+	}
+	d.Set("name", props.Name)
+	d.Set("datacenter_id", dc.Reference().Value)
+	d.Set("folder", normalizeFolderPath(folder))
+	if props.ResourcePool != nil {
+		d.Set("resource_pool_id", props.ResourcePool.Value)
+	}
+
+	info, ok := props.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	if !ok {
+		return fmt.Errorf("unexpected configuration type for cluster: %T", props.ConfigurationEx)
+	}
+	if err := flattenClusterConfigInfoEx(d, info); err != nil {
+		return err
+	}
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, cluster, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereComputeClusterUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	cluster, err := clusterFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	// Apply any pending tags first as it's the lesser expensive of the
+	// operations
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, cluster); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	// Rename this cluster if our name has drifted.
+	if d.HasChange("name") {
+		if err := renameObject(client, cluster.Reference(), d.Get("name").(string)); err != nil {
+			return err
+		}
+	}
+
+	// Update folder if necessary
+	if d.HasChange("folder") {
+		folder := d.Get("folder").(string)
+		if err := moveClusterToFolder(client, cluster, folder); err != nil {
+			return fmt.Errorf("could not move cluster to folder %q: %s", folder, err)
+		}
+	}
+
+	spec := expandClusterConfigSpecEx(d)
+	if err := reconfigureCluster(cluster, spec); err != nil {
+		return fmt.Errorf("error reconfiguring cluster: %s", err)
+	}
+
+	return resourceVSphereComputeClusterRead(d, meta)
+}
+
+func resourceVSphereComputeClusterDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	cluster, err := clusterFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+	props, err := clusterProperties(cluster)
+	if err != nil {
+		return fmt.Errorf("could not get properties for cluster: %s", err)
+	}
+
+	// Refuse to delete a cluster that still has hosts in it. Destroying the
+	// cluster in this state would remove the hosts from inventory as well.
+	if len(props.Host) > 0 {
+		return fmt.Errorf("cluster %q still has %d host(s) - please remove all hosts from the cluster before deleting it", props.Name, len(props.Host))
+	}
+
+	if err := deleteCluster(cluster); err != nil {
+		return fmt.Errorf("error deleting cluster: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereComputeClusterImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the full path to a specific cluster, for which we just get
+	// the MOID for and then pass off to Read.
+	p := d.Id()
+	if !strings.HasPrefix(p, "/") {
+		return nil, errors.New("path must start with a trailing slash")
+	}
+	client := meta.(*VSphereClient).vimClient
+	cluster, err := clusterFromAbsolutePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(cluster.Reference().Value)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,148 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereComputeClusterHostGroup() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"host_system_ids": &schema.Schema{
+			Type:        schema.TypeSet,
+			Description: "The managed object IDs of the hosts in this group.",
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+	}
+	mergeSchema(s, schemaClusterGroupInfo())
+
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterHostGroupCreate,
+		Read:   resourceVSphereComputeClusterHostGroupRead,
+		Update: resourceVSphereComputeClusterHostGroupUpdate,
+		Delete: resourceVSphereComputeClusterHostGroupDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereComputeClusterHostGroupImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereComputeClusterHostGroupCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	clusterID := d.Get("compute_cluster_id").(string)
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info := expandClusterHostGroup(d)
+	if err := createClusterGroup(cluster, info); err != nil {
+		return fmt.Errorf("error creating host group: %s", err)
+	}
+
+	saveClusterGroupID(d, clusterID, info.Name)
+
+	return resourceVSphereComputeClusterHostGroupRead(d, meta)
+}
+
+func resourceVSphereComputeClusterHostGroupRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	clusterID, name, err := clusterGroupIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	group, err := clusterGroupFromName(cluster, name)
+	if err != nil {
+		return fmt.Errorf("error fetching group: %s", err)
+	}
+	if group == nil {
+		// The group is gone, so remove it from state.
+		d.SetId("")
+		return nil
+	}
+	info, ok := group.(*types.ClusterHostGroup)
+	if !ok {
+		return fmt.Errorf("group %q on cluster %q is not a host group (type %T)", name, clusterID, group)
+	}
+
+	d.Set("compute_cluster_id", clusterID)
+	return flattenClusterHostGroup(d, info)
+}
+
+func resourceVSphereComputeClusterHostGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	clusterID, _, err := clusterGroupIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info := expandClusterHostGroup(d)
+	if err := updateClusterGroup(cluster, info); err != nil {
+		return fmt.Errorf("error updating host group: %s", err)
+	}
+
+	return resourceVSphereComputeClusterHostGroupRead(d, meta)
+}
+
+func resourceVSphereComputeClusterHostGroupDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	clusterID, name, err := clusterGroupIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	if err := deleteClusterGroup(cluster, name); err != nil {
+		return fmt.Errorf("error deleting host group: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereComputeClusterHostGroupImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the full path to the cluster and the name of the group.
+	p, name, err := splitClusterObjectImportID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	client := meta.(*VSphereClient).vimClient
+	cluster, err := clusterFromAbsolutePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	group, err := clusterGroupFromName(cluster, name)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, fmt.Errorf("could not find group %q on cluster", name)
+	}
+	if _, ok := group.(*types.ClusterHostGroup); !ok {
+		return nil, fmt.Errorf("group %q is not a host group (type %T)", name, group)
+	}
+	saveClusterGroupID(d, cluster.Reference().Value, name)
+	return []*schema.ResourceData{d}, nil
+}
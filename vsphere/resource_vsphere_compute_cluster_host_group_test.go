@@ -0,0 +1,160 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterHostGroup(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterHostGroupCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterHostGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterHostGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterHostGroupConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterHostGroupExists(true),
+							testAccResourceVSphereComputeClusterHostGroupMatchMembership(1),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterHostGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterHostGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterHostGroupConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterHostGroupExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster_host_group.group",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							return testGetComputeClusterObjectImportID(s, "vsphere_compute_cluster_host_group.group")
+						},
+						Config: testAccResourceVSphereComputeClusterHostGroupConfig(),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterHostGroupCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereComputeClusterHostGroupPreCheck(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster_host_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER_ID") == "" {
+		t.Skip("set VSPHERE_CLUSTER_ID to run vsphere_compute_cluster_host_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_compute_cluster_host_group acceptance tests")
+	}
+}
+
+func testAccResourceVSphereComputeClusterHostGroupExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		group, err := testGetComputeClusterGroup(s, "vsphere_compute_cluster_host_group.group")
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && expected == false {
+				// Cluster is gone, so the group is as well
+				return nil
+			}
+			return err
+		}
+		switch {
+		case group == nil && expected:
+			return fmt.Errorf("expected host group to exist")
+		case group != nil && !expected:
+			return fmt.Errorf("expected host group to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterHostGroupMatchMembership(hostCount int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		group, err := testGetComputeClusterGroup(s, "vsphere_compute_cluster_host_group.group")
+		if err != nil {
+			return err
+		}
+		info, ok := group.(*types.ClusterHostGroup)
+		if !ok {
+			return fmt.Errorf("expected group to be *types.ClusterHostGroup, got %T", group)
+		}
+		if len(info.Host) != hostCount {
+			return fmt.Errorf("expected %d hosts in group, got %d", hostCount, len(info.Host))
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterHostGroupConfig() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster_id" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_compute_cluster_host_group" "group" {
+  name               = "terraform-test-host-group"
+  compute_cluster_id = "${var.cluster_id}"
+  host_system_ids    = ["${data.vsphere_host.host.id}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER_ID"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+	)
+}
@@ -0,0 +1,451 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const testAccResourceVSphereComputeClusterConfigExpectedName = "terraform-compute-cluster-test"
+const testAccResourceVSphereComputeClusterConfigExpectedAltName = "terraform-compute-cluster-test-renamed"
+const testAccResourceVSphereComputeClusterConfigExpectedFolder = "terraform-compute-cluster-test-folder"
+
+func TestAccResourceVSphereComputeCluster(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigBasic(
+							testAccResourceVSphereComputeClusterConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterHasName(testAccResourceVSphereComputeClusterConfigExpectedName),
+						),
+					},
+				},
+			},
+		},
+		{
+			"DRS and HA",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigDRSHA(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterHasDRS(true, types.DrsBehaviorFullyAutomated),
+							testAccResourceVSphereComputeClusterHasHA(true),
+						),
+					},
+				},
+			},
+		},
+		{
+			"enable DRS and HA on update",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigBasic(
+							testAccResourceVSphereComputeClusterConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterHasDRS(false, types.DrsBehaviorManual),
+							testAccResourceVSphereComputeClusterHasHA(false),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterConfigDRSHA(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterHasDRS(true, types.DrsBehaviorFullyAutomated),
+							testAccResourceVSphereComputeClusterHasHA(true),
+						),
+					},
+				},
+			},
+		},
+		{
+			"rename",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigBasic(
+							testAccResourceVSphereComputeClusterConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterHasName(testAccResourceVSphereComputeClusterConfigExpectedName),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterConfigBasic(
+							testAccResourceVSphereComputeClusterConfigExpectedAltName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterHasName(testAccResourceVSphereComputeClusterConfigExpectedAltName),
+						),
+					},
+				},
+			},
+		},
+		{
+			"move to folder",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigBasic(
+							testAccResourceVSphereComputeClusterConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterConfigFolder(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterMatchInventoryPath(testAccResourceVSphereComputeClusterConfigExpectedFolder),
+						),
+					},
+				},
+			},
+		},
+		{
+			"tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigTag(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+							testAccResourceVSphereComputeClusterCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterConfigBasic(
+							testAccResourceVSphereComputeClusterConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster.compute_cluster",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							cluster, err := testGetComputeCluster(s, "compute_cluster")
+							if err != nil {
+								return "", err
+							}
+							return cluster.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereComputeClusterConfigBasic(
+							testAccResourceVSphereComputeClusterConfigExpectedName,
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereComputeClusterExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		cluster, err := testGetComputeCluster(s, "compute_cluster")
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected cluster %q to be missing", cluster.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterHasName(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetComputeClusterProperties(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+		actual := props.Name
+		if expected != actual {
+			return fmt.Errorf("expected name to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterHasDRS(enabled bool, behavior types.DrsBehavior) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetComputeClusterProperties(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+		drs := props.ConfigurationEx.(*types.ClusterConfigInfoEx).DrsConfig
+		if drs.Enabled == nil || *drs.Enabled != enabled {
+			return fmt.Errorf("expected DRS enabled to be %t", enabled)
+		}
+		if drs.DefaultVmBehavior != behavior {
+			return fmt.Errorf("expected DRS automation level to be %q, got %q", behavior, drs.DefaultVmBehavior)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterHasHA(enabled bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetComputeClusterProperties(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+		das := props.ConfigurationEx.(*types.ClusterConfigInfoEx).DasConfig
+		if das.Enabled == nil || *das.Enabled != enabled {
+			return fmt.Errorf("expected HA enabled to be %t", enabled)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereComputeClusterMatchInventoryPath checks to make sure
+// the cluster is in the supplied inventory path.
+func testAccResourceVSphereComputeClusterMatchInventoryPath(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		cluster, err := testGetComputeCluster(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+
+		expected, err := rootPathParticleHost.PathFromNewRoot(cluster.InventoryPath, rootPathParticleHost, expected)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		actual := path.Dir(cluster.InventoryPath)
+		if expected != actual {
+			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereComputeClusterCheckTags is a check to ensure that any
+// tags that have been created with the supplied resource name have been
+// attached to the cluster.
+func testAccResourceVSphereComputeClusterCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		cluster, err := testGetComputeCluster(s, "compute_cluster")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, cluster, tagResName)
+	}
+}
+
+func testAccResourceVSphereComputeClusterConfigBasic(name string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster_name" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "${var.cluster_name}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		name,
+	)
+}
+
+func testAccResourceVSphereComputeClusterConfigDRSHA() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster_name" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "${var.cluster_name}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  drs_enabled          = true
+  drs_automation_level = "fullyAutomated"
+
+  ha_enabled                   = true
+  ha_admission_control_enabled = false
+  ha_vm_restart_priority       = "high"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		testAccResourceVSphereComputeClusterConfigExpectedName,
+	)
+}
+
+func testAccResourceVSphereComputeClusterConfigFolder() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster_name" {
+  default = "%s"
+}
+
+variable "folder" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_folder" "folder" {
+  path          = "${var.folder}"
+  type          = "host"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "${var.cluster_name}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  folder        = "${vsphere_folder.folder.path}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		testAccResourceVSphereComputeClusterConfigExpectedName,
+		testAccResourceVSphereComputeClusterConfigExpectedFolder,
+	)
+}
+
+func testAccResourceVSphereComputeClusterConfigTag() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster_name" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "ClusterComputeResource",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_compute_cluster" "compute_cluster" {
+  name          = "${var.cluster_name}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  tags          = ["${vsphere_tag.terraform-test-tag.id}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		testAccResourceVSphereComputeClusterConfigExpectedName,
+	)
+}
@@ -0,0 +1,282 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterVMAffinityRule(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterVMAffinityRuleCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMAffinityRulePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(2, true, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+							testAccResourceVSphereComputeClusterVMAffinityRuleMatch("terraform-test-affinity-rule", true, false, 2),
+						),
+					},
+				},
+			},
+		},
+		{
+			"disable and make mandatory",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMAffinityRulePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(2, true, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(2, false, true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+							testAccResourceVSphereComputeClusterVMAffinityRuleMatch("terraform-test-affinity-rule", false, true, 2),
+						),
+					},
+				},
+			},
+		},
+		{
+			"add a virtual machine",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMAffinityRulePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(2, true, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(3, true, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+							testAccResourceVSphereComputeClusterVMAffinityRuleMatch("terraform-test-affinity-rule", true, false, 3),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMAffinityRulePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMAffinityRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(2, true, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMAffinityRuleExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster_vm_affinity_rule.rule",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							return testGetComputeClusterObjectImportID(s, "vsphere_compute_cluster_vm_affinity_rule.rule")
+						},
+						Config: testAccResourceVSphereComputeClusterVMAffinityRuleConfig(2, true, false),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterVMAffinityRuleCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMAffinityRulePreCheck(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster_vm_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER") == "" {
+		t.Skip("set VSPHERE_CLUSTER to run vsphere_compute_cluster_vm_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER_ID") == "" {
+		t.Skip("set VSPHERE_CLUSTER_ID to run vsphere_compute_cluster_vm_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_compute_cluster_vm_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_compute_cluster_vm_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_compute_cluster_vm_affinity_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_compute_cluster_vm_affinity_rule acceptance tests")
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMAffinityRuleExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rule, err := testGetComputeClusterRule(s, "vsphere_compute_cluster_vm_affinity_rule.rule")
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && expected == false {
+				// Cluster is gone, so the rule is as well
+				return nil
+			}
+			return err
+		}
+		switch {
+		case rule == nil && expected:
+			return fmt.Errorf("expected affinity rule to exist")
+		case rule != nil && !expected:
+			return fmt.Errorf("expected affinity rule to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMAffinityRuleMatch(name string, enabled, mandatory bool, vmCount int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rule, err := testGetComputeClusterRule(s, "vsphere_compute_cluster_vm_affinity_rule.rule")
+		if err != nil {
+			return err
+		}
+		info, ok := rule.(*types.ClusterAffinityRuleSpec)
+		if !ok {
+			return fmt.Errorf("expected rule to be *types.ClusterAffinityRuleSpec, got %T", rule)
+		}
+		if info.Name != name {
+			return fmt.Errorf("expected name to be %q, got %q", name, info.Name)
+		}
+		if *info.Enabled != enabled {
+			return fmt.Errorf("expected enabled to be %t, got %t", enabled, *info.Enabled)
+		}
+		if *info.Mandatory != mandatory {
+			return fmt.Errorf("expected mandatory to be %t, got %t", mandatory, *info.Mandatory)
+		}
+		if len(info.Vm) != vmCount {
+			return fmt.Errorf("expected %d virtual machines in rule, got %d", vmCount, len(info.Vm))
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMAffinityRuleConfig(count int, enabled, mandatory bool) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "cluster_id" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "vm_count" {
+  default = "%d"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  count         = "${var.vm_count}"
+  name          = "terraform-test-${count.index}"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 1
+  memory = 512
+
+  network_interface {
+    label = "${var.network_label}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+  }
+
+  linked_clone       = "${var.linked_clone != "" ? "true" : "false" }"
+  skip_customization = true
+  wait_for_guest_net = false
+}
+
+resource "vsphere_compute_cluster_vm_affinity_rule" "rule" {
+  name                = "terraform-test-affinity-rule"
+  compute_cluster_id  = "${var.cluster_id}"
+  virtual_machine_ids = ["${vsphere_virtual_machine.vm.*.uuid}"]
+  enabled             = %t
+  mandatory           = %t
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_CLUSTER_ID"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		count,
+		enabled,
+		mandatory,
+	)
+}
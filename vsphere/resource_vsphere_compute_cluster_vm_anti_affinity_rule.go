@@ -0,0 +1,151 @@
+package vsphere
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereComputeClusterVMAntiAffinityRule() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"virtual_machine_ids": schemaClusterVMRuleVirtualMachineIDs(),
+	}
+	mergeSchema(s, schemaClusterRuleInfo())
+
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterVMAntiAffinityRuleCreate,
+		Read:   resourceVSphereComputeClusterVMAntiAffinityRuleRead,
+		Update: resourceVSphereComputeClusterVMAntiAffinityRuleUpdate,
+		Delete: resourceVSphereComputeClusterVMAntiAffinityRuleDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereComputeClusterVMAntiAffinityRuleImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereComputeClusterVMAntiAffinityRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	clusterID := d.Get("compute_cluster_id").(string)
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := expandClusterAntiAffinityRuleSpec(client, d)
+	if err != nil {
+		return err
+	}
+	key, err := createClusterRule(cluster, info)
+	if err != nil {
+		return fmt.Errorf("error creating anti-affinity rule: %s", err)
+	}
+
+	saveClusterRuleID(d, clusterID, key)
+
+	return resourceVSphereComputeClusterVMAntiAffinityRuleRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMAntiAffinityRuleRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	clusterID, key, err := clusterRuleIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	rule, err := clusterRuleFromKey(cluster, key)
+	if err != nil {
+		return fmt.Errorf("error fetching rule: %s", err)
+	}
+	if rule == nil {
+		// The rule is gone, so remove it from state.
+		d.SetId("")
+		return nil
+	}
+	info, ok := rule.(*types.ClusterAntiAffinityRuleSpec)
+	if !ok {
+		return fmt.Errorf("rule %d on cluster %q is not a VM anti-affinity rule (type %T)", key, clusterID, rule)
+	}
+
+	d.Set("compute_cluster_id", clusterID)
+	if err := flattenClusterRuleInfo(d, &info.ClusterRuleInfo); err != nil {
+		return err
+	}
+	return flattenClusterVirtualMachineIDs(client, d, info.Vm)
+}
+
+func resourceVSphereComputeClusterVMAntiAffinityRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	clusterID, _, err := clusterRuleIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := expandClusterAntiAffinityRuleSpec(client, d)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterRule(cluster, info); err != nil {
+		return fmt.Errorf("error updating anti-affinity rule: %s", err)
+	}
+
+	return resourceVSphereComputeClusterVMAntiAffinityRuleRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMAntiAffinityRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	clusterID, key, err := clusterRuleIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	if err := deleteClusterRule(cluster, key); err != nil {
+		return fmt.Errorf("error deleting anti-affinity rule: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereComputeClusterVMAntiAffinityRuleImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the full path to the cluster and the name of the rule,
+	// which we look up to get the key.
+	p, name, err := splitClusterObjectImportID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	client := meta.(*VSphereClient).vimClient
+	cluster, err := clusterFromAbsolutePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := clusterRuleFromName(cluster, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := rule.(*types.ClusterAntiAffinityRuleSpec); !ok {
+		return nil, fmt.Errorf("rule %q is not a VM anti-affinity rule (type %T)", name, rule)
+	}
+	saveClusterRuleID(d, cluster.Reference().Value, rule.GetClusterRuleInfo().Key)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,243 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterVMGroup(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterVMGroupCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMGroupConfig(2),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMGroupExists(true),
+							testAccResourceVSphereComputeClusterVMGroupMatchMembership(2),
+						),
+					},
+				},
+			},
+		},
+		{
+			"add a virtual machine",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMGroupConfig(2),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMGroupExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterVMGroupConfig(3),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMGroupExists(true),
+							testAccResourceVSphereComputeClusterVMGroupMatchMembership(3),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMGroupConfig(2),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMGroupExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster_vm_group.group",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							return testGetComputeClusterObjectImportID(s, "vsphere_compute_cluster_vm_group.group")
+						},
+						Config: testAccResourceVSphereComputeClusterVMGroupConfig(2),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterVMGroupCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMGroupPreCheck(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster_vm_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER") == "" {
+		t.Skip("set VSPHERE_CLUSTER to run vsphere_compute_cluster_vm_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER_ID") == "" {
+		t.Skip("set VSPHERE_CLUSTER_ID to run vsphere_compute_cluster_vm_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_compute_cluster_vm_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_compute_cluster_vm_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_compute_cluster_vm_group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_compute_cluster_vm_group acceptance tests")
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMGroupExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		group, err := testGetComputeClusterGroup(s, "vsphere_compute_cluster_vm_group.group")
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && expected == false {
+				// Cluster is gone, so the group is as well
+				return nil
+			}
+			return err
+		}
+		switch {
+		case group == nil && expected:
+			return fmt.Errorf("expected VM group to exist")
+		case group != nil && !expected:
+			return fmt.Errorf("expected VM group to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMGroupMatchMembership(vmCount int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		group, err := testGetComputeClusterGroup(s, "vsphere_compute_cluster_vm_group.group")
+		if err != nil {
+			return err
+		}
+		info, ok := group.(*types.ClusterVmGroup)
+		if !ok {
+			return fmt.Errorf("expected group to be *types.ClusterVmGroup, got %T", group)
+		}
+		if len(info.Vm) != vmCount {
+			return fmt.Errorf("expected %d virtual machines in group, got %d", vmCount, len(info.Vm))
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMGroupConfig(count int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "cluster_id" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "vm_count" {
+  default = "%d"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  count         = "${var.vm_count}"
+  name          = "terraform-test-${count.index}"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 1
+  memory = 512
+
+  network_interface {
+    label = "${var.network_label}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+  }
+
+  linked_clone       = "${var.linked_clone != "" ? "true" : "false" }"
+  skip_customization = true
+  wait_for_guest_net = false
+}
+
+resource "vsphere_compute_cluster_vm_group" "group" {
+  name                = "terraform-test-vm-group"
+  compute_cluster_id  = "${var.cluster_id}"
+  virtual_machine_ids = ["${vsphere_virtual_machine.vm.*.uuid}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_CLUSTER_ID"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		count,
+	)
+}
@@ -0,0 +1,169 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func resourceVSphereComputeClusterVMHostRule() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"vm_group_name": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The name of the virtual machine group to use with this rule.",
+			Required:    true,
+		},
+		"affinity_host_group_name": &schema.Schema{
+			Type:          schema.TypeString,
+			Description:   "When this field is used, virtual machines defined in vm_group_name will be run on the hosts defined in this host group.",
+			Optional:      true,
+			ConflictsWith: []string{"anti_affinity_host_group_name"},
+		},
+		"anti_affinity_host_group_name": &schema.Schema{
+			Type:          schema.TypeString,
+			Description:   "When this field is used, virtual machines defined in vm_group_name will not be run on the hosts defined in this host group.",
+			Optional:      true,
+			ConflictsWith: []string{"affinity_host_group_name"},
+		},
+	}
+	mergeSchema(s, schemaClusterRuleInfo())
+
+	return &schema.Resource{
+		Create: resourceVSphereComputeClusterVMHostRuleCreate,
+		Read:   resourceVSphereComputeClusterVMHostRuleRead,
+		Update: resourceVSphereComputeClusterVMHostRuleUpdate,
+		Delete: resourceVSphereComputeClusterVMHostRuleDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereComputeClusterVMHostRuleImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereComputeClusterVMHostRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	if d.Get("affinity_host_group_name").(string) == "" && d.Get("anti_affinity_host_group_name").(string) == "" {
+		return errors.New("one of affinity_host_group_name or anti_affinity_host_group_name must be specified")
+	}
+
+	clusterID := d.Get("compute_cluster_id").(string)
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := expandClusterVmHostRuleInfo(d)
+	if err != nil {
+		return err
+	}
+	key, err := createClusterRule(cluster, info)
+	if err != nil {
+		return fmt.Errorf("error creating VM-host rule: %s", err)
+	}
+
+	saveClusterRuleID(d, clusterID, key)
+
+	return resourceVSphereComputeClusterVMHostRuleRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMHostRuleRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	clusterID, key, err := clusterRuleIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	rule, err := clusterRuleFromKey(cluster, key)
+	if err != nil {
+		return fmt.Errorf("error fetching rule: %s", err)
+	}
+	if rule == nil {
+		// The rule is gone, so remove it from state.
+		d.SetId("")
+		return nil
+	}
+	info, ok := rule.(*types.ClusterVmHostRuleInfo)
+	if !ok {
+		return fmt.Errorf("rule %d on cluster %q is not a VM-host rule (type %T)", key, clusterID, rule)
+	}
+
+	d.Set("compute_cluster_id", clusterID)
+	return flattenClusterVmHostRuleInfo(d, info)
+}
+
+func resourceVSphereComputeClusterVMHostRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	clusterID, _, err := clusterRuleIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	info, err := expandClusterVmHostRuleInfo(d)
+	if err != nil {
+		return err
+	}
+	if err := updateClusterRule(cluster, info); err != nil {
+		return fmt.Errorf("error updating VM-host rule: %s", err)
+	}
+
+	return resourceVSphereComputeClusterVMHostRuleRead(d, meta)
+}
+
+func resourceVSphereComputeClusterVMHostRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	clusterID, key, err := clusterRuleIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	cluster, err := clusterFromID(client, clusterID)
+	if err != nil {
+		return fmt.Errorf("cannot locate cluster: %s", err)
+	}
+
+	if err := deleteClusterRule(cluster, key); err != nil {
+		return fmt.Errorf("error deleting VM-host rule: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereComputeClusterVMHostRuleImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the full path to the cluster and the name of the rule,
+	// which we look up to get the key.
+	p, name, err := splitClusterObjectImportID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	client := meta.(*VSphereClient).vimClient
+	cluster, err := clusterFromAbsolutePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := clusterRuleFromName(cluster, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := rule.(*types.ClusterVmHostRuleInfo); !ok {
+		return nil, fmt.Errorf("rule %q is not a VM-host rule (type %T)", name, rule)
+	}
+	saveClusterRuleID(d, cluster.Reference().Value, rule.GetClusterRuleInfo().Key)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,290 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereComputeClusterVMHostRule(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereComputeClusterVMHostRuleCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"affinity",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMHostRulePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMHostRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMHostRuleConfig(true, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMHostRuleExists(true),
+							testAccResourceVSphereComputeClusterVMHostRuleMatch(true, false),
+						),
+					},
+				},
+			},
+		},
+		{
+			"affinity, then anti-affinity and mandatory",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMHostRulePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMHostRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMHostRuleConfig(true, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMHostRuleExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereComputeClusterVMHostRuleConfig(false, true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMHostRuleExists(true),
+							testAccResourceVSphereComputeClusterVMHostRuleMatch(false, true),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereComputeClusterVMHostRulePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereComputeClusterVMHostRuleExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereComputeClusterVMHostRuleConfig(true, false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereComputeClusterVMHostRuleExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_compute_cluster_vm_host_rule.rule",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							return testGetComputeClusterObjectImportID(s, "vsphere_compute_cluster_vm_host_rule.rule")
+						},
+						Config: testAccResourceVSphereComputeClusterVMHostRuleConfig(true, false),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereComputeClusterVMHostRuleCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMHostRulePreCheck(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_compute_cluster_vm_host_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER") == "" {
+		t.Skip("set VSPHERE_CLUSTER to run vsphere_compute_cluster_vm_host_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_CLUSTER_ID") == "" {
+		t.Skip("set VSPHERE_CLUSTER_ID to run vsphere_compute_cluster_vm_host_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL to run vsphere_compute_cluster_vm_host_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_LABEL") == "" {
+		t.Skip("set VSPHERE_NETWORK_LABEL to run vsphere_compute_cluster_vm_host_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE") == "" {
+		t.Skip("set VSPHERE_DATASTORE to run vsphere_compute_cluster_vm_host_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_TEMPLATE") == "" {
+		t.Skip("set VSPHERE_TEMPLATE to run vsphere_compute_cluster_vm_host_rule acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_compute_cluster_vm_host_rule acceptance tests")
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMHostRuleExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rule, err := testGetComputeClusterRule(s, "vsphere_compute_cluster_vm_host_rule.rule")
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && expected == false {
+				// Cluster is gone, so the rule is as well
+				return nil
+			}
+			return err
+		}
+		switch {
+		case rule == nil && expected:
+			return fmt.Errorf("expected VM-host rule to exist")
+		case rule != nil && !expected:
+			return fmt.Errorf("expected VM-host rule to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMHostRuleMatch(affinity, mandatory bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rule, err := testGetComputeClusterRule(s, "vsphere_compute_cluster_vm_host_rule.rule")
+		if err != nil {
+			return err
+		}
+		info, ok := rule.(*types.ClusterVmHostRuleInfo)
+		if !ok {
+			return fmt.Errorf("expected rule to be *types.ClusterVmHostRuleInfo, got %T", rule)
+		}
+		if info.VmGroupName != "terraform-test-vm-group" {
+			return fmt.Errorf("expected VM group to be %q, got %q", "terraform-test-vm-group", info.VmGroupName)
+		}
+		hostGroup := info.AntiAffineHostGroupName
+		if affinity {
+			hostGroup = info.AffineHostGroupName
+		}
+		if hostGroup != "terraform-test-host-group" {
+			return fmt.Errorf("expected host group to be %q, got %q (affinity: %t)", "terraform-test-host-group", hostGroup, affinity)
+		}
+		if *info.Mandatory != mandatory {
+			return fmt.Errorf("expected mandatory to be %t, got %t", mandatory, *info.Mandatory)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereComputeClusterVMHostRuleConfig(affinity, mandatory bool) string {
+	hostGroupAttr := "anti_affinity_host_group_name"
+	if affinity {
+		hostGroupAttr = "affinity_host_group_name"
+	}
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "cluster_id" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "vm_count" {
+  default = "%d"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  count         = "${var.vm_count}"
+  name          = "terraform-test-${count.index}"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 1
+  memory = 512
+
+  network_interface {
+    label = "${var.network_label}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+  }
+
+  linked_clone       = "${var.linked_clone != "" ? "true" : "false" }"
+  skip_customization = true
+  wait_for_guest_net = false
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_compute_cluster_vm_group" "vm_group" {
+  name                = "terraform-test-vm-group"
+  compute_cluster_id  = "${var.cluster_id}"
+  virtual_machine_ids = ["${vsphere_virtual_machine.vm.*.uuid}"]
+}
+
+resource "vsphere_compute_cluster_host_group" "host_group" {
+  name               = "terraform-test-host-group"
+  compute_cluster_id = "${var.cluster_id}"
+  host_system_ids    = ["${data.vsphere_host.host.id}"]
+}
+
+resource "vsphere_compute_cluster_vm_host_rule" "rule" {
+  name               = "terraform-test-vm-host-rule"
+  compute_cluster_id = "${var.cluster_id}"
+  vm_group_name      = "${vsphere_compute_cluster_vm_group.vm_group.name}"
+  %s = "${vsphere_compute_cluster_host_group.host_group.name}"
+  mandatory          = %t
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_CLUSTER_ID"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		2,
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		hostGroupAttr,
+		mandatory,
+	)
+}
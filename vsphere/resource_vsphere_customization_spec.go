@@ -0,0 +1,295 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+)
+
+func resourceVSphereCustomizationSpec() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereCustomizationSpecCreate,
+		Read:   resourceVSphereCustomizationSpecRead,
+		Update: resourceVSphereCustomizationSpecUpdate,
+		Delete: resourceVSphereCustomizationSpecDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "The name of the customization spec.",
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The description of the customization spec.",
+				Optional:    true,
+			},
+			"dns_server_list": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The list of DNS servers for all network interfaces.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"dns_suffix_list": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The list of DNS search domains. Linux only.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"linux_options": &schema.Schema{
+				Type:          schema.TypeList,
+				Description:   "The settings of a spec that customizes Linux guests.",
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"windows_options"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"domain": &schema.Schema{
+							Type:         schema.TypeString,
+							Description:  "The domain name of the guest.",
+							Required:     true,
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"time_zone": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The time zone of the guest, such as Etc/UTC.",
+							Optional:    true,
+							Default:     "Etc/UTC",
+						},
+						"hw_clock_utc": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Set the hardware clock of the guest to UTC.",
+							Optional:    true,
+							Default:     true,
+						},
+					},
+				},
+			},
+			"windows_options": &schema.Schema{
+				Type:          schema.TypeList,
+				Description:   "The sysprep settings of a spec that customizes Windows guests.",
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"linux_options"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"full_name": &schema.Schema{
+							Type:         schema.TypeString,
+							Description:  "The full name of the registered user of the guest.",
+							Required:     true,
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"organization_name": &schema.Schema{
+							Type:         schema.TypeString,
+							Description:  "The organization name of the registered user of the guest.",
+							Required:     true,
+							ValidateFunc: validation.NoZeroValues,
+						},
+						"product_key": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The Windows product key.",
+							Optional:    true,
+						},
+						"admin_password": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The password of the local Administrator account.",
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"auto_logon": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Log on as Administrator automatically after customization.",
+							Optional:    true,
+							Default:     false,
+						},
+						"auto_logon_count": &schema.Schema{
+							Type:         schema.TypeInt,
+							Description:  "The number of times to log on as Administrator automatically.",
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"time_zone": &schema.Schema{
+							Type:         schema.TypeInt,
+							Description:  "The numeric Microsoft time zone index of the guest. The default of 85 is GMT.",
+							Optional:     true,
+							Default:      windowsTimeZoneUTC,
+							ValidateFunc: validateWindowsTimeZoneIndex,
+						},
+						"workgroup": &schema.Schema{
+							Type:          schema.TypeString,
+							Description:   "The workgroup to join.",
+							Optional:      true,
+							ConflictsWith: []string{"windows_options.0.join_domain"},
+						},
+						"join_domain": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The Active Directory domain to join.",
+							Optional:    true,
+						},
+						"domain_admin_user": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The user to join the domain with.",
+							Optional:    true,
+						},
+						"domain_admin_password": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The password of domain_admin_user.",
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"run_once_command_list": &schema.Schema{
+							Type:        schema.TypeList,
+							Description: "Commands to run the first time a user logs on after customization.",
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"network_interface": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The settings of each network interface, in device order. Virtual machines that use the spec replace the IP settings with their own.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ipv4_address": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The IPv4 address of the interface. Empty uses DHCP.",
+							Optional:    true,
+						},
+						"ipv4_netmask": &schema.Schema{
+							Type:         schema.TypeInt,
+							Description:  "The IPv4 prefix length of the interface.",
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(0, 32),
+						},
+						"ipv4_gateway": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The IPv4 default gateway of the interface.",
+							Optional:    true,
+						},
+						"dns_server_list": &schema.Schema{
+							Type:        schema.TypeList,
+							Description: "The list of DNS servers of the interface. Windows only.",
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"dns_domain": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "The DNS domain suffix of the interface. Windows only.",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"type": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The type of the customization spec, either Linux or Windows.",
+				Computed:    true,
+			},
+			"change_version": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The version of the customization spec in vCenter.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereCustomizationSpecCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	item, err := expandCustomizationSpecItem(d)
+	if err != nil {
+		return err
+	}
+	csm := object.NewCustomizationSpecManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := csm.CreateCustomizationSpec(ctx, item); err != nil {
+		return fmt.Errorf("error creating customization spec: %s", err)
+	}
+	d.SetId(item.Info.Name)
+	return resourceVSphereCustomizationSpecRead(d, meta)
+}
+
+func resourceVSphereCustomizationSpecRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	exists, err := customizationSpecExists(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error checking for customization spec %q: %s", d.Id(), err)
+	}
+	if !exists {
+		log.Printf("[DEBUG] Customization spec %q is gone", d.Id())
+		d.SetId("")
+		return nil
+	}
+	item, err := customizationSpecItemFromName(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error fetching customization spec %q: %s", d.Id(), err)
+	}
+	d.Set("type", item.Info.Type)
+	d.Set("change_version", item.Info.ChangeVersion)
+	return flattenCustomizationSpecItem(d, item)
+}
+
+func resourceVSphereCustomizationSpecUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	csm := object.NewCustomizationSpecManager(client.Client)
+	if d.HasChange("name") {
+		o, n := d.GetChange("name")
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer cancel()
+		if err := csm.RenameCustomizationSpec(ctx, o.(string), n.(string)); err != nil {
+			return fmt.Errorf("error renaming customization spec %q to %q: %s", o.(string), n.(string), err)
+		}
+		d.SetId(n.(string))
+	}
+
+	item, err := expandCustomizationSpecItem(d)
+	if err != nil {
+		return err
+	}
+	// The spec can only be overwritten if the change version matches the
+	// version currently stored in vCenter.
+	current, err := customizationSpecItemFromName(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("error fetching customization spec %q: %s", d.Id(), err)
+	}
+	item.Info.ChangeVersion = current.Info.ChangeVersion
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := csm.OverwriteCustomizationSpec(ctx, item); err != nil {
+		return fmt.Errorf("error updating customization spec %q: %s", d.Id(), err)
+	}
+	return resourceVSphereCustomizationSpecRead(d, meta)
+}
+
+func resourceVSphereCustomizationSpecDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	csm := object.NewCustomizationSpecManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := csm.DeleteCustomizationSpec(ctx, d.Id()); err != nil {
+		return fmt.Errorf("error deleting customization spec %q: %s", d.Id(), err)
+	}
+	d.SetId("")
+	return nil
+}
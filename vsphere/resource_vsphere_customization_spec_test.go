@@ -0,0 +1,198 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereCustomizationSpec(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereCustomizationSpecCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"linux",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereCustomizationSpecCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereCustomizationSpecConfigLinux(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereCustomizationSpecCheckExists(true),
+							testAccResourceVSphereCustomizationSpecCheckType(customizationSpecTypeLinux),
+							resource.TestCheckResourceAttr("vsphere_customization_spec.spec", "network_interface.0.dns_domain", "example.com"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"windows, rename and update",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereCustomizationSpecCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereCustomizationSpecConfigWindows("terraform-test-spec", "WORKGROUP"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereCustomizationSpecCheckExists(true),
+							testAccResourceVSphereCustomizationSpecCheckType(customizationSpecTypeWindows),
+							testAccResourceVSphereCustomizationSpecCheckWorkgroup("WORKGROUP"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereCustomizationSpecConfigWindows("terraform-test-spec-renamed", "TERRAFORM"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereCustomizationSpecCheckExists(true),
+							resource.TestCheckResourceAttr("vsphere_customization_spec.spec", "id", "terraform-test-spec-renamed"),
+							testAccResourceVSphereCustomizationSpecCheckWorkgroup("TERRAFORM"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereCustomizationSpecCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereCustomizationSpecConfigLinux(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereCustomizationSpecCheckExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_customization_spec.spec",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateId:     "terraform-test-spec",
+						Config:            testAccResourceVSphereCustomizationSpecConfigLinux(),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereCustomizationSpecCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereCustomizationSpecCheckExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vars, err := testClientVariablesForResource(s, "vsphere_customization_spec.spec")
+		if err != nil {
+			return err
+		}
+		exists, err := customizationSpecExists(vars.client, vars.resourceID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case exists && !expected:
+			return fmt.Errorf("expected customization spec %q to be missing", vars.resourceID)
+		case !exists && expected:
+			return fmt.Errorf("customization spec %q not found", vars.resourceID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereCustomizationSpecCheckType(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		item, err := testGetCustomizationSpec(s, "spec")
+		if err != nil {
+			return err
+		}
+		if item.Info.Type != expected {
+			return fmt.Errorf("expected customization spec type to be %q, got %q", expected, item.Info.Type)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereCustomizationSpecCheckWorkgroup(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		item, err := testGetCustomizationSpec(s, "spec")
+		if err != nil {
+			return err
+		}
+		sysprep, ok := item.Spec.Identity.(*types.CustomizationSysprep)
+		if !ok {
+			return errors.New("customization spec does not have a sysprep identity")
+		}
+		if actual := sysprep.Identification.JoinWorkgroup; actual != expected {
+			return fmt.Errorf("expected workgroup to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereCustomizationSpecConfigLinux() string {
+	return `
+resource "vsphere_customization_spec" "spec" {
+  name            = "terraform-test-spec"
+  description     = "Managed by Terraform"
+  dns_server_list = ["10.0.0.2", "10.0.0.3"]
+  dns_suffix_list = ["example.com"]
+
+  linux_options {
+    domain = "example.com"
+  }
+
+  network_interface {
+    dns_domain = "example.com"
+  }
+}
+`
+}
+
+func testAccResourceVSphereCustomizationSpecConfigWindows(name, workgroup string) string {
+	return fmt.Sprintf(`
+resource "vsphere_customization_spec" "spec" {
+  name = "%s"
+
+  windows_options {
+    full_name             = "terraform"
+    organization_name     = "terraform"
+    admin_password        = "VMw4re!1"
+    workgroup             = "%s"
+    run_once_command_list = ["cmd.exe /c echo terraform"]
+  }
+
+  network_interface {
+    ipv4_address    = "10.0.0.10"
+    ipv4_netmask    = 24
+    ipv4_gateway    = "10.0.0.1"
+    dns_server_list = ["10.0.0.2"]
+  }
+}
+`,
+		name,
+		workgroup,
+	)
+}
@@ -0,0 +1,248 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// formatDatastoreClusterCreateRollbackErrorUpdate defines the verbose error
+// for configuring a datastore cluster on creation where rollback was not
+// possible.
+const formatDatastoreClusterCreateRollbackErrorUpdate = `
+WARNING: Dangling resource!
+There was an error configuring your datastore cluster:
+%s
+Additionally, there was an error removing the created datastore cluster:
+%s
+You will need to remove this datastore cluster manually before trying again.
+`
+
+func resourceVSphereDatastoreCluster() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The name of the datastore cluster.",
+			Required:    true,
+		},
+		"datacenter_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the datacenter to put the datastore cluster in.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"folder": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The path to the datastore folder to put the datastore cluster in.",
+			Optional:    true,
+			StateFunc:   normalizeFolderPath,
+		},
+	}
+	mergeSchema(s, schemaStorageDrsPodConfigSpec())
+
+	// Add tags schema
+	s[vSphereTagAttributeKey] = tagsSchema()
+
+	return &schema.Resource{
+		Create: resourceVSphereDatastoreClusterCreate,
+		Read:   resourceVSphereDatastoreClusterRead,
+		Update: resourceVSphereDatastoreClusterUpdate,
+		Delete: resourceVSphereDatastoreClusterDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereDatastoreClusterImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereDatastoreClusterCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	folder, err := datastoreFolderFromObject(client, dc, d.Get("folder").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate folder: %s", err)
+	}
+
+	pod, err := createStoragePod(folder, d.Get("name").(string))
+	if err != nil {
+		return fmt.Errorf("error creating datastore cluster: %s", err)
+	}
+
+	// Apply the Storage DRS configuration. This can't be supplied on creation,
+	// so we need to roll back if it fails.
+	if err := reconfigureStoragePod(client, pod, expandStorageDrsConfigSpec(d)); err != nil {
+		if remErr := deleteStoragePod(pod); remErr != nil {
+			return fmt.Errorf(formatDatastoreClusterCreateRollbackErrorUpdate, err, remErr)
+		}
+		return fmt.Errorf("error configuring Storage DRS: %s", err)
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pod); err != nil {
+			if remErr := deleteStoragePod(pod); remErr != nil {
+				// We could not destroy the created datastore cluster and there is now
+				// a dangling resource. We need to instruct the user to remove the
+				// datastore cluster manually.
+				return fmt.Errorf(formatDatastoreClusterCreateRollbackErrorUpdate, err, remErr)
+			}
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	d.SetId(pod.Reference().Value)
+
+	return resourceVSphereDatastoreClusterRead(d, meta)
+}
+
+func resourceVSphereDatastoreClusterRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	pod, err := storagePodFromID(client, d.Id())
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+	props, err := storagePodProperties(pod)
+	if err != nil {
+		return fmt.Errorf("could not get properties for datastore cluster: %s", err)
+	}
+
+	// Discover the datacenter and relative folder from the inventory path. We
+	// use the datastore cluster as the source of truth here so that we can
+	// support import.
+	dcp, err := rootPathParticleDatastore.SplitDatacenter(pod.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing datacenter from datastore cluster path %q: %s", pod.InventoryPath, err)
+	}
+	dc, err := getDatacenter(client, dcp)
+	if err != nil {
+		return fmt.Errorf("cannot find datacenter from path %q: %s", dcp, err)
+	}
+	folder, err := rootPathParticleDatastore.SplitRelativeFolder(pod.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing datastore cluster path %q: %s", pod.InventoryPath, err)
+	}
+	d.Set("name", props.Name)
+	d.Set("datacenter_id", dc.Reference().Value)
+	d.Set("folder", normalizeFolderPath(folder))
+
+	if props.PodStorageDrsEntry == nil {
+		return errors.New("datastore cluster has no Storage DRS configuration")
+	}
+	if err := flattenStorageDrsConfigInfo(d, props.PodStorageDrsEntry.StorageDrsConfig); err != nil {
+		return err
+	}
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, pod, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereDatastoreClusterUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	pod, err := storagePodFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+
+	// Apply any pending tags first as it's the lesser expensive of the
+	// operations
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pod); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	// Rename this datastore cluster if our name has drifted.
+	if d.HasChange("name") {
+		if err := renameObject(client, pod.Reference(), d.Get("name").(string)); err != nil {
+			return err
+		}
+	}
+
+	// Update folder if necessary
+	if d.HasChange("folder") {
+		folder := d.Get("folder").(string)
+		if err := moveStoragePodToFolder(client, pod, folder); err != nil {
+			return fmt.Errorf("could not move datastore cluster to folder %q: %s", folder, err)
+		}
+	}
+
+	if err := reconfigureStoragePod(client, pod, expandStorageDrsConfigSpec(d)); err != nil {
+		return fmt.Errorf("error configuring Storage DRS: %s", err)
+	}
+
+	return resourceVSphereDatastoreClusterRead(d, meta)
+}
+
+func resourceVSphereDatastoreClusterDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	pod, err := storagePodFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore cluster: %s", err)
+	}
+
+	// Refuse to delete a datastore cluster that still has datastores in it.
+	// These should be moved out of the cluster or removed first.
+	ne, err := storagePodHasChildren(pod)
+	if err != nil {
+		return fmt.Errorf("error checking for datastore cluster contents: %s", err)
+	}
+	if ne {
+		return fmt.Errorf("datastore cluster %q still has datastores - please remove all datastores from the datastore cluster before deleting it", pod.InventoryPath)
+	}
+
+	if err := deleteStoragePod(pod); err != nil {
+		return fmt.Errorf("error deleting datastore cluster: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereDatastoreClusterImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the full path to a specific datastore cluster, for which
+	// we just get the MOID for and then pass off to Read.
+	p := d.Id()
+	if !strings.HasPrefix(p, "/") {
+		return nil, errors.New("path must start with a trailing slash")
+	}
+	client := meta.(*VSphereClient).vimClient
+	pod, err := storagePodFromAbsolutePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(pod.Reference().Value)
+	return []*schema.ResourceData{d}, nil
+}
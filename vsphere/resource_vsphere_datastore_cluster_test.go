@@ -0,0 +1,490 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereDatastoreCluster(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereDatastoreClusterCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDatastoreClusterPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+							testAccResourceVSphereDatastoreClusterCheckSDRSAutomationLevel("manual"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"change SDRS settings",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDatastoreClusterPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigSDRSSettings(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+							testAccResourceVSphereDatastoreClusterCheckSDRSAutomationLevel("automated"),
+							testAccResourceVSphereDatastoreClusterCheckSDRSThresholds(70, 20),
+						),
+					},
+				},
+			},
+		},
+		{
+			"rename",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDatastoreClusterPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigAltName(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+							testAccResourceVSphereDatastoreClusterCheckName("terraform-datastore-cluster-test-renamed"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"folder",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDatastoreClusterPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigWithFolder(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+							testAccResourceVSphereDatastoreClusterMatchInventoryPath("terraform-datastore-cluster-test-folder"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDatastoreClusterPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigWithTags(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+							testAccResourceVSphereDatastoreClusterCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"with NAS datastore member",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDatastoreClusterPreCheck(tp)
+					testAccResourceVSphereNasDatastorePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigNasMember(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+							testAccResourceVSphereDatastoreClusterCheckMember("vsphere_nas_datastore.datastore"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDatastoreClusterPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDatastoreClusterCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDatastoreClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_datastore_cluster.datastore_cluster",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							pod, err := testGetDatastoreCluster(s, "datastore_cluster")
+							if err != nil {
+								return "", err
+							}
+							return pod.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereDatastoreClusterConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDatastoreClusterCheckExists(true),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereDatastoreClusterCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_datastore_cluster acceptance tests")
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterCheckExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		_, err := testGetDatastoreCluster(s, "datastore_cluster")
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return errors.New("expected datastore cluster to be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterCheckName(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDatastoreClusterProperties(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		actual := props.Name
+		if expected != actual {
+			return fmt.Errorf("expected datastore cluster name to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterMatchInventoryPath(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pod, err := testGetDatastoreCluster(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+
+		expected, err := rootPathParticleDatastore.PathFromNewRoot(pod.InventoryPath, rootPathParticleDatastore, expected)
+		actual := path.Dir(pod.InventoryPath)
+		if err != nil {
+			return fmt.Errorf("bad: %s", err)
+		}
+		if expected != actual {
+			return fmt.Errorf("expected path to be %s, got %s", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterCheckSDRSAutomationLevel(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDatastoreClusterProperties(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		if props.PodStorageDrsEntry == nil {
+			return errors.New("datastore cluster has no Storage DRS configuration")
+		}
+		actual := props.PodStorageDrsEntry.StorageDrsConfig.PodConfig.DefaultVmBehavior
+		if expected != actual {
+			return fmt.Errorf("expected SDRS automation level to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterCheckSDRSThresholds(space, latency int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDatastoreClusterProperties(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		if props.PodStorageDrsEntry == nil {
+			return errors.New("datastore cluster has no Storage DRS configuration")
+		}
+		pc := props.PodStorageDrsEntry.StorageDrsConfig.PodConfig
+		if pc.SpaceLoadBalanceConfig == nil || pc.SpaceLoadBalanceConfig.SpaceUtilizationThreshold != space {
+			return fmt.Errorf("expected space utilization threshold to be %d, got %#v", space, pc.SpaceLoadBalanceConfig)
+		}
+		if pc.IoLoadBalanceConfig == nil || pc.IoLoadBalanceConfig.IoLatencyThreshold != latency {
+			return fmt.Errorf("expected I/O latency threshold to be %d, got %#v", latency, pc.IoLoadBalanceConfig)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pod, err := testGetDatastoreCluster(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, pod, tagResName)
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterCheckMember(dsResAddr string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pod, err := testGetDatastoreCluster(s, "datastore_cluster")
+		if err != nil {
+			return err
+		}
+		ds, err := testGetDatastore(s, dsResAddr)
+		if err != nil {
+			return err
+		}
+		props, err := datastoreProperties(ds)
+		if err != nil {
+			return err
+		}
+		if props.Parent == nil || props.Parent.Value != pod.Reference().Value {
+			return fmt.Errorf("expected datastore %q to be a member of datastore cluster %q", ds.Reference().Value, pod.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDatastoreClusterConfigBasic() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterConfigSDRSSettings() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name                             = "terraform-datastore-cluster-test"
+  datacenter_id                    = "${data.vsphere_datacenter.dc.id}"
+  sdrs_automation_level            = "automated"
+  sdrs_load_balance_interval       = 60
+  sdrs_space_utilization_threshold = 70
+  sdrs_io_latency_threshold        = 20
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterConfigAltName() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test-renamed"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterConfigWithFolder() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_folder" "datastore_cluster_folder" {
+  path          = "terraform-datastore-cluster-test-folder"
+  type          = "datastore"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  folder        = "${vsphere_folder.datastore_cluster_folder.path}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterConfigWithTags() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "StoragePod",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  tags          = ["${vsphere_tag.terraform-test-tag.id}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDatastoreClusterConfigNasMember() string {
+	return fmt.Sprintf(`
+variable "nfs_host" {
+  type    = "string"
+  default = "%s"
+}
+
+variable "nfs_path" {
+  type    = "string"
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name          = "terraform-datastore-cluster-test"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_nas_datastore" "datastore" {
+  name                 = "terraform-test-nas"
+  host_system_ids      = ["${data.vsphere_host.esxi_host.id}"]
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+
+  type         = "NFS"
+  remote_hosts = ["${var.nfs_host}"]
+  remote_path  = "${var.nfs_path}"
+}
+`, os.Getenv("VSPHERE_NAS_HOST"), os.Getenv("VSPHERE_NFS_PATH"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
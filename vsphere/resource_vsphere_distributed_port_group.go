@@ -0,0 +1,224 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// formatDVPortgroupCreateRollbackError defines the verbose error for
+// configuring a distributed port group on creation where rollback was not
+// possible.
+const formatDVPortgroupCreateRollbackError = `
+WARNING: Dangling resource!
+There was an error completing the configuration of your distributed port
+group:
+%s
+Additionally, there was an error removing the created port group:
+%s
+You will need to remove this port group manually before trying again.
+`
+
+func resourceVSphereDistributedPortGroup() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"distributed_virtual_switch_uuid": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The UUID of the distributed virtual switch to create this port group on.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"key": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The generated UUID of the port group.",
+			Computed:    true,
+		},
+		"config_version": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The current version of the port group configuration, incremented by subsequent updates to the port group.",
+			Computed:    true,
+		},
+	}
+	mergeSchema(s, schemaDVPortgroupConfigSpec())
+
+	// Add tags schema
+	s[vSphereTagAttributeKey] = tagsSchema()
+
+	return &schema.Resource{
+		Create: resourceVSphereDistributedPortGroupCreate,
+		Read:   resourceVSphereDistributedPortGroupRead,
+		Update: resourceVSphereDistributedPortGroupUpdate,
+		Delete: resourceVSphereDistributedPortGroupDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereDistributedPortGroupImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereDistributedPortGroupCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	dvs, err := dvsFromUUID(client, d.Get("distributed_virtual_switch_uuid").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+
+	spec := expandDVPortgroupConfigSpec(d)
+	pg, err := createDVPortgroup(client, dvs, spec)
+	if err != nil {
+		return fmt.Errorf("error creating port group: %s", err)
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pg); err != nil {
+			if remErr := deleteDVPortgroup(pg); remErr != nil {
+				// We could not destroy the created port group and there is now a
+				// dangling resource. We need to instruct the user to remove the port
+				// group manually.
+				return fmt.Errorf(formatDVPortgroupCreateRollbackError, err, remErr)
+			}
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	d.SetId(pg.Reference().Value)
+
+	return resourceVSphereDistributedPortGroupRead(d, meta)
+}
+
+func resourceVSphereDistributedPortGroupRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	pg, err := dvPortgroupFromMOID(client, d.Id())
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate port group: %s", err)
+	}
+	props, err := dvPortgroupProperties(pg)
+	if err != nil {
+		return fmt.Errorf("could not get properties for port group: %s", err)
+	}
+
+	// Look up the switch UUID from the switch reference in the port group's
+	// configuration. We use the port group as the source of truth here so that
+	// we can support import.
+	if props.Config.DistributedVirtualSwitch == nil {
+		return fmt.Errorf("port group %q has no distributed virtual switch", d.Id())
+	}
+	dvs, err := dvsFromMOID(client, props.Config.DistributedVirtualSwitch.Value)
+	if err != nil {
+		return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+	dvsProps, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("could not get properties for distributed virtual switch: %s", err)
+	}
+	d.Set("distributed_virtual_switch_uuid", dvsProps.Uuid)
+
+	if err := flattenDVPortgroupConfigInfo(d, props.Config); err != nil {
+		return err
+	}
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, pg, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereDistributedPortGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	pg, err := dvPortgroupFromMOID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate port group: %s", err)
+	}
+
+	// Apply any pending tags first as it's the lesser expensive of the
+	// operations
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pg); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	// The configuration version needs to be current for the reconfigure to
+	// succeed, so we fetch it here rather than using the one in state, which
+	// may be stale if the port group was changed outside of Terraform.
+	props, err := dvPortgroupProperties(pg)
+	if err != nil {
+		return fmt.Errorf("could not get properties for port group: %s", err)
+	}
+	spec := expandDVPortgroupConfigSpec(d)
+	spec.ConfigVersion = props.Config.ConfigVersion
+	if err := updateDVPortgroup(pg, spec); err != nil {
+		return fmt.Errorf("could not update port group: %s", err)
+	}
+
+	return resourceVSphereDistributedPortGroupRead(d, meta)
+}
+
+func resourceVSphereDistributedPortGroupDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	pg, err := dvPortgroupFromMOID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate port group: %s", err)
+	}
+	if err := deleteDVPortgroup(pg); err != nil {
+		return fmt.Errorf("could not delete port group: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereDistributedPortGroupImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the full path to a specific port group, for which we just
+	// get the MOID for and then pass off to Read.
+	p := d.Id()
+	if !strings.HasPrefix(p, "/") {
+		return nil, errors.New("path must start with a trailing slash")
+	}
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	pg, err := dvPortgroupFromAbsolutePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(pg.Reference().Value)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,483 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestAccResourceVSphereDistributedPortGroup(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereDistributedPortGroupCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "type", "earlyBinding"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"ephemeral",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigEphemeral(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "type", "ephemeral"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"number of ports",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigNumberOfPorts(16),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupHasNumberOfPorts(16),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigNumberOfPorts(32),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupHasNumberOfPorts(32),
+						),
+					},
+				},
+			},
+		},
+		{
+			"VLAN ID",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigVLANID(1000),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupHasVLANID(1000),
+						),
+					},
+				},
+			},
+		},
+		{
+			"VLAN trunk ranges",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigVLANRange(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupHasVLANRange([]types.NumericRange{
+								{Start: 1000, End: 1999},
+								{Start: 3000, End: 3999},
+							}),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigVLANID(1000),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupHasVLANID(1000),
+						),
+					},
+				},
+			},
+		},
+		{
+			"policy overrides",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigPolicy(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "teaming_policy", "failover_explicit"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "active_uplinks.#", "1"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "active_uplinks.0", "tfup1"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "standby_uplinks.#", "1"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "standby_uplinks.0", "tfup2"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "failback", "false"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "allow_promiscuous", "true"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "allow_forged_transmits", "false"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "allow_mac_changes", "false"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "ingress_shaping_enabled", "true"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "ingress_shaping_average_bandwidth", "1000000"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "egress_shaping_enabled", "true"),
+							resource.TestCheckResourceAttr("vsphere_distributed_port_group.pg", "egress_shaping_peak_bandwidth", "2000000"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfigTag(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+							testAccResourceVSphereDistributedPortGroupCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedPortGroupPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedPortGroupExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedPortGroupConfig(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedPortGroupExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_distributed_port_group.pg",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							pg, err := testGetDVPortgroup(s, "pg")
+							if err != nil {
+								return "", err
+							}
+							return pg.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereDistributedPortGroupConfig(),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereDistributedPortGroupCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereDistributedPortGroupPreCheck(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_distributed_port_group acceptance tests")
+	}
+}
+
+func testAccResourceVSphereDistributedPortGroupExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pg, err := testGetDVPortgroup(s, "pg")
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected port group %q to be missing", pg.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedPortGroupHasNumberOfPorts(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVPortgroupProperties(s, "pg")
+		if err != nil {
+			return err
+		}
+		actual := int(props.Config.NumPorts)
+		if expected != actual {
+			return fmt.Errorf("expected number of ports to be %d, got %d", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedPortGroupHasVLANID(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVPortgroupProperties(s, "pg")
+		if err != nil {
+			return err
+		}
+		spec, ok := props.Config.DefaultPortConfig.(*types.VMwareDVSPortSetting).Vlan.(*types.VmwareDistributedVirtualSwitchVlanIdSpec)
+		if !ok {
+			return fmt.Errorf("unexpected VLAN spec type: %T", props.Config.DefaultPortConfig.(*types.VMwareDVSPortSetting).Vlan)
+		}
+		actual := int(spec.VlanId)
+		if expected != actual {
+			return fmt.Errorf("expected VLAN ID to be %d, got %d", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedPortGroupHasVLANRange(expected []types.NumericRange) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVPortgroupProperties(s, "pg")
+		if err != nil {
+			return err
+		}
+		spec, ok := props.Config.DefaultPortConfig.(*types.VMwareDVSPortSetting).Vlan.(*types.VmwareDistributedVirtualSwitchTrunkVlanSpec)
+		if !ok {
+			return fmt.Errorf("unexpected VLAN spec type: %T", props.Config.DefaultPortConfig.(*types.VMwareDVSPortSetting).Vlan)
+		}
+		actual := spec.VlanId
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected VLAN ranges to be %#v, got %#v", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereDistributedPortGroupCheckTags is a check to ensure
+// that any tags that have been created with the supplied resource name have
+// been attached to the port group.
+func testAccResourceVSphereDistributedPortGroupCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pg, err := testGetDVPortgroup(s, "pg")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, pg, tagResName)
+	}
+}
+
+// testAccResourceVSphereDistributedPortGroupConfigBase returns the shared
+// datacenter and switch configuration that the port group tests use.
+func testAccResourceVSphereDistributedPortGroupConfigBase() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  uplinks       = ["tfup1", "tfup2"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+	)
+}
+
+func testAccResourceVSphereDistributedPortGroupConfig() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.uuid}"
+}
+`,
+		testAccResourceVSphereDistributedPortGroupConfigBase(),
+	)
+}
+
+func testAccResourceVSphereDistributedPortGroupConfigEphemeral() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.uuid}"
+  type                            = "ephemeral"
+}
+`,
+		testAccResourceVSphereDistributedPortGroupConfigBase(),
+	)
+}
+
+func testAccResourceVSphereDistributedPortGroupConfigNumberOfPorts(ports int) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.uuid}"
+  number_of_ports                 = %d
+  auto_expand                     = false
+}
+`,
+		testAccResourceVSphereDistributedPortGroupConfigBase(),
+		ports,
+	)
+}
+
+func testAccResourceVSphereDistributedPortGroupConfigVLANID(id int) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.uuid}"
+  vlan_id                         = %d
+}
+`,
+		testAccResourceVSphereDistributedPortGroupConfigBase(),
+		id,
+	)
+}
+
+func testAccResourceVSphereDistributedPortGroupConfigVLANRange() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.uuid}"
+
+  vlan_range {
+    min_vlan = 1000
+    max_vlan = 1999
+  }
+
+  vlan_range {
+    min_vlan = 3000
+    max_vlan = 3999
+  }
+}
+`,
+		testAccResourceVSphereDistributedPortGroupConfigBase(),
+	)
+}
+
+func testAccResourceVSphereDistributedPortGroupConfigPolicy() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.uuid}"
+
+  teaming_policy  = "failover_explicit"
+  active_uplinks  = ["tfup1"]
+  standby_uplinks = ["tfup2"]
+  failback        = false
+
+  allow_promiscuous      = true
+  allow_forged_transmits = false
+  allow_mac_changes      = false
+
+  ingress_shaping_enabled           = true
+  ingress_shaping_average_bandwidth = 1000000
+  ingress_shaping_peak_bandwidth    = 2000000
+  ingress_shaping_burst_size        = 1000000
+
+  egress_shaping_enabled           = true
+  egress_shaping_average_bandwidth = 1000000
+  egress_shaping_peak_bandwidth    = 2000000
+  egress_shaping_burst_size        = 1000000
+}
+`,
+		testAccResourceVSphereDistributedPortGroupConfigBase(),
+	)
+}
+
+func testAccResourceVSphereDistributedPortGroupConfigTag() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "DistributedVirtualPortgroup",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.uuid}"
+  tags                            = ["${vsphere_tag.terraform-test-tag.id}"]
+}
+`,
+		testAccResourceVSphereDistributedPortGroupConfigBase(),
+	)
+}
@@ -0,0 +1,284 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// formatDVSCreateRollbackError defines the verbose error for configuring a
+// distributed virtual switch on creation where rollback was not possible.
+const formatDVSCreateRollbackError = `
+WARNING: Dangling resource!
+There was an error completing the configuration of your distributed virtual
+switch:
+%s
+Additionally, there was an error removing the created switch:
+%s
+You will need to remove this switch manually before trying again.
+`
+
+func resourceVSphereDistributedVirtualSwitch() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The name for the distributed virtual switch.",
+			Required:    true,
+		},
+		"datacenter_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The ID of the datacenter to create this virtual switch in.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"folder": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The folder to create this virtual switch in, relative to the datacenter.",
+			Optional:    true,
+			StateFunc:   normalizeFolderPath,
+		},
+		"version": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The version of this virtual switch. Allowed versions are 6.5.0, 6.0.0, 5.5.0, 5.1.0, and 5.0.0. Changing this to a higher version upgrades the switch.",
+			Optional:    true,
+			Computed:    true,
+		},
+		"uuid": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The UUID of the distributed virtual switch.",
+			Computed:    true,
+		},
+		"config_version": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The version string of the configuration that this spec is trying to change.",
+			Computed:    true,
+		},
+	}
+	mergeSchema(s, schemaVMwareDVSConfigSpec())
+
+	// Add tags schema
+	s[vSphereTagAttributeKey] = tagsSchema()
+
+	return &schema.Resource{
+		Create: resourceVSphereDistributedVirtualSwitchCreate,
+		Read:   resourceVSphereDistributedVirtualSwitchRead,
+		Update: resourceVSphereDistributedVirtualSwitchUpdate,
+		Delete: resourceVSphereDistributedVirtualSwitchDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereDistributedVirtualSwitchImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereDistributedVirtualSwitchCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	dc, err := datacenterFromID(client, d.Get("datacenter_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datacenter: %s", err)
+	}
+	folder, err := networkFolderFromObject(client, dc, d.Get("folder").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate folder: %s", err)
+	}
+
+	spec := expandDVSCreateSpec(d)
+	dvs, err := createDVS(client, folder, spec)
+	if err != nil {
+		return fmt.Errorf("error creating distributed virtual switch: %s", err)
+	}
+
+	// Enable network resource control if it has been requested. This is a
+	// separate operation from the switch configuration.
+	if d.Get("network_resource_control_enabled").(bool) {
+		if err := enableDVSNetworkResourceManagement(client, dvs, true); err != nil {
+			if remErr := deleteDVS(dvs); remErr != nil {
+				// We could not destroy the created switch and there is now a dangling
+				// resource. We need to instruct the user to remove the switch
+				// manually.
+				return fmt.Errorf(formatDVSCreateRollbackError, err, remErr)
+			}
+			return fmt.Errorf("could not enable network resource control: %s", err)
+		}
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, dvs); err != nil {
+			if remErr := deleteDVS(dvs); remErr != nil {
+				return fmt.Errorf(formatDVSCreateRollbackError, err, remErr)
+			}
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	d.SetId(dvs.Reference().Value)
+
+	return resourceVSphereDistributedVirtualSwitchRead(d, meta)
+}
+
+func resourceVSphereDistributedVirtualSwitchRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	dvs, err := dvsFromMOID(client, d.Id())
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("could not get properties for distributed virtual switch: %s", err)
+	}
+
+	// Discover the datacenter and relative folder from the inventory path. We
+	// use the switch as the source of truth here so that we can support import.
+	dcp, err := rootPathParticleNetwork.SplitDatacenter(dvs.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing datacenter from switch path %q: %s", dvs.InventoryPath, err)
+	}
+	dc, err := getDatacenter(client, dcp)
+	if err != nil {
+		return fmt.Errorf("cannot find datacenter from path %q: %s", dcp, err)
+	}
+	folder, err := rootPathParticleNetwork.SplitRelativeFolder(dvs.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing switch path %q: %s", dvs.InventoryPath, err)
+	}
+	d.Set("datacenter_id", dc.Reference().Value)
+	d.Set("folder", normalizeFolderPath(folder))
+	d.Set("uuid", props.Uuid)
+
+	config, ok := props.Config.(*types.VMwareDVSConfigInfo)
+	if !ok {
+		return fmt.Errorf("unexpected configuration type for distributed virtual switch: %T", props.Config)
+	}
+	if err := flattenVMwareDVSConfigInfo(d, config); err != nil {
+		return err
+	}
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, dvs, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereDistributedVirtualSwitchUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	dvs, err := dvsFromMOID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+
+	// Apply any pending tags first as it's the lesser expensive of the
+	// operations
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, dvs); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	// Upgrade the switch first, if necessary. This needs to happen before
+	// reconfiguration as some settings may depend on the newer version.
+	if d.HasChange("version") {
+		if err := upgradeDVS(client, dvs, d.Get("version").(string)); err != nil {
+			return fmt.Errorf("could not upgrade switch: %s", err)
+		}
+	}
+
+	// Update folder if necessary
+	if d.HasChange("folder") {
+		folder := d.Get("folder").(string)
+		if err := moveDVSToFolder(client, dvs, folder); err != nil {
+			return fmt.Errorf("could not move switch to folder %q: %s", folder, err)
+		}
+	}
+
+	if d.HasChange("network_resource_control_enabled") {
+		if err := enableDVSNetworkResourceManagement(client, dvs, d.Get("network_resource_control_enabled").(bool)); err != nil {
+			return fmt.Errorf("could not change network resource control state: %s", err)
+		}
+	}
+
+	// The configuration version needs to be current for the reconfigure to
+	// succeed, so we fetch it again here as the operations above will have
+	// changed it.
+	props, err := dvsProperties(dvs)
+	if err != nil {
+		return fmt.Errorf("could not get properties for distributed virtual switch: %s", err)
+	}
+	spec := expandVMwareDVSConfigSpec(d)
+	spec.ConfigVersion = props.Config.GetDVSConfigInfo().ConfigVersion
+	if err := updateDVSConfiguration(dvs, spec); err != nil {
+		return fmt.Errorf("could not update distributed virtual switch: %s", err)
+	}
+
+	return resourceVSphereDistributedVirtualSwitchRead(d, meta)
+}
+
+func resourceVSphereDistributedVirtualSwitchDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	dvs, err := dvsFromMOID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate distributed virtual switch: %s", err)
+	}
+	if err := deleteDVS(dvs); err != nil {
+		return fmt.Errorf("could not delete distributed virtual switch: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereDistributedVirtualSwitchImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the full path to a specific switch, for which we just get
+	// the MOID for and then pass off to Read.
+	p := d.Id()
+	if !strings.HasPrefix(p, "/") {
+		return nil, errors.New("path must start with a trailing slash")
+	}
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return nil, err
+	}
+	dvs, err := dvsFromAbsolutePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(dvs.Reference().Value)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,630 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName = "terraform-test-dvs"
+const testAccResourceVSphereDistributedVirtualSwitchConfigExpectedAltName = "terraform-test-dvs-renamed"
+
+func TestAccResourceVSphereDistributedVirtualSwitch(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereDistributedVirtualSwitchCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfig(
+							testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasName(testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName),
+						),
+					},
+				},
+			},
+		},
+		{
+			"rename",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfig(
+							testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasName(testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfig(
+							testAccResourceVSphereDistributedVirtualSwitchConfigExpectedAltName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasName(testAccResourceVSphereDistributedVirtualSwitchConfigExpectedAltName),
+						),
+					},
+				},
+			},
+		},
+		{
+			"add host",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfig(
+							testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHostCount(0),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigSingleHost(
+							os.Getenv("VSPHERE_HOST_NIC0"),
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHostCount(1),
+							testAccResourceVSphereDistributedVirtualSwitchHasDevices([]string{os.Getenv("VSPHERE_HOST_NIC0")}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"modify host devices",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigSingleHost(
+							os.Getenv("VSPHERE_HOST_NIC0"),
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasDevices([]string{os.Getenv("VSPHERE_HOST_NIC0")}),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigSingleHost(
+							os.Getenv("VSPHERE_HOST_NIC1"),
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasDevices([]string{os.Getenv("VSPHERE_HOST_NIC1")}),
+						),
+					},
+				},
+			},
+		},
+		{
+			"remove host",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigSingleHost(
+							os.Getenv("VSPHERE_HOST_NIC0"),
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHostCount(1),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfig(
+							testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHostCount(0),
+						),
+					},
+				},
+			},
+		},
+		{
+			"uplinks, MTU, LACP, and link discovery",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigSettings(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasUplinks([]string{"tfup1", "tfup2"}),
+							resource.TestCheckResourceAttr("vsphere_distributed_virtual_switch.dvs", "max_mtu", "9000"),
+							resource.TestCheckResourceAttr("vsphere_distributed_virtual_switch.dvs", "lacp_enabled", "true"),
+							resource.TestCheckResourceAttr("vsphere_distributed_virtual_switch.dvs", "lacp_mode", "active"),
+							resource.TestCheckResourceAttr("vsphere_distributed_virtual_switch.dvs", "link_discovery_protocol", "lldp"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"network resource control",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfig(
+							testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchNetworkResourceControl(false),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigNIOC(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchNetworkResourceControl(true),
+						),
+					},
+				},
+			},
+		},
+		{
+			"upgrade version",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigVersion("6.0.0"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasVersion("6.0.0"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigVersion("6.5.0"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchHasVersion("6.5.0"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigTag(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+							testAccResourceVSphereDistributedVirtualSwitchCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereDistributedVirtualSwitchPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereDistributedVirtualSwitchExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigSingleHost(
+							os.Getenv("VSPHERE_HOST_NIC0"),
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereDistributedVirtualSwitchExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_distributed_virtual_switch.dvs",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							dvs, err := testGetDVS(s, "dvs")
+							if err != nil {
+								return "", err
+							}
+							return dvs.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereDistributedVirtualSwitchConfigSingleHost(
+							os.Getenv("VSPHERE_HOST_NIC0"),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereDistributedVirtualSwitchCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchPreCheck(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_distributed_virtual_switch acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_distributed_virtual_switch acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
+		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_distributed_virtual_switch acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC1") == "" {
+		t.Skip("set VSPHERE_HOST_NIC1 to run vsphere_distributed_virtual_switch acceptance tests")
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		dvs, err := testGetDVS(s, "dvs")
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected DVS %q to be missing", dvs.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchHasName(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		actual := props.Name
+		if expected != actual {
+			return fmt.Errorf("expected name to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchHasVersion(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		actual := props.Summary.ProductInfo.Version
+		if expected != actual {
+			return fmt.Errorf("expected version to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchHostCount(expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		actual := len(props.Config.GetDVSConfigInfo().Host)
+		if expected != actual {
+			return fmt.Errorf("expected host count to be %d, got %d", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchHasDevices(expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		hosts := props.Config.GetDVSConfigInfo().Host
+		if len(hosts) < 1 {
+			return fmt.Errorf("no hosts found in DVS")
+		}
+		var actual []string
+		for _, spec := range hosts[0].Config.Backing.(*types.DistributedVirtualSwitchHostMemberPnicBacking).PnicSpec {
+			actual = append(actual, spec.PnicDevice)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected devices to be %#v, got %#v", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchHasUplinks(expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		policy := props.Config.GetDVSConfigInfo().UplinkPortPolicy.(*types.DVSNameArrayUplinkPortPolicy)
+		actual := policy.UplinkPortName
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected uplinks to be %#v, got %#v", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchNetworkResourceControl(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetDVSProperties(s, "dvs")
+		if err != nil {
+			return err
+		}
+		actual := props.Config.GetDVSConfigInfo().NetworkResourceManagementEnabled
+		if actual == nil || *actual != expected {
+			return fmt.Errorf("expected network resource control enabled to be %t", expected)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereDistributedVirtualSwitchCheckTags is a check to
+// ensure that any tags that have been created with the supplied resource name
+// have been attached to the DVS.
+func testAccResourceVSphereDistributedVirtualSwitchCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		dvs, err := testGetDVS(s, "dvs")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, dvs, tagResName)
+	}
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchConfig(name string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "dvs_name" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "${var.dvs_name}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		name,
+	)
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchConfigSingleHost(nic string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "network_interface" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  host {
+    host_system_id = "${data.vsphere_host.host.id}"
+    devices        = ["${var.network_interface}"]
+  }
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		nic,
+		testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+	)
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchConfigSettings() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  uplinks = ["tfup1", "tfup2"]
+  max_mtu = 9000
+
+  lacp_api_version = "singleLag"
+  lacp_enabled     = true
+  lacp_mode        = "active"
+
+  link_discovery_operation = "both"
+  link_discovery_protocol  = "lldp"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+	)
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchConfigNIOC() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  network_resource_control_enabled = true
+  network_resource_control_version = "version3"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+	)
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchConfigVersion(version string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  version       = "%s"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+		version,
+	)
+}
+
+func testAccResourceVSphereDistributedVirtualSwitchConfigTag() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "VmwareDistributedVirtualSwitch",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+  tags          = ["${vsphere_tag.terraform-test-tag.id}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		testAccResourceVSphereDistributedVirtualSwitchConfigExpectedName,
+	)
+}
@@ -27,10 +27,17 @@ func resourceVSphereNasDatastore() *schema.Resource {
 			Required:    true,
 		},
 		"folder": &schema.Schema{
-			Type:        schema.TypeString,
-			Description: "The path to the datastore folder to put the datastore in.",
-			Optional:    true,
-			StateFunc:   normalizeFolderPath,
+			Type:          schema.TypeString,
+			Description:   "The path to the datastore folder to put the datastore in.",
+			Optional:      true,
+			StateFunc:     normalizeFolderPath,
+			ConflictsWith: []string{"datastore_cluster_id"},
+		},
+		"datastore_cluster_id": &schema.Schema{
+			Type:          schema.TypeString,
+			Description:   "The managed object ID of the datastore cluster to place the datastore in.",
+			Optional:      true,
+			ConflictsWith: []string{"folder"},
 		},
 	}
 	mergeSchema(s, schemaHostNasVolumeSpec())
@@ -84,6 +91,13 @@ func resourceVSphereNasDatastoreCreate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
+	// Move the datastore into the datastore cluster, if specified.
+	if podID := d.Get("datastore_cluster_id").(string); podID != "" {
+		if err := moveDatastoreToStoragePod(client, ds, podID); err != nil {
+			return fmt.Errorf("error moving datastore to datastore cluster: %s", err)
+		}
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, ds); err != nil {
@@ -110,12 +124,20 @@ func resourceVSphereNasDatastoreRead(d *schema.ResourceData, meta interface{}) e
 		return err
 	}
 
-	// Set the folder
-	folder, err := rootPathParticleDatastore.SplitRelativeFolder(ds.InventoryPath)
-	if err != nil {
-		return fmt.Errorf("error parsing datastore path %q: %s", ds.InventoryPath, err)
+	// Set the folder or datastore cluster. A datastore that is a member of a
+	// datastore cluster has the cluster as its parent, and the folder is left
+	// blank as the two options conflict.
+	if props.Parent != nil && props.Parent.Type == "StoragePod" {
+		d.Set("datastore_cluster_id", props.Parent.Value)
+		d.Set("folder", "")
+	} else {
+		folder, err := rootPathParticleDatastore.SplitRelativeFolder(ds.InventoryPath)
+		if err != nil {
+			return fmt.Errorf("error parsing datastore path %q: %s", ds.InventoryPath, err)
+		}
+		d.Set("datastore_cluster_id", "")
+		d.Set("folder", normalizeFolderPath(folder))
 	}
-	d.Set("folder", normalizeFolderPath(folder))
 
 	// Update NAS spec
 	if err := flattenHostNasVolume(d, props.Info.(*types.NasDatastoreInfo).Nas); err != nil {
@@ -164,11 +186,17 @@ func resourceVSphereNasDatastoreUpdate(d *schema.ResourceData, meta interface{})
 		}
 	}
 
-	// Update folder if necessary
-	if d.HasChange("folder") {
-		folder := d.Get("folder").(string)
-		if err := moveDatastoreToFolder(client, ds, folder); err != nil {
-			return fmt.Errorf("could not move datastore to folder %q: %s", folder, err)
+	// Update folder or datastore cluster if necessary
+	if d.HasChange("folder") || d.HasChange("datastore_cluster_id") {
+		if podID := d.Get("datastore_cluster_id").(string); podID != "" {
+			if err := moveDatastoreToStoragePod(client, ds, podID); err != nil {
+				return fmt.Errorf("could not move datastore to datastore cluster %q: %s", podID, err)
+			}
+		} else {
+			folder := d.Get("folder").(string)
+			if err := moveDatastoreToFolder(client, ds, folder); err != nil {
+				return fmt.Errorf("could not move datastore to folder %q: %s", folder, err)
+			}
 		}
 	}
 
@@ -0,0 +1,308 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var ovfDiskProvisioningAllowedValues = []string{
+	string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThin),
+	string(types.OvfCreateImportSpecParamsDiskProvisioningTypeThick),
+	string(types.OvfCreateImportSpecParamsDiskProvisioningTypeEagerZeroedThick),
+	string(types.OvfCreateImportSpecParamsDiskProvisioningTypeSeSparse),
+	string(types.OvfCreateImportSpecParamsDiskProvisioningTypeSparse),
+	string(types.OvfCreateImportSpecParamsDiskProvisioningTypeFlat),
+}
+
+// formatOvfVirtualMachineCreateRollbackErrorProperties defines the verbose
+// error for fetching the properties of a deployed virtual machine on creation
+// where rollback was not possible.
+const formatOvfVirtualMachineCreateRollbackErrorProperties = `
+WARNING: Dangling resource!
+After deploying the virtual machine %q (managed object ID %s), there was an
+error fetching its properties:
+%s
+Additionally, there was an error removing the deployed virtual machine:
+%s
+You will need to remove this virtual machine manually before trying again.
+`
+
+func resourceVSphereOvfVirtualMachine() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereOvfVirtualMachineCreate,
+		Read:   resourceVSphereOvfVirtualMachineRead,
+		Update: resourceVSphereOvfVirtualMachineUpdate,
+		Delete: resourceVSphereOvfVirtualMachineDelete,
+
+		Schema: map[string]*schema.Schema{
+			"name": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The name of the virtual machine.",
+				Required:    true,
+			},
+			"ovf_path": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The path to a local .ovf or .ova file to deploy the virtual machine from.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"resource_pool_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the resource pool to put the virtual machine in.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"datastore_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the datastore to put the virtual machine in.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"host_system_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to put the virtual machine on.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"folder": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The path to the VM folder to put the virtual machine in.",
+				Optional:    true,
+				StateFunc:   normalizeFolderPath,
+			},
+			"disk_provisioning": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "The provisioning type of the disks of the virtual machine. Defaults to the type in the OVF package.",
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(ovfDiskProvisioningAllowedValues, false),
+			},
+			"deployment_option": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The key of the deployment option to use, for OVF packages that offer more than one configuration.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"network_mapping": &schema.Schema{
+				Type:        schema.TypeMap,
+				Description: "A map of the network names in the OVF package to the managed object IDs of the networks to connect them to.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"properties": &schema.Schema{
+				Type:        schema.TypeMap,
+				Description: "A map of OVF property keys to the values to set them to.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"power_on": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Power on the virtual machine once it is deployed, and keep it powered on.",
+				Optional:    true,
+				Default:     true,
+			},
+			"upload_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Description:  "The time, in minutes, to wait for the files of the OVF package to upload.",
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"moid": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the virtual machine.",
+				Computed:    true,
+			},
+			"power_state": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The power state of the virtual machine.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceVSphereOvfVirtualMachineCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	if err := validateVirtualCenter(client); err != nil {
+		return err
+	}
+	rp, err := resourcePoolFromID(client, d.Get("resource_pool_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate resource pool: %s", err)
+	}
+	ds, err := datastoreFromID(client, d.Get("datastore_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate datastore: %s", err)
+	}
+	folder, err := vmFolderFromObject(client, ds, d.Get("folder").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate folder: %s", err)
+	}
+	var host *object.HostSystem
+	if v, ok := d.GetOk("host_system_id"); ok {
+		host, err = hostSystemFromID(client, v.(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate host: %s", err)
+		}
+	}
+	cisp, err := expandOvfCreateImportSpecParams(client, d, host)
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(d.Get("upload_timeout").(int)) * time.Minute
+	log.Printf("[DEBUG] Deploying %q from OVF package %q", cisp.EntityName, d.Get("ovf_path").(string))
+	vm, err := deployOVF(client, d.Get("ovf_path").(string), rp, ds, folder, host, cisp, timeout)
+	if err != nil {
+		return fmt.Errorf("error deploying OVF package: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		if remErr := destroyVirtualMachine(vm); remErr != nil {
+			// We could not destroy the deployed virtual machine and there is now a
+			// dangling resource. We need to instruct the user to remove the
+			// virtual machine manually.
+			return fmt.Errorf(formatOvfVirtualMachineCreateRollbackErrorProperties, cisp.EntityName, vm.Reference().Value, err, remErr)
+		}
+		return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+	}
+	d.SetId(props.Config.Uuid)
+
+	if d.Get("power_on").(bool) {
+		if err := powerOnVirtualMachine(vm); err != nil {
+			return fmt.Errorf("error powering on virtual machine: %s", err)
+		}
+	}
+	return resourceVSphereOvfVirtualMachineRead(d, meta)
+}
+
+func resourceVSphereOvfVirtualMachineRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualMachineFromUUID(client, d.Id())
+	if err != nil {
+		if isVirtualMachineNotFoundError(err) {
+			log.Printf("[DEBUG] Virtual machine %q is gone: %s", d.Id(), err)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+	}
+	folder, err := rootPathParticleVM.SplitRelativeFolder(vm.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing virtual machine path %q: %s", vm.InventoryPath, err)
+	}
+	d.Set("name", props.Name)
+	d.Set("folder", normalizeFolderPath(folder))
+	d.Set("moid", vm.Reference().Value)
+	d.Set("power_state", string(props.Runtime.PowerState))
+	return nil
+}
+
+func resourceVSphereOvfVirtualMachineUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualMachineFromUUID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	if d.HasChange("name") {
+		if err := renameObject(client, vm.Reference(), d.Get("name").(string)); err != nil {
+			return fmt.Errorf("error renaming virtual machine: %s", err)
+		}
+	}
+	if d.HasChange("folder") {
+		if err := moveVirtualMachineToFolder(client, vm, d.Get("folder").(string)); err != nil {
+			return fmt.Errorf("error moving virtual machine to folder: %s", err)
+		}
+	}
+	if d.HasChange("power_on") {
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+		}
+		on := props.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn
+		switch {
+		case d.Get("power_on").(bool) && !on:
+			if err := powerOnVirtualMachine(vm); err != nil {
+				return fmt.Errorf("error powering on virtual machine: %s", err)
+			}
+		case !d.Get("power_on").(bool) && on:
+			if err := powerOffVirtualMachine(vm); err != nil {
+				return fmt.Errorf("error powering off virtual machine: %s", err)
+			}
+		}
+	}
+	return resourceVSphereOvfVirtualMachineRead(d, meta)
+}
+
+func resourceVSphereOvfVirtualMachineDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualMachineFromUUID(client, d.Id())
+	if err != nil {
+		if isVirtualMachineNotFoundError(err) {
+			log.Printf("[DEBUG] Virtual machine %q is already gone: %s", d.Id(), err)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+	}
+	if props.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		if err := powerOffVirtualMachine(vm); err != nil {
+			return fmt.Errorf("error powering off virtual machine: %s", err)
+		}
+	}
+	if err := destroyVirtualMachine(vm); err != nil {
+		return fmt.Errorf("error destroying virtual machine: %s", err)
+	}
+	d.SetId("")
+	return nil
+}
+
+// expandOvfCreateImportSpecParams reads the deployment settings of a
+// vsphere_ovf_virtual_machine resource into an OvfCreateImportSpecParams.
+// Networks in network_mapping are looked up by their managed object IDs.
+func expandOvfCreateImportSpecParams(client *govmomi.Client, d *schema.ResourceData, host *object.HostSystem) (types.OvfCreateImportSpecParams, error) {
+	cisp := types.OvfCreateImportSpecParams{
+		OvfManagerCommonParams: types.OvfManagerCommonParams{
+			DeploymentOption: d.Get("deployment_option").(string),
+		},
+		EntityName:       d.Get("name").(string),
+		DiskProvisioning: d.Get("disk_provisioning").(string),
+	}
+	if host != nil {
+		ref := host.Reference()
+		cisp.HostSystem = &ref
+	}
+	for name, id := range d.Get("network_mapping").(map[string]interface{}) {
+		net, err := networkFromID(client, id.(string))
+		if err != nil {
+			return cisp, fmt.Errorf("cannot locate network %q for OVF network %q: %s", id, name, err)
+		}
+		cisp.NetworkMapping = append(cisp.NetworkMapping, types.OvfNetworkMapping{
+			Name:    name,
+			Network: net.Reference(),
+		})
+	}
+	for k, v := range d.Get("properties").(map[string]interface{}) {
+		cisp.PropertyMapping = append(cisp.PropertyMapping, types.KeyValue{
+			Key:   k,
+			Value: v.(string),
+		})
+	}
+	return cisp, nil
+}
@@ -0,0 +1,123 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereOvfVirtualMachine_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereOvfVirtualMachinePreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccResourceVSphereOvfVirtualMachineExists(false),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereOvfVirtualMachineConfig("terraform-test-ovf", true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereOvfVirtualMachineExists(true),
+					resource.TestCheckResourceAttr("vsphere_ovf_virtual_machine.vm", "power_state", "poweredOn"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereOvfVirtualMachineConfig("terraform-test-ovf-renamed", false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccResourceVSphereOvfVirtualMachineExists(true),
+					resource.TestCheckResourceAttr("vsphere_ovf_virtual_machine.vm", "name", "terraform-test-ovf-renamed"),
+					resource.TestCheckResourceAttr("vsphere_ovf_virtual_machine.vm", "power_state", "poweredOff"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereOvfVirtualMachinePreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_OVF_PATH") == "" {
+		t.Skip("set VSPHERE_OVF_PATH to run vsphere_ovf_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_OVF_NETWORK") == "" {
+		t.Skip("set VSPHERE_OVF_NETWORK to run vsphere_ovf_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_RESOURCE_POOL_ID") == "" {
+		t.Skip("set VSPHERE_RESOURCE_POOL_ID to run vsphere_ovf_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_DATASTORE_ID") == "" {
+		t.Skip("set VSPHERE_DATASTORE_ID to run vsphere_ovf_virtual_machine acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NETWORK_ID") == "" {
+		t.Skip("set VSPHERE_NETWORK_ID to run vsphere_ovf_virtual_machine acceptance tests")
+	}
+}
+
+func testAccResourceVSphereOvfVirtualMachineExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["vsphere_ovf_virtual_machine.vm"]
+		if !ok {
+			if expected {
+				return errors.New("vsphere_ovf_virtual_machine.vm not found in state")
+			}
+			return nil
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		_, err := virtualMachineFromUUID(client, rs.Primary.ID)
+		if err != nil {
+			if isVirtualMachineNotFoundError(err) && !expected {
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("virtual machine %q still exists", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereOvfVirtualMachineConfig(name string, powerOn bool) string {
+	return fmt.Sprintf(`
+variable "ovf_path" {
+  default = "%s"
+}
+
+variable "ovf_network" {
+  default = "%s"
+}
+
+variable "resource_pool_id" {
+  default = "%s"
+}
+
+variable "datastore_id" {
+  default = "%s"
+}
+
+variable "network_id" {
+  default = "%s"
+}
+
+resource "vsphere_ovf_virtual_machine" "vm" {
+  name              = "%s"
+  ovf_path          = "${var.ovf_path}"
+  resource_pool_id  = "${var.resource_pool_id}"
+  datastore_id      = "${var.datastore_id}"
+  disk_provisioning = "thin"
+  power_on          = %t
+  network_mapping   = "${map(var.ovf_network, var.network_id)}"
+}
+`,
+		os.Getenv("VSPHERE_OVF_PATH"),
+		os.Getenv("VSPHERE_OVF_NETWORK"),
+		os.Getenv("VSPHERE_RESOURCE_POOL_ID"),
+		os.Getenv("VSPHERE_DATASTORE_ID"),
+		os.Getenv("VSPHERE_NETWORK_ID"),
+		name,
+		powerOn,
+	)
+}
@@ -0,0 +1,207 @@
+package vsphere
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// formatResourcePoolCreateRollbackErrorUpdate defines the verbose error for
+// tagging a resource pool on creation where rollback was not possible.
+const formatResourcePoolCreateRollbackErrorUpdate = `
+WARNING: Dangling resource!
+There was an error applying tags to your resource pool:
+%s
+Additionally, there was an error removing the created resource pool:
+%s
+You will need to remove this resource pool manually before trying again.
+`
+
+func resourceVSphereResourcePool() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The name of the resource pool.",
+			Required:    true,
+		},
+		"parent_resource_pool_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the parent resource pool. This can be the root resource pool of a cluster or standalone host, or another resource pool.",
+			Required:    true,
+		},
+	}
+	mergeSchema(s, schemaResourceConfigSpec())
+
+	// Add tags schema
+	s[vSphereTagAttributeKey] = tagsSchema()
+
+	return &schema.Resource{
+		Create: resourceVSphereResourcePoolCreate,
+		Read:   resourceVSphereResourcePoolRead,
+		Update: resourceVSphereResourcePoolUpdate,
+		Delete: resourceVSphereResourcePoolDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereResourcePoolImport,
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereResourcePoolCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	parent, err := resourcePoolFromID(client, d.Get("parent_resource_pool_id").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate parent resource pool: %s", err)
+	}
+	spec := expandResourceConfigSpec(d)
+	pool, err := createResourcePool(parent, d.Get("name").(string), *spec)
+	if err != nil {
+		return fmt.Errorf("error creating resource pool: %s", err)
+	}
+
+	// Apply any pending tags now
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pool); err != nil {
+			if remErr := deleteResourcePool(pool); remErr != nil {
+				// We could not destroy the created resource pool and there is now a
+				// dangling resource. We need to instruct the user to remove the
+				// resource pool manually.
+				return fmt.Errorf(formatResourcePoolCreateRollbackErrorUpdate, err, remErr)
+			}
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	d.SetId(pool.Reference().Value)
+
+	return resourceVSphereResourcePoolRead(d, meta)
+}
+
+func resourceVSphereResourcePoolRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	pool, err := resourcePoolFromID(client, d.Id())
+	if err != nil {
+		if isManagedObjectNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate resource pool: %s", err)
+	}
+	props, err := resourcePoolProperties(pool)
+	if err != nil {
+		return fmt.Errorf("could not get properties for resource pool: %s", err)
+	}
+
+	d.Set("name", props.Name)
+	if props.Parent != nil {
+		d.Set("parent_resource_pool_id", props.Parent.Value)
+	}
+	if err := flattenResourceConfigSpec(d, props.Config); err != nil {
+		return err
+	}
+
+	// Read tags if we have the ability to do so
+	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
+		if err := readTagsForResource(tagsClient, pool, d); err != nil {
+			return fmt.Errorf("error reading tags: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceVSphereResourcePoolUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+
+	// Load up the tags client, which will validate a proper vCenter before
+	// attempting to proceed if we have tags defined.
+	tagsClient, err := tagsClientIfDefined(d, meta)
+	if err != nil {
+		return err
+	}
+
+	pool, err := resourcePoolFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate resource pool: %s", err)
+	}
+
+	// Apply any pending tags first as it's the lesser expensive of the
+	// operations
+	if tagsClient != nil {
+		if err := processTagDiff(tagsClient, d, pool); err != nil {
+			return fmt.Errorf("error updating tags: %s", err)
+		}
+	}
+
+	// Re-parent the resource pool if the parent has changed.
+	if d.HasChange("parent_resource_pool_id") {
+		parent, err := resourcePoolFromID(client, d.Get("parent_resource_pool_id").(string))
+		if err != nil {
+			return fmt.Errorf("cannot locate parent resource pool: %s", err)
+		}
+		if err := moveResourcePool(pool, parent); err != nil {
+			return fmt.Errorf("could not move resource pool to parent %q: %s", parent.Reference().Value, err)
+		}
+	}
+
+	var name string
+	if d.HasChange("name") {
+		name = d.Get("name").(string)
+	}
+	spec := expandResourceConfigSpec(d)
+	if err := updateResourcePool(pool, name, spec); err != nil {
+		return fmt.Errorf("error updating resource pool: %s", err)
+	}
+
+	return resourceVSphereResourcePoolRead(d, meta)
+}
+
+func resourceVSphereResourcePoolDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	pool, err := resourcePoolFromID(client, d.Id())
+	if err != nil {
+		return fmt.Errorf("cannot locate resource pool: %s", err)
+	}
+	props, err := resourcePoolProperties(pool)
+	if err != nil {
+		return fmt.Errorf("could not get properties for resource pool: %s", err)
+	}
+
+	// Refuse to delete a resource pool that still has child pools or virtual
+	// machines in it, which would otherwise be silently moved to the parent.
+	if len(props.ResourcePool) > 0 || len(props.Vm) > 0 {
+		return errors.New("resource pool is not empty, please remove all items before deleting")
+	}
+
+	if err := deleteResourcePool(pool); err != nil {
+		return fmt.Errorf("error deleting resource pool: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereResourcePoolImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the full path to a specific resource pool, for which we
+	// just get the MOID for and then pass off to Read.
+	p := d.Id()
+	if !strings.HasPrefix(p, "/") {
+		return nil, errors.New("path must start with a trailing slash")
+	}
+	client := meta.(*VSphereClient).vimClient
+	pool, err := resourcePoolFromAbsolutePath(client, p)
+	if err != nil {
+		return nil, err
+	}
+	d.SetId(pool.Reference().Value)
+	return []*schema.ResourceData{d}, nil
+}
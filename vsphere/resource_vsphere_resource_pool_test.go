@@ -0,0 +1,486 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const testAccResourceVSphereResourcePoolConfigExpectedName = "terraform-resource-pool-test"
+const testAccResourceVSphereResourcePoolConfigExpectedAltName = "terraform-resource-pool-test-renamed"
+const testAccResourceVSphereResourcePoolConfigExpectedParentName = "terraform-resource-pool-test-parent"
+
+func TestAccResourceVSphereResourcePool(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereResourcePoolCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"basic",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereResourcePoolPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigBasic(
+							testAccResourceVSphereResourcePoolConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolHasName(testAccResourceVSphereResourcePoolConfigExpectedName),
+							testAccResourceVSphereResourcePoolHasCPUShareLevel(types.SharesLevelNormal),
+						),
+					},
+				},
+			},
+		},
+		{
+			"custom allocation",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereResourcePoolPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigCustomAllocation(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolHasCPUShareLevel(types.SharesLevelCustom),
+							resource.TestCheckResourceAttr("vsphere_resource_pool.resource_pool", "cpu_shares", "1234"),
+							resource.TestCheckResourceAttr("vsphere_resource_pool.resource_pool", "memory_reservation", "128"),
+							resource.TestCheckResourceAttr("vsphere_resource_pool.resource_pool", "memory_limit", "1024"),
+							resource.TestCheckResourceAttr("vsphere_resource_pool.resource_pool", "memory_expandable", "false"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"update allocation",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereResourcePoolPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigBasic(
+							testAccResourceVSphereResourcePoolConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolHasCPUShareLevel(types.SharesLevelNormal),
+						),
+					},
+					{
+						Config: testAccResourceVSphereResourcePoolConfigCustomAllocation(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolHasCPUShareLevel(types.SharesLevelCustom),
+							resource.TestCheckResourceAttr("vsphere_resource_pool.resource_pool", "cpu_shares", "1234"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"rename",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereResourcePoolPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigBasic(
+							testAccResourceVSphereResourcePoolConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolHasName(testAccResourceVSphereResourcePoolConfigExpectedName),
+						),
+					},
+					{
+						Config: testAccResourceVSphereResourcePoolConfigBasic(
+							testAccResourceVSphereResourcePoolConfigExpectedAltName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolHasName(testAccResourceVSphereResourcePoolConfigExpectedAltName),
+						),
+					},
+				},
+			},
+		},
+		{
+			"move to child pool",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereResourcePoolPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigBasic(
+							testAccResourceVSphereResourcePoolConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereResourcePoolConfigChild(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolHasParentName(testAccResourceVSphereResourcePoolConfigExpectedParentName),
+						),
+					},
+				},
+			},
+		},
+		{
+			"tags",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereResourcePoolPreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigTag(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+							testAccResourceVSphereResourcePoolCheckTags("terraform-test-tag"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereResourcePoolPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereResourcePoolExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereResourcePoolConfigBasic(
+							testAccResourceVSphereResourcePoolConfigExpectedName,
+						),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereResourcePoolExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_resource_pool.resource_pool",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							pool, err := testGetResourcePool(s, "resource_pool")
+							if err != nil {
+								return "", err
+							}
+							return pool.InventoryPath, nil
+						},
+						Config: testAccResourceVSphereResourcePoolConfigBasic(
+							testAccResourceVSphereResourcePoolConfigExpectedName,
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereResourcePoolCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereResourcePoolPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_DATACENTER") == "" {
+		t.Skip("set VSPHERE_DATACENTER to run vsphere_resource_pool acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_resource_pool acceptance tests")
+	}
+}
+
+func testAccResourceVSphereResourcePoolExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetResourcePool(s, "resource_pool")
+		if err != nil {
+			if isManagedObjectNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if !expected {
+			return fmt.Errorf("expected resource pool %q to be missing", pool.Reference().Value)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereResourcePoolHasName(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetResourcePoolProperties(s, "resource_pool")
+		if err != nil {
+			return err
+		}
+		actual := props.Name
+		if expected != actual {
+			return fmt.Errorf("expected name to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereResourcePoolHasCPUShareLevel(expected types.SharesLevel) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetResourcePoolProperties(s, "resource_pool")
+		if err != nil {
+			return err
+		}
+		actual := props.Config.CpuAllocation.GetResourceAllocationInfo().Shares.Level
+		if expected != actual {
+			return fmt.Errorf("expected CPU share level to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereResourcePoolHasParentName(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetResourcePoolProperties(s, "resource_pool")
+		if err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		parent, err := resourcePoolFromID(client, props.Parent.Value)
+		if err != nil {
+			return err
+		}
+		pprops, err := resourcePoolProperties(parent)
+		if err != nil {
+			return err
+		}
+		actual := pprops.Name
+		if expected != actual {
+			return fmt.Errorf("expected parent resource pool name to be %q, got %q", expected, actual)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereResourcePoolCheckTags is a check to ensure that any
+// tags that have been created with the supplied resource name have been
+// attached to the resource pool.
+func testAccResourceVSphereResourcePoolCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		pool, err := testGetResourcePool(s, "resource_pool")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, pool, tagResName)
+	}
+}
+
+func testAccResourceVSphereResourcePoolConfigBasic(name string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "resource_pool_name" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "${var.resource_pool_name}"
+  parent_resource_pool_id = "${data.vsphere_host.esxi_host.resource_pool_id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		name,
+	)
+}
+
+func testAccResourceVSphereResourcePoolConfigCustomAllocation() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "resource_pool_name" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "${var.resource_pool_name}"
+  parent_resource_pool_id = "${data.vsphere_host.esxi_host.resource_pool_id}"
+
+  cpu_share_level = "custom"
+  cpu_shares      = 1234
+
+  memory_reservation = 128
+  memory_limit       = 1024
+  memory_expandable  = false
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		testAccResourceVSphereResourcePoolConfigExpectedName,
+	)
+}
+
+func testAccResourceVSphereResourcePoolConfigChild() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "resource_pool_name" {
+  default = "%s"
+}
+
+variable "parent_name" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_resource_pool" "parent" {
+  name                    = "${var.parent_name}"
+  parent_resource_pool_id = "${data.vsphere_host.esxi_host.resource_pool_id}"
+}
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "${var.resource_pool_name}"
+  parent_resource_pool_id = "${vsphere_resource_pool.parent.id}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		testAccResourceVSphereResourcePoolConfigExpectedName,
+		testAccResourceVSphereResourcePoolConfigExpectedParentName,
+	)
+}
+
+func testAccResourceVSphereResourcePoolConfigTag() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "resource_pool_name" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_tag_category" "terraform-test-category" {
+  name        = "terraform-test-tag-category"
+  cardinality = "MULTIPLE"
+
+  associable_types = [
+    "ResourcePool",
+  ]
+}
+
+resource "vsphere_tag" "terraform-test-tag" {
+  name        = "terraform-test-tag"
+  category_id = "${vsphere_tag_category.terraform-test-category.id}"
+}
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "${var.resource_pool_name}"
+  parent_resource_pool_id = "${data.vsphere_host.esxi_host.resource_pool_id}"
+  tags                    = ["${vsphere_tag.terraform-test-tag.id}"]
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		testAccResourceVSphereResourcePoolConfigExpectedName,
+	)
+}
@@ -3,9 +3,7 @@ package vsphere
 import (
 	"fmt"
 	"log"
-
-	"errors"
-	"path"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/vmware/govmomi"
@@ -29,20 +27,22 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVSphereVirtualDiskCreate,
 		Read:   resourceVSphereVirtualDiskRead,
+		Update: resourceVSphereVirtualDiskUpdate,
 		Delete: resourceVSphereVirtualDiskDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVirtualDiskImport,
+		},
 
 		Schema: map[string]*schema.Schema{
 			// Size in GB
 			"size": &schema.Schema{
 				Type:     schema.TypeInt,
 				Required: true,
-				ForceNew: true, //TODO Can this be optional (resize)?
 			},
 
 			"vmdk_path": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true, //TODO Can this be optional (move)?
 			},
 
 			"type": &schema.Schema{
@@ -84,7 +84,6 @@ func resourceVSphereVirtualDisk() *schema.Resource {
 			"datastore": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 			},
 		},
 	}
@@ -183,66 +182,19 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 		return err
 	}
 
-	ctx := context.TODO()
-	b, err := ds.Browser(ctx)
-	if err != nil {
-		return err
-	}
-
-	// `Datastore.Stat` does not allow to query `VmDiskFileQuery`. Instead, we
-	// search the datastore manually.
-	spec := types.HostDatastoreBrowserSearchSpec{
-		Query: []types.BaseFileQuery{&types.VmDiskFileQuery{Details: &types.VmDiskFileQueryFlags{
-			CapacityKb: true,
-			DiskType:   true,
-		}}},
-		Details: &types.FileQueryFlags{
-			FileSize:     true,
-			FileType:     true,
-			Modification: true,
-			FileOwner:    types.NewBool(true),
-		},
-		MatchPattern: []string{path.Base(vDisk.vmdkPath)},
-	}
-
-	dsPath := ds.Path(path.Dir(vDisk.vmdkPath))
-	task, err := b.SearchDatastore(context.TODO(), dsPath, &spec)
-
-	if err != nil {
-		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not search datastore for: %v", vDisk.vmdkPath)
-		return err
-	}
-
-	info, err := task.WaitForResult(context.TODO(), nil)
+	fileInfo, err := searchVirtualDisk(ds, vDisk.vmdkPath)
 	if err != nil {
-		if info == nil || info.Error != nil {
-			_, ok := info.Error.Fault.(*types.FileNotFound)
-			if ok {
-				log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not find: %v", vDisk.vmdkPath)
-				d.SetId("")
-				return nil
-			}
-		}
-
 		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not search datastore for: %v", vDisk.vmdkPath)
 		return err
 	}
-
-	res := info.Result.(types.HostDatastoreBrowserSearchResults)
-	log.Printf("[DEBUG] num results: %d", len(res.File))
-	if len(res.File) == 0 {
-		d.SetId("")
+	if fileInfo == nil {
 		log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - could not find: %v", vDisk.vmdkPath)
+		d.SetId("")
 		return nil
 	}
 
-	if len(res.File) != 1 {
-		return errors.New("Datastore search did not return exactly one result")
-	}
-
-	fileInfo := res.File[0]
 	log.Printf("[DEBUG] resourceVSphereVirtualDiskRead - fileinfo: %#v", fileInfo)
-	size := fileInfo.(*types.VmDiskFileInfo).CapacityKb / 1024 / 1024
+	size := fileInfo.CapacityKb / 1024 / 1024
 
 	d.SetId(vDisk.vmdkPath)
 
@@ -256,6 +208,59 @@ func resourceVSphereVirtualDiskRead(d *schema.ResourceData, meta interface{}) er
 
 }
 
+func resourceVSphereVirtualDiskUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+
+	if d.HasChange("size") {
+		o, n := d.GetChange("size")
+		if n.(int) < o.(int) {
+			// Keep the pending changes out of state, as none of them were made.
+			d.Partial(true)
+			return fmt.Errorf("virtual disk %q cannot be shrunk from %d GB to %d GB - only increases in size are supported", d.Get("vmdk_path").(string), o.(int), n.(int))
+		}
+	}
+
+	dc, err := getDatacenter(client, d.Get("datacenter").(string))
+	if err != nil {
+		return err
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+
+	ds, err := getDatastore(finder, d.Get("datastore").(string))
+	if err != nil {
+		return err
+	}
+
+	// Move the disk first, so that any resize happens at its new location.
+	if d.HasChange("vmdk_path") || d.HasChange("datastore") {
+		oldPath, _ := d.GetChange("vmdk_path")
+		oldDatastore, _ := d.GetChange("datastore")
+		oldDs, err := getDatastore(finder, oldDatastore.(string))
+		if err != nil {
+			return err
+		}
+		src := oldDs.Path(oldPath.(string))
+		dst := ds.Path(d.Get("vmdk_path").(string))
+		log.Printf("[INFO] Moving disk %s to %s", src, dst)
+		if err := moveVirtualDisk(client, src, dst, dc); err != nil {
+			return fmt.Errorf("error moving disk %s to %s: %s", src, dst, err)
+		}
+	}
+
+	if d.HasChange("size") {
+		n := d.Get("size")
+		diskPath := ds.Path(d.Get("vmdk_path").(string))
+		log.Printf("[INFO] Extending disk %s to %d GB", diskPath, n.(int))
+		if err := extendVirtualDisk(client, diskPath, dc, n.(int), d.Get("type").(string) == "eagerZeroedThick"); err != nil {
+			return fmt.Errorf("error extending disk %s: %s", diskPath, err)
+		}
+	}
+
+	return resourceVSphereVirtualDiskRead(d, meta)
+}
+
 func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*VSphereClient).vimClient
 
@@ -301,6 +306,57 @@ func resourceVSphereVirtualDiskDelete(d *schema.ResourceData, meta interface{})
 	return nil
 }
 
+func resourceVSphereVirtualDiskImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the datacenter path followed by the datastore path of the
+	// disk, ie: "/dc1/[datastore1] foo/bar.vmdk".
+	client := meta.(*VSphereClient).vimClient
+	p := d.Id()
+	i := strings.Index(p, "/[")
+	if !strings.HasPrefix(p, "/") || i < 1 {
+		return nil, fmt.Errorf("import ID %q must be in the form /DATACENTER/[DATASTORE] PATH", p)
+	}
+	dcp := strings.TrimPrefix(p[:i], "/")
+	var dsPath object.DatastorePath
+	if !dsPath.FromString(p[i+1:]) || dsPath.Path == "" {
+		return nil, fmt.Errorf("invalid datastore path %q", p[i+1:])
+	}
+
+	dc, err := getDatacenter(client, dcp)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find datacenter %q: %s", dcp, err)
+	}
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+	ds, err := finder.Datastore(context.TODO(), dsPath.Datastore)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find datastore %q: %s", dsPath.Datastore, err)
+	}
+
+	fileInfo, err := searchVirtualDisk(ds, dsPath.Path)
+	if err != nil {
+		return nil, fmt.Errorf("error searching for disk %q: %s", dsPath.String(), err)
+	}
+	if fileInfo == nil {
+		return nil, fmt.Errorf("disk %q not found", dsPath.String())
+	}
+	adapterType, ok := virtualDiskAdapterTypesFromAPI[fileInfo.ControllerType]
+	if !ok {
+		return nil, fmt.Errorf("disk %q has unsupported adapter type %q", dsPath.String(), fileInfo.ControllerType)
+	}
+	diskType, err := virtualDiskType(client, dsPath.String(), dc)
+	if err != nil {
+		return nil, fmt.Errorf("error reading disk type for %q: %s", dsPath.String(), err)
+	}
+
+	d.Set("datacenter", dcp)
+	d.Set("datastore", dsPath.Datastore)
+	d.Set("vmdk_path", dsPath.Path)
+	d.Set("type", diskType)
+	d.Set("adapter_type", adapterType)
+	d.SetId(dsPath.Path)
+	return []*schema.ResourceData{d}, nil
+}
+
 // createHardDisk creates a new Hard Disk.
 func createHardDisk(client *govmomi.Client, size int, diskPath string, diskType string, adapterType string, dc string) error {
 	var vDiskType string
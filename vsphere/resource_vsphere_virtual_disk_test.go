@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/acctest"
@@ -51,6 +52,115 @@ func TestAccVSphereVirtualDisk_basic(t *testing.T) {
 	})
 }
 
+func TestAccVSphereVirtualDisk_grow(t *testing.T) {
+	var datacenterOpt string
+	var datastoreOpt string
+
+	rString := acctest.RandString(5)
+
+	if v := os.Getenv("VSPHERE_DATACENTER"); v != "" {
+		datacenterOpt = v
+	}
+	if v := os.Getenv("VSPHERE_DATASTORE"); v != "" {
+		datastoreOpt = v
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereVirtualDiskDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_size(rString, 1, datacenterOpt, datastoreOpt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "size", "1"),
+				),
+			},
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_size(rString, 2, datacenterOpt, datastoreOpt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "size", "2"),
+				),
+			},
+			{
+				Config:      testAccCheckVSphereVirtuaDiskConfig_size(rString, 1, datacenterOpt, datastoreOpt),
+				ExpectError: regexp.MustCompile("only increases in size are supported"),
+			},
+		},
+	})
+}
+
+func TestAccVSphereVirtualDisk_move(t *testing.T) {
+	var datacenterOpt string
+	var datastoreOpt string
+
+	rString := acctest.RandString(5)
+
+	if v := os.Getenv("VSPHERE_DATACENTER"); v != "" {
+		datacenterOpt = v
+	}
+	if v := os.Getenv("VSPHERE_DATASTORE"); v != "" {
+		datastoreOpt = v
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereVirtualDiskDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_path(fmt.Sprintf("tfTestDisk-%s.vmdk", rString), datacenterOpt, datastoreOpt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo"),
+				),
+			},
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_path(fmt.Sprintf("tfTestDir-%s/tfTestDisk-%s-moved.vmdk", rString, rString), datacenterOpt, datastoreOpt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo"),
+					resource.TestCheckResourceAttr("vsphere_virtual_disk.foo", "vmdk_path", fmt.Sprintf("tfTestDir-%s/tfTestDisk-%s-moved.vmdk", rString, rString)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVSphereVirtualDisk_import(t *testing.T) {
+	var datacenterOpt string
+	var datastoreOpt string
+
+	rString := acctest.RandString(5)
+
+	if v := os.Getenv("VSPHERE_DATACENTER"); v != "" {
+		datacenterOpt = v
+	}
+	if v := os.Getenv("VSPHERE_DATASTORE"); v != "" {
+		datastoreOpt = v
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVSphereVirtualDiskDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVSphereVirtuaDiskConfig_size(rString, 1, datacenterOpt, datastoreOpt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccVSphereVirtualDiskExists("vsphere_virtual_disk.foo"),
+				),
+			},
+			{
+				ResourceName:      "vsphere_virtual_disk.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateId:     fmt.Sprintf("/%s/[%s] tfTestDisk-%s.vmdk", datacenterOpt, datastoreOpt, rString),
+			},
+		},
+	})
+}
+
 func testAccVSphereVirtualDiskExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -127,3 +237,27 @@ resource "vsphere_virtual_disk" "foo" {
 }
 `, rName, initTypeOpt, adapterTypeOpt, datacenterOpt, datastoreOpt)
 }
+
+func testAccCheckVSphereVirtuaDiskConfig_size(rName string, size int, datacenterOpt, datastoreOpt string) string {
+	return fmt.Sprintf(`
+resource "vsphere_virtual_disk" "foo" {
+    size = %d
+    vmdk_path = "tfTestDisk-%s.vmdk"
+    type = "thin"
+    datacenter = "%s"
+    datastore = "%s"
+}
+`, size, rName, datacenterOpt, datastoreOpt)
+}
+
+func testAccCheckVSphereVirtuaDiskConfig_path(vmdkPath, datacenterOpt, datastoreOpt string) string {
+	return fmt.Sprintf(`
+resource "vsphere_virtual_disk" "foo" {
+    size = 1
+    vmdk_path = "%s"
+    type = "thin"
+    datacenter = "%s"
+    datastore = "%s"
+}
+`, vmdkPath, datacenterOpt, datastoreOpt)
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -18,13 +20,33 @@ import (
 	"golang.org/x/net/context"
 )
 
-var DefaultDNSSuffixes = []string{
-	"vsphere.local",
+const (
+	// customizeFailurePolicyTaint keeps a virtual machine that failed
+	// customization in state, where it is tainted and replaced on the next
+	// apply.
+	customizeFailurePolicyTaint = "taint"
+
+	// customizeFailurePolicyLeave leaves a virtual machine that failed
+	// customization in place, without adding it to state, so that the guest
+	// can be inspected.
+	customizeFailurePolicyLeave = "leave"
+)
+
+var customizeFailurePolicyAllowedValues = []string{
+	customizeFailurePolicyTaint,
+	customizeFailurePolicyLeave,
 }
 
-var DefaultDNSServers = []string{
-	"8.8.8.8",
-	"8.8.4.4",
+// virtualMachineNetworkInterfaceCustomizationKeys are the keys of the
+// network_interface block that only apply to guest customization, and are
+// carried over from the configuration on read.
+var virtualMachineNetworkInterfaceCustomizationKeys = []string{
+	"ipv6_addresses",
+	"ipv6_mode",
+	"dns_server_list",
+	"dns_domain",
+	"primary_wins",
+	"secondary_wins",
 }
 
 var DiskControllerTypes = []string{
@@ -45,6 +67,12 @@ type networkInterface struct {
 	ipv6Address      string
 	ipv6PrefixLength int
 	ipv6Gateway      string
+	ipv6Addresses    []string
+	ipv6Mode         string
+	dnsServers       []string
+	dnsDomain        string
+	primaryWINS      string
+	secondaryWINS    string
 	adapterType      string // TODO: Make "adapter_type" argument
 	macAddress       string
 }
@@ -66,6 +94,13 @@ type windowsOptConfig struct {
 	domainUser         string
 	domain             string
 	domainUserPassword string
+	workgroup          string
+	fullName           string
+	orgName            string
+	autoLogon          bool
+	autoLogonCount     int32
+	runOnceCommands    []string
+	sysprepText        string
 }
 
 type cdrom struct {
@@ -85,6 +120,7 @@ type virtualMachine struct {
 	cluster               string
 	resourcePool          string
 	datastore             string
+	datastoreClusterID    string
 	vcpu                  int32
 	memoryMb              int64
 	memoryAllocation      memoryAllocation
@@ -100,10 +136,14 @@ type virtualMachine struct {
 	hasBootableVmdk       bool
 	linkedClone           bool
 	skipCustomization     bool
+	customizationSpecName string
+	customizeTimeout      time.Duration
 	enableDiskUUID        bool
 	moid                  string
 	windowsOptionalConfig windowsOptConfig
 	customConfigurations  map[string](types.AnyType)
+	vAppProperties        map[string]interface{}
+	cloudInit             *cloudInitConfig
 }
 
 func (v virtualMachine) Path() string {
@@ -124,15 +164,17 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 		Read:   resourceVSphereVirtualMachineRead,
 		Update: resourceVSphereVirtualMachineUpdate,
 		Delete: resourceVSphereVirtualMachineDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVirtualMachineImport,
+		},
 
-		SchemaVersion: 1,
+		SchemaVersion: 2,
 		MigrateState:  resourceVSphereVirtualMachineMigrateState,
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
 			"hostname": &schema.Schema{
@@ -142,9 +184,9 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			},
 
 			"folder": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
+				Type:      schema.TypeString,
+				Optional:  true,
+				StateFunc: normalizeFolderPath,
 			},
 
 			"vcpu": &schema.Schema{
@@ -178,15 +220,26 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			"cluster": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
+				Computed: true,
 			},
 
 			"resource_pool": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"datastore_cluster_id": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: true,
 			},
 
+			"datastore_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
 			"linked_clone": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -203,15 +256,15 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 			"domain": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
+				Computed: true,
 				ForceNew: true,
-				Default:  "vsphere.local",
 			},
 
 			"time_zone": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
+				Computed: true,
 				ForceNew: true,
-				Default:  "Etc/UTC",
 			},
 
 			"dns_suffixes": &schema.Schema{
@@ -235,6 +288,26 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				Default:  false,
 			},
 
+			"customization_spec_name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"customize_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      int(virtualMachineCustomizationWaiterTimeout / time.Minute),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"customize_failure_policy": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      customizeFailurePolicyTaint,
+				ValidateFunc: validation.StringInSlice(customizeFailurePolicyAllowedValues, false),
+			},
+
 			"wait_for_guest_net": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -271,6 +344,59 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"cloud_init": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"metadata": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"userdata": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"vendordata": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"encoding": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							Default:      cloudInitEncodingGzipBase64,
+							ValidateFunc: validation.StringInSlice(cloudInitEncodingAllowedValues, false),
+						},
+						"skip_linux_prep": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							ForceNew: true,
+							Default:  true,
+						},
+					},
+				},
+			},
+
+			"vapp": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"properties": &schema.Schema{
+							Type:     schema.TypeMap,
+							Optional: true,
+						},
+					},
+				},
+			},
+
 			"windows_opt_config": &schema.Schema{
 				Type:     schema.TypeList,
 				Optional: true,
@@ -306,6 +432,64 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 							Optional: true,
 							ForceNew: true,
 						},
+
+						"workgroup": &schema.Schema{
+							Type:          schema.TypeString,
+							Optional:      true,
+							ForceNew:      true,
+							ConflictsWith: []string{"windows_opt_config.0.domain"},
+						},
+
+						"full_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+							Default:  "terraform",
+						},
+
+						"organization_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+							Default:  "terraform",
+						},
+
+						"auto_logon": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							ForceNew: true,
+							Default:  false,
+						},
+
+						"auto_logon_count": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ForceNew:     true,
+							Default:      1,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+
+						"run_once_command_list": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						"sysprep_text": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+							ConflictsWith: []string{
+								"windows_opt_config.0.product_key",
+								"windows_opt_config.0.admin_password",
+								"windows_opt_config.0.domain_user",
+								"windows_opt_config.0.domain",
+								"windows_opt_config.0.domain_user_password",
+								"windows_opt_config.0.workgroup",
+								"windows_opt_config.0.run_once_command_list",
+							},
+						},
 					},
 				},
 			},
@@ -378,6 +562,48 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 							Computed:         true,
 							DiffSuppressFunc: suppressIpDifferences},
 
+						"ipv6_addresses": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validateIPv6CIDR,
+							},
+						},
+
+						"ipv6_mode": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringInSlice(virtualMachineIPv6ModeAllowedValues, false),
+						},
+
+						"dns_server_list": &schema.Schema{
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						"dns_domain": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+
+						"primary_wins": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+
+						"secondary_wins": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+
 						"adapter_type": &schema.Schema{
 							Type:     schema.TypeString,
 							Optional: true,
@@ -492,6 +718,13 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 				Default:  false,
 			},
 
+			"migrate_wait_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(10),
+			},
+
 			"cdrom": &schema.Schema{
 				Type:     schema.TypeList,
 				Optional: true,
@@ -520,6 +753,10 @@ func resourceVSphereVirtualMachine() *schema.Resource {
 }
 
 func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := validateVirtualMachineDiskChanges(d); err != nil {
+		return err
+	}
+
 	// flag if changes have to be applied
 	hasChanges := false
 	// flag if changes have to be done when powered off
@@ -561,9 +798,46 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 	finder := find.NewFinder(client.Client, true)
 	finder = finder.SetDatacenter(dc)
 
-	vm, err := finder.VirtualMachine(context.TODO(), vmPath(d.Get("folder").(string), d.Get("name").(string)))
+	vm, err := virtualMachineFromUUID(client, d.Id())
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+
+	// Disks that have only changed datastore or size are moved with Storage
+	// vMotion or grown in place, rather than being removed and re-added.
+	var addedDisks, removedDisks *schema.Set
+	var diskChanges []virtualMachineDiskChange
+	if d.HasChange("disk") {
+		oldDisks, newDisks := d.GetChange("disk")
+		oldDiskSet := oldDisks.(*schema.Set)
+		newDiskSet := newDisks.(*schema.Set)
+
+		addedDisks = newDiskSet.Difference(oldDiskSet)
+		removedDisks = oldDiskSet.Difference(newDiskSet)
+		diskChanges = diskChangesFromDiff(addedDisks, removedDisks)
+	}
+	var diskMoved, diskResized bool
+	for _, c := range diskChanges {
+		diskMoved = diskMoved || c.moved()
+		diskResized = diskResized || c.resized()
+	}
+
+	// vApp properties are validated against the properties defined in the
+	// virtual machine, which it got from its template.
+	if d.HasChange("vapp") {
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+		}
+		o, n := d.GetChange("vapp.0.properties")
+		vAppSpec, err := expandVAppConfigSpec(props.Config.VAppConfig, o.(map[string]interface{}), n.(map[string]interface{}))
+		if err != nil {
+			return err
+		}
+		if vAppSpec != nil {
+			configSpec.VAppConfig = vAppSpec
+			hasChanges = true
+		}
 	}
 
 	// Apply any pending tags now, before proceeding with any expensive VM updates
@@ -573,14 +847,56 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		}
 	}
 
+	// Rename the virtual machine if our name has drifted.
+	if d.HasChange("name") {
+		if err := renameObject(client, vm.Reference(), d.Get("name").(string)); err != nil {
+			return fmt.Errorf("error renaming virtual machine: %s", err)
+		}
+	}
+
+	// Update folder if necessary
+	if d.HasChange("folder") {
+		folder := d.Get("folder").(string)
+		if err := moveVirtualMachineToFolder(client, vm, folder); err != nil {
+			return fmt.Errorf("could not move virtual machine to folder %q: %s", folder, err)
+		}
+	}
+
+	// Relocate the virtual machine if its compute or storage placement has
+	// changed.
+	if d.HasChange("cluster") || d.HasChange("resource_pool") || diskMoved {
+		var rp *object.ResourcePool
+		cluster, resourcePool := virtualMachineRelocationTarget(d)
+		if d.HasChange("cluster") || d.HasChange("resource_pool") {
+			rp, err = findVirtualMachineResourcePool(finder, cluster, resourcePool)
+		} else {
+			rp, err = vm.ResourcePool(context.TODO())
+		}
+		if err != nil {
+			return fmt.Errorf("error locating resource pool: %s", err)
+		}
+		oldDisks, _ := d.GetChange("disk")
+		spec, err := buildVMInPlaceRelocateSpec(finder, vm, rp, diskChanges, oldDisks.(*schema.Set))
+		if err != nil {
+			return fmt.Errorf("error building relocate spec: %s", err)
+		}
+		log.Printf("[INFO] Relocating virtual machine: %s", d.Id())
+		timeout := time.Duration(d.Get("migrate_wait_timeout").(int)) * time.Minute
+		if err := relocateVirtualMachine(vm, spec, timeout); err != nil {
+			return fmt.Errorf("error relocating virtual machine: %s", err)
+		}
+		d.Set("resource_pool", resourcePool)
+	}
+
+	if diskResized {
+		oldDisks, _ := d.GetChange("disk")
+		if err := resizeVirtualMachineDisks(vm, diskChanges, oldDisks.(*schema.Set)); err != nil {
+			return err
+		}
+	}
+
 	if d.HasChange("disk") {
 		hasChanges = true
-		oldDisks, newDisks := d.GetChange("disk")
-		oldDiskSet := oldDisks.(*schema.Set)
-		newDiskSet := newDisks.(*schema.Set)
-
-		addedDisks := newDiskSet.Difference(oldDiskSet)
-		removedDisks := oldDiskSet.Difference(newDiskSet)
 
 		// Removed disks
 		for _, diskRaw := range removedDisks.List() {
@@ -606,26 +922,35 @@ func resourceVSphereVirtualMachineUpdate(d *schema.ResourceData, meta interface{
 		for _, diskRaw := range addedDisks.List() {
 			if disk, ok := diskRaw.(map[string]interface{}); ok {
 
+				var size int64
+				if disk["size"] == 0 {
+					size = 0
+				} else {
+					size = int64(disk["size"].(int))
+				}
+
 				var datastore *object.Datastore
-				if disk["datastore"] == "" {
+				switch {
+				case d.Get("datastore_cluster_id").(string) != "":
+					if disk["datastore"] != "" {
+						return fmt.Errorf("Cannot specify a disk datastore when datastore_cluster_id is set")
+					}
+					datastore, err = recommendDatastoreForNewDisk(client, vm, d.Get("datastore_cluster_id").(string), size, disk["type"].(string))
+					if err != nil {
+						return fmt.Errorf("[ERROR] Update Add Disk - Error getting storage DRS recommendation: %v", err)
+					}
+				case disk["datastore"] == "":
 					datastore, err = finder.DefaultDatastore(context.TODO())
 					if err != nil {
 						return fmt.Errorf("[ERROR] Update Remove Disk - Error finding datastore: %v", err)
 					}
-				} else {
+				default:
 					datastore, err = finder.Datastore(context.TODO(), disk["datastore"].(string))
 					if err != nil {
 						log.Printf("[ERROR] Couldn't find datastore %v.  %s", disk["datastore"].(string), err)
 						return err
 					}
 				}
-
-				var size int64
-				if disk["size"] == 0 {
-					size = 0
-				} else {
-					size = int64(disk["size"].(int))
-				}
 				iops := int64(disk["iops"].(int))
 				controller_type := disk["controller_type"].(string)
 
@@ -772,6 +1097,10 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		vm.resourcePool = v.(string)
 	}
 
+	if v, ok := d.GetOk("datastore_cluster_id"); ok {
+		vm.datastoreClusterID = v.(string)
+	}
+
 	if v, ok := d.GetOk("domain"); ok {
 		vm.domain = v.(string)
 	}
@@ -794,6 +1123,12 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		vm.skipCustomization = v.(bool)
 	}
 
+	if v, ok := d.GetOk("customization_spec_name"); ok {
+		vm.customizationSpecName = v.(string)
+	}
+
+	vm.customizeTimeout = time.Duration(d.Get("customize_timeout").(int)) * time.Minute
+
 	if v, ok := d.GetOk("enable_disk_uuid"); ok {
 		vm.enableDiskUUID = v.(bool)
 	}
@@ -802,18 +1137,18 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		for _, v := range raw.([]interface{}) {
 			vm.dnsSuffixes = append(vm.dnsSuffixes, v.(string))
 		}
-	} else {
-		vm.dnsSuffixes = DefaultDNSSuffixes
 	}
 
 	if raw, ok := d.GetOk("dns_servers"); ok {
 		for _, v := range raw.([]interface{}) {
 			vm.dnsServers = append(vm.dnsServers, v.(string))
 		}
-	} else {
-		vm.dnsServers = DefaultDNSServers
 	}
 
+	vm.applyCustomizationDefaults(meta.(*VSphereClient).customizationDefaults)
+	d.Set("domain", vm.domain)
+	d.Set("time_zone", vm.timeZone)
+
 	if vL, ok := d.GetOk("custom_configuration_parameters"); ok {
 		if custom_configs, ok := vL.(map[string]interface{}); ok {
 			custom := make(map[string]types.AnyType)
@@ -825,6 +1160,19 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		}
 	}
 
+	if v, ok := d.GetOk("vapp.0.properties"); ok {
+		vm.vAppProperties = v.(map[string]interface{})
+	}
+
+	if vL, ok := d.GetOk("cloud_init"); ok {
+		vm.cloudInit = expandCloudInitConfig(vL.([]interface{})[0].(map[string]interface{}))
+		for _, key := range cloudInitGuestInfoKeys {
+			if _, ok := vm.customConfigurations[key]; ok {
+				return fmt.Errorf("%s cannot be set in custom_configuration_parameters when cloud_init is used", key)
+			}
+		}
+	}
+
 	if vL, ok := d.GetOk("network_interface"); ok {
 		networks := make([]networkInterface, len(vL.([]interface{})))
 		for i, v := range vL.([]interface{}) {
@@ -864,6 +1212,27 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 			if v, ok := network["ipv6_gateway"].(string); ok && v != "" {
 				networks[i].ipv6Gateway = v
 			}
+			if v, ok := network["ipv6_addresses"].([]interface{}); ok && len(v) > 0 {
+				if networks[i].ipv6Address != "" {
+					return fmt.Errorf("ipv6_address and ipv6_addresses cannot both be set on network_interface %d", i)
+				}
+				networks[i].ipv6Addresses = sliceInterfacesToStrings(v)
+			}
+			if v, ok := network["ipv6_mode"].(string); ok && v != "" {
+				networks[i].ipv6Mode = v
+			}
+			if v, ok := network["dns_server_list"].([]interface{}); ok && len(v) > 0 {
+				networks[i].dnsServers = sliceInterfacesToStrings(v)
+			}
+			if v, ok := network["dns_domain"].(string); ok && v != "" {
+				networks[i].dnsDomain = v
+			}
+			if v, ok := network["primary_wins"].(string); ok && v != "" {
+				networks[i].primaryWINS = v
+			}
+			if v, ok := network["secondary_wins"].(string); ok && v != "" {
+				networks[i].secondaryWINS = v
+			}
 			if v, ok := network["mac_address"].(string); ok && v != "" {
 				networks[i].macAddress = v
 			}
@@ -872,8 +1241,12 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		log.Printf("[DEBUG] network_interface init: %v", networks)
 	}
 
+	winOpt := windowsOptConfig{
+		fullName:       "terraform",
+		orgName:        "terraform",
+		autoLogonCount: 1,
+	}
 	if vL, ok := d.GetOk("windows_opt_config"); ok {
-		var winOpt windowsOptConfig
 		custom_configs := (vL.([]interface{}))[0].(map[string]interface{})
 		if v, ok := custom_configs["admin_password"].(string); ok && v != "" {
 			winOpt.adminPassword = v
@@ -890,9 +1263,29 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		if v, ok := custom_configs["domain_user_password"].(string); ok && v != "" {
 			winOpt.domainUserPassword = v
 		}
-		vm.windowsOptionalConfig = winOpt
-		log.Printf("[DEBUG] windows config init: %v", winOpt)
+		if v, ok := custom_configs["workgroup"].(string); ok && v != "" {
+			winOpt.workgroup = v
+		}
+		if v, ok := custom_configs["full_name"].(string); ok && v != "" {
+			winOpt.fullName = v
+		}
+		if v, ok := custom_configs["organization_name"].(string); ok && v != "" {
+			winOpt.orgName = v
+		}
+		if v, ok := custom_configs["auto_logon"].(bool); ok {
+			winOpt.autoLogon = v
+		}
+		if v, ok := custom_configs["auto_logon_count"].(int); ok && v > 0 {
+			winOpt.autoLogonCount = int32(v)
+		}
+		if v, ok := custom_configs["run_once_command_list"].([]interface{}); ok {
+			winOpt.runOnceCommands = sliceInterfacesToStrings(v)
+		}
+		if v, ok := custom_configs["sysprep_text"].(string); ok && v != "" {
+			winOpt.sysprepText = v
+		}
 	}
+	vm.windowsOptionalConfig = winOpt
 
 	if vL, ok := d.GetOk("disk"); ok {
 		if diskSet, ok := vL.(*schema.Set); ok {
@@ -918,6 +1311,9 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 				}
 
 				if v, ok := disk["datastore"].(string); ok && v != "" {
+					if vm.datastoreClusterID != "" {
+						return fmt.Errorf("Cannot specify a disk datastore when datastore_cluster_id is set")
+					}
 					vm.datastore = v
 				}
 
@@ -993,13 +1389,20 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		log.Printf("[DEBUG] cdrom init: %v", cdroms)
 	}
 
+	// A virtual machine that fails guest customization is either kept in state,
+	// where it is tainted so that it is replaced on the next apply, or left
+	// outside of Terraform for debugging, depending on customize_failure_policy.
+	var customizeErr error
 	if err := vm.setupVirtualMachine(client); err != nil {
-		return err
+		if !isCustomizationFailedError(err) || vm.moid == "" {
+			return err
+		}
+		if d.Get("customize_failure_policy").(string) == customizeFailurePolicyLeave {
+			return fmt.Errorf("%s. Virtual machine %q has been left in place for debugging, and is not managed by Terraform", err, vm.Path())
+		}
+		customizeErr = fmt.Errorf("%s. Virtual machine %q has been tainted, and will be replaced on the next apply", err, vm.Path())
 	}
 
-	d.SetId(vm.Path())
-	log.Printf("[INFO] Created virtual machine: %s", d.Id())
-
 	newVM, err := virtualMachineFromManagedObjectID(client, vm.moid)
 	if err != nil {
 		return err
@@ -1009,6 +1412,14 @@ func resourceVSphereVirtualMachineCreate(d *schema.ResourceData, meta interface{
 		return err
 	}
 
+	// The virtual machine is tracked by its UUID, so that it can be renamed and
+	// moved without losing track of it.
+	d.SetId(newProps.Config.Uuid)
+	log.Printf("[INFO] Created virtual machine: %s", vm.Path())
+	if customizeErr != nil {
+		return customizeErr
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, newVM); err != nil {
@@ -1038,11 +1449,22 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 	finder := find.NewFinder(client.Client, true)
 	finder = finder.SetDatacenter(dc)
 
-	vm, err := finder.VirtualMachine(context.TODO(), d.Id())
+	vm, err := virtualMachineFromUUID(client, d.Id())
 	if err != nil {
-		d.SetId("")
-		return nil
+		if isVirtualMachineNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+
+	// The name and folder are read from the inventory path so that renames and
+	// moves made outside of Terraform are picked up.
+	folder, err := rootPathParticleVM.SplitRelativeFolder(vm.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing virtual machine path %q: %s", vm.InventoryPath, err)
 	}
+	d.Set("folder", strings.Trim(normalizeFolderPath(folder), "/"))
 
 	err = d.Set("moid", vm.Reference().Value)
 	if err != nil {
@@ -1142,15 +1564,25 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 	deviceList := object.VirtualDeviceList(mvm.Config.Hardware.Device)
 	deviceList = deviceList.SelectByType((*types.VirtualEthernetCard)(nil))
 	log.Printf("[DEBUG] Device list %+v", deviceList)
-	for _, device := range deviceList {
+	for i, device := range deviceList {
 		networkInterface := make(map[string]interface{})
 		virtualDevice := device.GetVirtualDevice()
 		nic := device.(types.BaseVirtualEthernetCard)
-		DeviceName, _ := getNetworkName(client, vm, nic)
+		DeviceName, networkID, _ := getNetworkName(client, vm, nic)
 		log.Printf("[DEBUG] device name %s", DeviceName)
+		// Preserve the label as a managed object ID if that is how it was
+		// originally specified, to prevent a diff.
+		if networkID != "" && d.Get(fmt.Sprintf("network_interface.%d.label", i)).(string) == networkID {
+			DeviceName = networkID
+		}
 		networkInterface["label"] = DeviceName
 		networkInterface["mac_address"] = nic.GetVirtualEthernetCard().MacAddress
 		networkInterface["key"] = virtualDevice.Key
+		// The guest customization settings cannot be read back from the virtual
+		// machine, so carry them over from the configuration.
+		for _, k := range virtualMachineNetworkInterfaceCustomizationKeys {
+			networkInterface[k] = d.Get(fmt.Sprintf("network_interface.%d.%s", i, k))
+		}
 		log.Printf("[DEBUG] networkInterface %#v", networkInterface)
 		networkInterfaces = append(networkInterfaces, networkInterface)
 	}
@@ -1251,15 +1683,37 @@ func resourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{})
 		break
 	}
 
+	// The datastore the virtual machine's configuration lives on is tracked as
+	// a computed value, so that moves made by Storage DRS do not cause a diff.
+	var dsPath object.DatastorePath
+	if dsPath.FromString(mvm.Summary.Config.VmPathName) {
+		ds, err := finder.Datastore(context.TODO(), dsPath.Datastore)
+		if err != nil {
+			return fmt.Errorf("error locating datastore %q for virtual machine: %s", dsPath.Datastore, err)
+		}
+		d.Set("datastore_id", ds.Reference().Value)
+	}
+
 	d.Set("datacenter", dc)
 	d.Set("memory", mvm.Summary.Config.MemorySizeMB)
 	d.Set("memory_reservation", mvm.Summary.Config.MemoryReservation)
-	d.Set("cpu", mvm.Summary.Config.NumCpu)
+	d.Set("vcpu", mvm.Summary.Config.NumCpu)
 	d.Set("datastore", rootDatastore)
+	d.Set("name", mvm.Summary.Config.Name)
 	d.Set("uuid", mvm.Summary.Config.Uuid)
 	d.Set("annotation", mvm.Summary.Config.Annotation)
 	d.Set("power_state", mvm.Runtime.PowerState)
 
+	// Only the vApp properties set in the configuration are read back.
+	if v, ok := d.GetOk("vapp.0.properties"); ok {
+		vApp := map[string]interface{}{
+			"properties": flattenVAppProperties(mvm.Config.VAppConfig, v.(map[string]interface{})),
+		}
+		if err := d.Set("vapp", []interface{}{vApp}); err != nil {
+			return fmt.Errorf("error setting vapp: %s", err)
+		}
+	}
+
 	// Read tags if we have the ability to do so
 	if tagsClient, _ := meta.(*VSphereClient).TagsClient(); tagsClient != nil {
 		if err := readTagsForResource(tagsClient, vm, d); err != nil {
@@ -1279,9 +1733,9 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 	finder := find.NewFinder(client.Client, true)
 	finder = finder.SetDatacenter(dc)
 
-	vm, err := finder.VirtualMachine(context.TODO(), vmPath(d.Get("folder").(string), d.Get("name").(string)))
+	vm, err := virtualMachineFromUUID(client, d.Id())
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
 	}
 	devices, err := vm.Device(context.TODO())
 	if err != nil {
@@ -1372,11 +1826,272 @@ func resourceVSphereVirtualMachineDelete(d *schema.ResourceData, meta interface{
 	return nil
 }
 
-// addHardDisk adds a new Hard Disk to the VirtualMachine.
-func addHardDisk(vm *object.VirtualMachine, size, iops int64, diskType string, datastore *object.Datastore, diskPath string, controller_type string) error {
-	devices, err := vm.Device(context.TODO())
+// virtualMachineExtraConfigInternalPrefixes is a list of extraConfig key
+// prefixes that vSphere manages on its own. These are skipped when importing
+// custom_configuration_parameters, as they are not something a user would
+// normally set.
+var virtualMachineExtraConfigInternalPrefixes = []string{
+	"cpuid.",
+	"ethernet",
+	"guestinfo.",
+	"hpet",
+	"ide",
+	"migrate.",
+	"monitor.",
+	"numa.",
+	"nvram",
+	"pciBridge",
+	"sata",
+	"sched.",
+	"scsi",
+	"softPowerOff",
+	"svga.",
+	"tools.",
+	"toolsInstallManager.",
+	"virtualHW.",
+	"vmci",
+	"vmotion.",
+	"vmware.tools.",
+	"vmxstats.",
+}
+
+// virtualMachineExtraConfigManagedKeys is a list of extraConfig keys that
+// vSphere writes for settings that are managed through attributes of the
+// resource, such as disk.EnableUUID for enable_disk_uuid and the UUID keys for
+// uuid. These are skipped when importing custom_configuration_parameters, as
+// they would otherwise force a new virtual machine. Keys are matched without
+// regard to case, like vSphere does.
+var virtualMachineExtraConfigManagedKeys = []string{
+	"disk.EnableUUID",
+	"uuid.bios",
+	"uuid.location",
+	"vc.uuid",
+}
+
+func resourceVSphereVirtualMachineImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is either the UUID of the virtual machine, or the full path to
+	// it.
+	client := meta.(*VSphereClient).vimClient
+	var vm *object.VirtualMachine
+	var err error
+	if strings.HasPrefix(d.Id(), "/") {
+		vm, err = virtualMachineFromAbsolutePath(client, d.Id())
+	} else {
+		vm, err = virtualMachineFromUUID(client, d.Id())
+	}
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error locating virtual machine: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching virtual machine properties: %s", err)
+	}
+	if props.Config == nil {
+		return nil, fmt.Errorf("virtual machine %q has no configuration", vm.InventoryPath)
+	}
+	if props.Config.Template {
+		return nil, fmt.Errorf("virtual machine %q is a template and cannot be imported", vm.InventoryPath)
+	}
+
+	// Work out the datacenter and folder from the inventory path. The
+	// datacenter is only set if it is not the default one, as it will
+	// otherwise cause a diff on configurations that don't specify it.
+	dcp, err := rootPathParticleVM.SplitDatacenter(vm.InventoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing datacenter from virtual machine path %q: %s", vm.InventoryPath, err)
+	}
+	dc, err := getDatacenter(client, strings.TrimPrefix(dcp, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find datacenter from path %q: %s", dcp, err)
+	}
+	if defaultDC, err := getDatacenter(client, ""); err != nil || defaultDC.Reference().Value != dc.Reference().Value {
+		d.Set("datacenter", strings.TrimPrefix(dcp, "/"))
+	}
+	folder, err := rootPathParticleVM.SplitRelativeFolder(vm.InventoryPath)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing virtual machine path %q: %s", vm.InventoryPath, err)
+	}
+	folder = strings.Trim(normalizeFolderPath(folder), "/")
+	d.Set("name", props.Name)
+	d.Set("folder", folder)
+
+	// Resource pool and cluster
+	if props.ResourcePool != nil {
+		if err := importVirtualMachineResourcePool(client, d, props.ResourcePool.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	if props.Config.Flags.DiskUuidEnabled != nil {
+		d.Set("enable_disk_uuid", *props.Config.Flags.DiskUuidEnabled)
+	}
+
+	devices := object.VirtualDeviceList(props.Config.Hardware.Device)
+	if err := d.Set("disk", flattenImportedDisks(devices)); err != nil {
+		return nil, fmt.Errorf("error setting disks: %s", err)
+	}
+	if err := d.Set("cdrom", flattenImportedCdroms(devices)); err != nil {
+		return nil, fmt.Errorf("error setting cdroms: %s", err)
+	}
+	if err := d.Set("custom_configuration_parameters", flattenImportedExtraConfig(props.Config.ExtraConfig)); err != nil {
+		return nil, fmt.Errorf("error setting custom_configuration_parameters: %s", err)
+	}
+	if err := d.Set("cloud_init", flattenImportedCloudInit(props.Config.ExtraConfig)); err != nil {
+		return nil, fmt.Errorf("error setting cloud_init: %s", err)
+	}
+
+	// Set the defaults for settings that only apply at creation time, so that
+	// they don't cause a diff against configuration that leaves them out.
+	d.Set("linked_clone", false)
+	d.Set("skip_customization", false)
+	d.Set("wait_for_guest_net", true)
+	d.Set("detach_unknown_disks_on_delete", false)
+
+	d.SetId(props.Config.Uuid)
+	return []*schema.ResourceData{d}, nil
+}
+
+// importVirtualMachineResourcePool sets the resource_pool and cluster
+// attributes for an imported virtual machine from the supplied resource pool
+// managed object ID.
+func importVirtualMachineResourcePool(client *govmomi.Client, d *schema.ResourceData, id string) error {
+	pool, err := resourcePoolFromID(client, id)
+	if err != nil {
+		return fmt.Errorf("error locating resource pool: %s", err)
+	}
+	poolPath, err := rootPathParticleHost.SplitRelative(pool.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error parsing resource pool path %q: %s", pool.InventoryPath, err)
+	}
+	d.Set("resource_pool", poolPath)
+
+	poolProps, err := resourcePoolProperties(pool)
+	if err != nil {
+		return fmt.Errorf("error fetching resource pool properties: %s", err)
+	}
+	if poolProps.Owner.Type == "ClusterComputeResource" {
+		cluster, err := clusterFromID(client, poolProps.Owner.Value)
+		if err != nil {
+			return fmt.Errorf("error locating cluster: %s", err)
+		}
+		d.Set("cluster", cluster.Name())
+	}
+	return nil
+}
+
+// flattenImportedDisks returns the disk set entries for the virtual disks in
+// the supplied device list. Disks are imported as vmdk disks, and are flagged
+// as keep_on_remove so that they are not destroyed unless the user explicitly
+// opts into it.
+func flattenImportedDisks(devices object.VirtualDeviceList) []interface{} {
+	var disks []interface{}
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		vd := device.(*types.VirtualDisk)
+		var fileName, uuid, diskType string
+		switch backing := vd.Backing.(type) {
+		case *types.VirtualDiskFlatVer2BackingInfo:
+			fileName = backing.FileName
+			uuid = backing.Uuid
+			switch {
+			case backing.ThinProvisioned != nil && *backing.ThinProvisioned:
+				diskType = "thin"
+			case backing.EagerlyScrub != nil && *backing.EagerlyScrub:
+				diskType = "eager_zeroed"
+			default:
+				diskType = "lazy"
+			}
+		case *types.VirtualDiskSparseVer2BackingInfo:
+			fileName = backing.FileName
+			uuid = backing.Uuid
+			diskType = "thin"
+		default:
+			log.Printf("[DEBUG] flattenImportedDisks: skipping disk %d with unsupported backing %T", vd.Key, vd.Backing)
+			continue
+		}
+		var dsPath object.DatastorePath
+		if !dsPath.FromString(fileName) {
+			log.Printf("[DEBUG] flattenImportedDisks: skipping disk %d with unparseable path %q", vd.Key, fileName)
+			continue
+		}
+
+		controllerType := "scsi"
+		if _, ok := devices.FindByKey(vd.ControllerKey).(*types.VirtualIDEController); ok {
+			controllerType = "ide"
+		}
+
+		var iops int
+		if vd.StorageIOAllocation != nil && vd.StorageIOAllocation.Limit > 0 {
+			iops = int(vd.StorageIOAllocation.Limit)
+		}
+
+		disks = append(disks, map[string]interface{}{
+			"key":             int(vd.Key),
+			"uuid":            uuid,
+			"datastore":       dsPath.Datastore,
+			"vmdk":            dsPath.Path,
+			"type":            diskType,
+			"iops":            iops,
+			"controller_type": controllerType,
+			"keep_on_remove":  true,
+		})
+	}
+	return disks
+}
+
+// flattenImportedCdroms returns the cdrom list entries for the CD-ROM drives
+// in the supplied device list that have an ISO attached.
+func flattenImportedCdroms(devices object.VirtualDeviceList) []interface{} {
+	var cdroms []interface{}
+	for _, device := range devices.SelectByType((*types.VirtualCdrom)(nil)) {
+		backing, ok := device.GetVirtualDevice().Backing.(*types.VirtualCdromIsoBackingInfo)
+		if !ok {
+			continue
+		}
+		var dsPath object.DatastorePath
+		if !dsPath.FromString(backing.FileName) {
+			continue
+		}
+		cdroms = append(cdroms, map[string]interface{}{
+			"datastore": dsPath.Datastore,
+			"path":      dsPath.Path,
+		})
+	}
+	return cdroms
+}
+
+// flattenImportedExtraConfig returns the custom_configuration_parameters for
+// the supplied extraConfig, skipping any keys managed by vSphere itself or by
+// other attributes of the resource.
+func flattenImportedExtraConfig(extraConfig []types.BaseOptionValue) map[string]interface{} {
+	params := make(map[string]interface{})
+	for _, v := range extraConfig {
+		ov := v.GetOptionValue()
+		var internal bool
+		for _, prefix := range virtualMachineExtraConfigInternalPrefixes {
+			if strings.HasPrefix(ov.Key, prefix) {
+				internal = true
+				break
+			}
+		}
+		for _, key := range virtualMachineExtraConfigManagedKeys {
+			if strings.EqualFold(ov.Key, key) {
+				internal = true
+				break
+			}
+		}
+		if internal {
+			continue
+		}
+		params[ov.Key] = fmt.Sprintf("%v", ov.Value)
+	}
+	return params
+}
+
+// addHardDisk adds a new Hard Disk to the VirtualMachine.
+func addHardDisk(vm *object.VirtualMachine, size, iops int64, diskType string, datastore *object.Datastore, diskPath string, controller_type string) error {
+	devices, err := vm.Device(context.TODO())
+	if err != nil {
+		return err
 	}
 	log.Printf("[DEBUG] vm devices: %#v\n", devices)
 
@@ -1612,9 +2327,11 @@ func addCdrom(client *govmomi.Client, vm *object.VirtualMachine, datacenter *obj
 	return vm.AddDevice(context.TODO(), c)
 }
 
-// buildNetworkDevice builds VirtualDeviceConfigSpec for Network Device.
-func buildNetworkDevice(f *find.Finder, label, adapterType string, macAddress string) (*types.VirtualDeviceConfigSpec, error) {
-	network, err := f.Network(context.TODO(), "*"+label)
+// buildNetworkDevice builds VirtualDeviceConfigSpec for Network Device. The
+// label can either be the name of the network or its managed object ID, and
+// can refer to a standard network or a distributed port group.
+func buildNetworkDevice(c *govmomi.Client, f *find.Finder, label, adapterType string, macAddress string) (*types.VirtualDeviceConfigSpec, error) {
+	network, err := networkFromLabel(c, f, label)
 	if err != nil {
 		return nil, err
 	}
@@ -1690,12 +2407,16 @@ func buildVMRelocateSpec(rp *object.ResourcePool, ds *object.Datastore, vm *obje
 	isThin := initType == "thin"
 	eagerScrub := initType == "eager_zeroed"
 	rpr := rp.Reference()
-	dsr := ds.Reference()
-	return types.VirtualMachineRelocateSpec{
-		Datastore:    &dsr,
+	spec := types.VirtualMachineRelocateSpec{
 		Pool:         &rpr,
 		DiskMoveType: moveType,
-		Disk: []types.VirtualMachineRelocateSpecDiskLocator{
+	}
+	// A nil datastore means that the datastore will be picked by Storage DRS,
+	// in which case the placement is left up to the recommendation.
+	if ds != nil {
+		dsr := ds.Reference()
+		spec.Datastore = &dsr
+		spec.Disk = []types.VirtualMachineRelocateSpecDiskLocator{
 			{
 				Datastore: dsr,
 				DiskBackingInfo: &types.VirtualDiskFlatVer2BackingInfo{
@@ -1705,8 +2426,300 @@ func buildVMRelocateSpec(rp *object.ResourcePool, ds *object.Datastore, vm *obje
 				},
 				DiskId: key,
 			},
-		},
-	}, nil
+		}
+	}
+	return spec, nil
+}
+
+// virtualMachineRelocationTarget returns the cluster and resource pool to
+// relocate an existing virtual machine to.
+//
+// resource_pool is computed, so it still holds the resource pool in the old
+// cluster when only cluster is changed. In that case, no resource pool is
+// returned, so that the virtual machine is moved to the root resource pool of
+// the new cluster.
+func virtualMachineRelocationTarget(d *schema.ResourceData) (string, string) {
+	cluster := d.Get("cluster").(string)
+	if d.HasChange("cluster") && !d.HasChange("resource_pool") {
+		return cluster, ""
+	}
+	return cluster, d.Get("resource_pool").(string)
+}
+
+// findVirtualMachineResourcePool locates the resource pool for a virtual
+// machine. An explicit resource pool takes precedence, followed by the root
+// resource pool of the cluster, followed by the default resource pool.
+func findVirtualMachineResourcePool(finder *find.Finder, cluster, resourcePool string) (*object.ResourcePool, error) {
+	switch {
+	case resourcePool != "":
+		return finder.ResourcePool(context.TODO(), resourcePool)
+	case cluster != "":
+		return finder.ResourcePool(context.TODO(), "*"+cluster+"/Resources")
+	}
+	return finder.DefaultResourcePool(context.TODO())
+}
+
+// virtualMachineDiskChange describes a disk whose only changes are to its
+// datastore or size, which can be carried out in place instead of removing
+// and re-adding the disk. The old entry carries the device key of the disk.
+type virtualMachineDiskChange struct {
+	old map[string]interface{}
+	new map[string]interface{}
+}
+
+// moved returns true if the disk's datastore has changed.
+func (c virtualMachineDiskChange) moved() bool {
+	return c.old["datastore"] != c.new["datastore"]
+}
+
+// resized returns true if the disk's size has changed.
+func (c virtualMachineDiskChange) resized() bool {
+	return c.old["size"] != c.new["size"]
+}
+
+// diskChangesFromDiff finds the disks in the supplied added and removed sets
+// that differ only by datastore or size, removes them from both sets, and
+// returns them as in-place changes.
+func diskChangesFromDiff(added, removed *schema.Set) []virtualMachineDiskChange {
+	var changes []virtualMachineDiskChange
+	for _, ro := range removed.List() {
+		od := ro.(map[string]interface{})
+		for _, ao := range added.List() {
+			nd := ao.(map[string]interface{})
+			c := virtualMachineDiskChange{old: od, new: nd}
+			if !(c.moved() || c.resized()) || !diskEqualExcept(od, nd, "datastore", "size") {
+				continue
+			}
+			changes = append(changes, c)
+			removed.Remove(ro)
+			added.Remove(ao)
+			break
+		}
+	}
+	return changes
+}
+
+// diskEqualExcept compares two disk entries, ignoring the supplied keys and
+// any computed attributes.
+func diskEqualExcept(a, b map[string]interface{}, ignore ...string) bool {
+	skip := map[string]bool{"key": true, "uuid": true}
+	for _, k := range ignore {
+		skip[k] = true
+	}
+	for k, v := range b {
+		if skip[k] {
+			continue
+		}
+		if !reflect.DeepEqual(a[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateVirtualMachineDiskChanges rejects an update that would shrink any
+// disk of the virtual machine. The vendored helper/schema has no CustomizeDiff
+// hook, so this runs at the start of Update, before any change is made,
+// instead of at plan time.
+//
+// Partial state mode is turned on when the update is rejected, so that none of
+// the pending changes, and in particular not the smaller disk size, are saved
+// to state. Read does not refresh disk sizes, so the shrink would otherwise be
+// taken as applied.
+func validateVirtualMachineDiskChanges(d *schema.ResourceData) error {
+	if !d.HasChange("disk") {
+		return nil
+	}
+	oldDisks, newDisks := d.GetChange("disk")
+	added := newDisks.(*schema.Set).Difference(oldDisks.(*schema.Set))
+	removed := oldDisks.(*schema.Set).Difference(newDisks.(*schema.Set))
+	if err := validateDiskChanges(diskChangesFromDiff(added, removed)); err != nil {
+		d.Partial(true)
+		return err
+	}
+	return nil
+}
+
+// validateDiskChanges checks the supplied in-place disk changes for any that
+// would shrink a disk, which is not supported.
+func validateDiskChanges(changes []virtualMachineDiskChange) error {
+	for _, c := range changes {
+		if !c.resized() {
+			continue
+		}
+		o, n := c.old["size"].(int), c.new["size"].(int)
+		if n < o {
+			return fmt.Errorf("disk %q cannot be shrunk from %d GB to %d GB - only increases in size are supported", diskLabel(c.new), o, n)
+		}
+	}
+	return nil
+}
+
+// diskLabel returns a label for a disk entry for use in log and error
+// messages.
+func diskLabel(disk map[string]interface{}) string {
+	switch {
+	case disk["name"] != "":
+		return disk["name"].(string)
+	case disk["vmdk"] != "":
+		return disk["vmdk"].(string)
+	case disk["template"] != "":
+		return disk["template"].(string)
+	}
+	return fmt.Sprintf("key %d", disk["key"].(int))
+}
+
+// knownDiskKeys returns the device keys of all disks in the supplied disk set
+// that have one recorded in state.
+func knownDiskKeys(diskSet *schema.Set) map[int32]bool {
+	keys := make(map[int32]bool)
+	for _, v := range diskSet.List() {
+		if key := int32(v.(map[string]interface{})["key"].(int)); key != 0 {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// diskDeviceKey returns the device key for a disk entry. The key of the
+// template disk, which the virtual machine was cloned with, is not tracked in
+// state, so it is taken to be the first disk device that is not claimed by
+// any other disk.
+func diskDeviceKey(disks object.VirtualDeviceList, disk map[string]interface{}, knownKeys map[int32]bool) int32 {
+	key := int32(disk["key"].(int))
+	if key != 0 || disk["template"] == "" {
+		return key
+	}
+	for _, d := range disks {
+		if k := d.GetVirtualDevice().Key; !knownKeys[k] {
+			return k
+		}
+	}
+	return 0
+}
+
+// resizeVirtualMachineDisks grows the attached disks in the supplied in-place
+// disk changes to their new size. oldDisks is the full set of disks in state
+// before the update.
+func resizeVirtualMachineDisks(vm *object.VirtualMachine, changes []virtualMachineDiskChange, oldDisks *schema.Set) error {
+	devices, err := vm.Device(context.TODO())
+	if err != nil {
+		return err
+	}
+	disks := devices.SelectByType((*types.VirtualDisk)(nil))
+	knownKeys := knownDiskKeys(oldDisks)
+
+	for _, c := range changes {
+		if !c.resized() {
+			continue
+		}
+		key := diskDeviceKey(disks, c.old, knownKeys)
+		device := devices.FindByKey(key)
+		if device == nil {
+			return fmt.Errorf("could not find disk %q", diskLabel(c.new))
+		}
+		disk := device.(*types.VirtualDisk)
+		capacity := int64(c.new["size"].(int)) * 1024 * 1024
+		if capacity < disk.CapacityInKB {
+			return fmt.Errorf("disk %q cannot be shrunk from %d KB to %d KB - only increases in size are supported", diskLabel(c.new), disk.CapacityInKB, capacity)
+		}
+		if capacity == disk.CapacityInKB {
+			continue
+		}
+		log.Printf("[INFO] Growing disk %q from %d KB to %d KB", diskLabel(c.new), disk.CapacityInKB, capacity)
+		disk.CapacityInKB = capacity
+		if err := vm.EditDevice(context.TODO(), disk); err != nil {
+			return fmt.Errorf("error growing disk %q: %s", diskLabel(c.new), err)
+		}
+	}
+	return nil
+}
+
+// buildVMInPlaceRelocateSpec builds a relocate spec to move an existing
+// virtual machine to the supplied resource pool, and any moved disks in
+// changes to their new datastores. oldDisks is the full set of disks in state
+// before the update.
+func buildVMInPlaceRelocateSpec(finder *find.Finder, vm *object.VirtualMachine, rp *object.ResourcePool, changes []virtualMachineDiskChange, oldDisks *schema.Set) (types.VirtualMachineRelocateSpec, error) {
+	devices, err := vm.Device(context.TODO())
+	if err != nil {
+		return types.VirtualMachineRelocateSpec{}, err
+	}
+
+	datastores := make(map[int]types.ManagedObjectReference)
+	for i, m := range changes {
+		if !m.moved() {
+			continue
+		}
+		var ds *object.Datastore
+		if m.new["datastore"] == "" {
+			ds, err = finder.DefaultDatastore(context.TODO())
+		} else {
+			ds, err = finder.Datastore(context.TODO(), m.new["datastore"].(string))
+		}
+		if err != nil {
+			return types.VirtualMachineRelocateSpec{}, err
+		}
+		log.Printf("[DEBUG] Moving disk %q to datastore %q", diskLabel(m.new), ds.Name())
+		datastores[i] = ds.Reference()
+	}
+	return expandVMInPlaceRelocateSpec(rp.Reference(), devices, changes, datastores, knownDiskKeys(oldDisks))
+}
+
+// expandVMInPlaceRelocateSpec returns the relocate spec for an existing
+// virtual machine, moving it to pool, and the moved disks in changes to the
+// datastores at the same index in datastores.
+//
+// Disk backings are moved with sharing allowed, so that the disks of a linked
+// clone keep referring to their shared parent disk, instead of being
+// consolidated into full copies.
+func expandVMInPlaceRelocateSpec(pool types.ManagedObjectReference, devices object.VirtualDeviceList, changes []virtualMachineDiskChange, datastores map[int]types.ManagedObjectReference, knownKeys map[int32]bool) (types.VirtualMachineRelocateSpec, error) {
+	spec := types.VirtualMachineRelocateSpec{
+		Pool:         &pool,
+		DiskMoveType: string(types.VirtualMachineRelocateDiskMoveOptionsMoveAllDiskBackingsAndAllowSharing),
+	}
+	disks := devices.SelectByType((*types.VirtualDisk)(nil))
+
+	movedKeys := make(map[int32]bool)
+	for i, m := range changes {
+		dsr, ok := datastores[i]
+		if !m.moved() || !ok {
+			continue
+		}
+		key := diskDeviceKey(disks, m.old, knownKeys)
+		if m.old["template"] != "" {
+			// The virtual machine's files live with the template disk, so they are
+			// moved along with it.
+			spec.Datastore = &dsr
+		}
+		if devices.FindByKey(key) == nil {
+			return spec, fmt.Errorf("could not find disk with key %d", key)
+		}
+		spec.Disk = append(spec.Disk, types.VirtualMachineRelocateSpecDiskLocator{
+			DiskId:    key,
+			Datastore: dsr,
+		})
+		movedKeys[key] = true
+	}
+
+	// Moving the virtual machine's own files moves any disks not listed in the
+	// spec along with them, so pin the rest of the disks to where they are.
+	if spec.Datastore != nil {
+		for _, disk := range disks {
+			vd := disk.GetVirtualDevice()
+			if movedKeys[vd.Key] {
+				continue
+			}
+			backing, ok := vd.Backing.(types.BaseVirtualDeviceFileBackingInfo)
+			if !ok || backing.GetVirtualDeviceFileBackingInfo().Datastore == nil {
+				continue
+			}
+			spec.Disk = append(spec.Disk, types.VirtualMachineRelocateSpecDiskLocator{
+				DiskId:    vd.Key,
+				Datastore: *backing.GetVirtualDeviceFileBackingInfo().Datastore,
+			})
+		}
+	}
+	return spec, nil
 }
 
 // getDatastoreObject gets datastore object.
@@ -1813,6 +2826,134 @@ func findDatastore(c *govmomi.Client, sps types.StoragePlacementSpec) (*object.D
 	return datastore, nil
 }
 
+// buildStoragePlacementSpecPodCreate builds a StoragePlacementSpec that creates
+// a virtual machine in the supplied folder and resource pool, with the
+// datastore being picked from the supplied datastore cluster.
+func buildStoragePlacementSpecPodCreate(folder *object.Folder, rp *object.ResourcePool, pod *object.StoragePod, configSpec types.VirtualMachineConfigSpec) types.StoragePlacementSpec {
+	fr := folder.Reference()
+	rpr := rp.Reference()
+	spr := pod.Reference()
+
+	return types.StoragePlacementSpec{
+		Type:       string(types.StoragePlacementSpecPlacementTypeCreate),
+		ConfigSpec: &configSpec,
+		PodSelectionSpec: types.StorageDrsPodSelectionSpec{
+			StoragePod: &spr,
+		},
+		Folder:       &fr,
+		ResourcePool: &rpr,
+	}
+}
+
+// buildStoragePlacementSpecPodClone builds a StoragePlacementSpec that clones
+// the supplied template into the supplied folder, with the datastore being
+// picked from the supplied datastore cluster.
+func buildStoragePlacementSpecPodClone(folder *object.Folder, template *object.VirtualMachine, name string, pod *object.StoragePod, cloneSpec types.VirtualMachineCloneSpec) types.StoragePlacementSpec {
+	fr := folder.Reference()
+	vmr := template.Reference()
+	spr := pod.Reference()
+
+	return types.StoragePlacementSpec{
+		Type:      string(types.StoragePlacementSpecPlacementTypeClone),
+		Vm:        &vmr,
+		CloneSpec: &cloneSpec,
+		CloneName: name,
+		PodSelectionSpec: types.StorageDrsPodSelectionSpec{
+			StoragePod: &spr,
+		},
+		Folder: &fr,
+	}
+}
+
+// applyStorageDrsPlacement gets a Storage DRS recommendation for the supplied
+// placement spec, applies it, and returns the datastore that was picked.
+func applyStorageDrsPlacement(c *govmomi.Client, sps types.StoragePlacementSpec) (*object.Datastore, error) {
+	log.Printf("[DEBUG] applyStorageDrsPlacement: StoragePlacementSpec: %#v", sps)
+	rec, err := recommendStoragePodPlacement(c, sps)
+	if err != nil {
+		return nil, err
+	}
+	datastore, err := storagePodRecommendationDatastore(c, rec)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyStoragePodRecommendation(c, rec); err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] applyStorageDrsPlacement: datastore: %#v", datastore)
+	return datastore, nil
+}
+
+// recommendDatastoreForNewDisk asks Storage DRS which datastore in the
+// supplied datastore cluster a new disk of the supplied size should be placed
+// on.
+//
+// The recommendation is not applied directly, as Storage DRS would then pick
+// the file name for the disk and we would lose track of the disk's name. The
+// disk is created on the recommended datastore instead.
+func recommendDatastoreForNewDisk(c *govmomi.Client, vm *object.VirtualMachine, podID string, size int64, diskType string) (*object.Datastore, error) {
+	pod, err := storagePodFromID(c, podID)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := vm.Device(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	controller, err := devices.FindDiskController("")
+	if err != nil {
+		return nil, err
+	}
+
+	disk := &types.VirtualDisk{
+		VirtualDevice: types.VirtualDevice{
+			Key: -1,
+			Backing: &types.VirtualDiskFlatVer2BackingInfo{
+				DiskMode:        string(types.VirtualDiskModePersistent),
+				ThinProvisioned: types.NewBool(diskType == "thin"),
+				EagerlyScrub:    types.NewBool(diskType == "eager_zeroed"),
+			},
+		},
+		CapacityInKB: size * 1024 * 1024,
+	}
+	devices.AssignController(disk, controller)
+
+	vmr := vm.Reference()
+	spr := pod.Reference()
+	sps := types.StoragePlacementSpec{
+		Type: string(types.StoragePlacementSpecPlacementTypeReconfigure),
+		Vm:   &vmr,
+		PodSelectionSpec: types.StorageDrsPodSelectionSpec{
+			InitialVmConfig: []types.VmPodConfigForPlacement{
+				{
+					StoragePod: spr,
+					Disk: []types.PodDiskLocator{
+						{
+							DiskId:          disk.Key,
+							DiskBackingInfo: disk.Backing,
+						},
+					},
+				},
+			},
+		},
+		ConfigSpec: &types.VirtualMachineConfigSpec{
+			DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+				&types.VirtualDeviceConfigSpec{
+					Operation:     types.VirtualDeviceConfigSpecOperationAdd,
+					FileOperation: types.VirtualDeviceConfigSpecFileOperationCreate,
+					Device:        disk,
+				},
+			},
+		},
+	}
+	log.Printf("[DEBUG] recommendDatastoreForNewDisk: StoragePlacementSpec: %#v", sps)
+	rec, err := recommendStoragePodPlacement(c, sps)
+	if err != nil {
+		return nil, err
+	}
+	return storagePodRecommendationDatastore(c, rec)
+}
+
 // createCdroms is a helper function to attach virtual cdrom devices (and their attached disk images) to a virtual IDE controller.
 func createCdroms(client *govmomi.Client, vm *object.VirtualMachine, datacenter *object.Datacenter, cdroms []cdrom) error {
 	log.Printf("[DEBUG] add cdroms: %v", cdroms)
@@ -1848,30 +2989,24 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		}
 		log.Printf("[DEBUG] template: %#v", template)
 
-		err = template.Properties(context.TODO(), template.Reference(), []string{"parent", "config.template", "config.guestId", "resourcePool", "snapshot", "guest.toolsVersionStatus2", "config.guestFullName"}, &template_mo)
+		err = template.Properties(context.TODO(), template.Reference(), []string{"parent", "config.template", "config.guestId", "config.vAppConfig", "resourcePool", "snapshot", "guest.toolsVersionStatus2", "config.guestFullName"}, &template_mo)
 		if err != nil {
 			return err
 		}
-	}
 
-	var resourcePool *object.ResourcePool
-	if vm.resourcePool == "" {
-		if vm.cluster == "" {
-			resourcePool, err = finder.DefaultResourcePool(context.TODO())
-			if err != nil {
-				return err
-			}
-		} else {
-			resourcePool, err = finder.ResourcePool(context.TODO(), "*"+vm.cluster+"/Resources")
-			if err != nil {
+		// Check the time zone of Windows clones before the clone, as it would
+		// otherwise only be rejected when the guest is customized, with the new
+		// virtual machine left outside of Terraform.
+		if vm.usesSysprepIdentity(template_mo.Config.GuestId) {
+			if _, err := windowsTimeZoneIndex(vm.timeZone); err != nil {
 				return err
 			}
 		}
-	} else {
-		resourcePool, err = finder.ResourcePool(context.TODO(), vm.resourcePool)
-		if err != nil {
-			return err
-		}
+	}
+
+	resourcePool, err := findVirtualMachineResourcePool(finder, vm.cluster, vm.resourcePool)
+	if err != nil {
+		return err
 	}
 	log.Printf("[DEBUG] resource pool: %#v", resourcePool)
 
@@ -1932,13 +3067,46 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		log.Printf("[DEBUG] virtual machine Extra Config spec: %v", configSpec.ExtraConfig)
 	}
 
+	// cloud-init data is passed to the VMware datasource through guestinfo.
+	if vm.cloudInit != nil {
+		ov, err := vm.cloudInit.extraConfig()
+		if err != nil {
+			return err
+		}
+		configSpec.ExtraConfig = append(configSpec.ExtraConfig, ov...)
+	}
+
+	// vApp properties are validated against the properties defined in the
+	// template.
+	if len(vm.vAppProperties) > 0 {
+		if vm.template == "" {
+			return fmt.Errorf("vApp properties can only be set on virtual machines cloned from a template")
+		}
+		vAppSpec, err := expandVAppConfigSpec(template_mo.Config.VAppConfig, nil, vm.vAppProperties)
+		if err != nil {
+			return err
+		}
+		if vAppSpec != nil {
+			configSpec.VAppConfig = vAppSpec
+		}
+	}
+
 	var datastore *object.Datastore
-	if vm.datastore == "" {
+	var pod *object.StoragePod
+	switch {
+	case vm.datastoreClusterID != "":
+		// The datastore is picked by Storage DRS when the virtual machine is
+		// deployed.
+		pod, err = storagePodFromID(c, vm.datastoreClusterID)
+		if err != nil {
+			return fmt.Errorf("error locating datastore cluster: %s", err)
+		}
+	case vm.datastore == "":
 		datastore, err = finder.DefaultDatastore(context.TODO())
 		if err != nil {
 			return err
 		}
-	} else {
+	default:
 		datastore, err = finder.Datastore(context.TODO(), vm.datastore)
 		if err != nil {
 			// TODO: datastore cluster support in govmomi finder function
@@ -1982,7 +3150,7 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		} else {
 			networkDeviceType = "vmxnet3"
 		}
-		nd, err := buildNetworkDevice(finder, network.label, networkDeviceType, network.macAddress)
+		nd, err := buildNetworkDevice(c, finder, network.label, networkDeviceType, network.macAddress)
 		if err != nil {
 			return err
 		}
@@ -1990,52 +3158,9 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		networkDevices = append(networkDevices, nd)
 
 		if vm.template != "" {
-			var ipSetting types.CustomizationIPSettings
-			if network.ipv4Address == "" {
-				ipSetting.Ip = &types.CustomizationDhcpIpGenerator{}
-			} else {
-				if network.ipv4PrefixLength == 0 {
-					return fmt.Errorf("Error: ipv4_prefix_length argument is empty.")
-				}
-				m := net.CIDRMask(network.ipv4PrefixLength, 32)
-				sm := net.IPv4(m[0], m[1], m[2], m[3])
-				subnetMask := sm.String()
-				log.Printf("[DEBUG] ipv4 gateway: %v\n", network.ipv4Gateway)
-				log.Printf("[DEBUG] ipv4 address: %v\n", network.ipv4Address)
-				log.Printf("[DEBUG] ipv4 prefix length: %v\n", network.ipv4PrefixLength)
-				log.Printf("[DEBUG] ipv4 subnet mask: %v\n", subnetMask)
-				ipSetting.Gateway = []string{
-					network.ipv4Gateway,
-				}
-				ipSetting.Ip = &types.CustomizationFixedIp{
-					IpAddress: network.ipv4Address,
-				}
-				ipSetting.SubnetMask = subnetMask
-			}
-
-			ipv6Spec := &types.CustomizationIPSettingsIpV6AddressSpec{}
-			if network.ipv6Address == "" {
-				ipv6Spec.Ip = []types.BaseCustomizationIpV6Generator{
-					&types.CustomizationDhcpIpV6Generator{},
-				}
-			} else {
-				log.Printf("[DEBUG] ipv6 gateway: %v\n", network.ipv6Gateway)
-				log.Printf("[DEBUG] ipv6 address: %v\n", network.ipv6Address)
-				log.Printf("[DEBUG] ipv6 prefix length: %v\n", network.ipv6PrefixLength)
-
-				ipv6Spec.Ip = []types.BaseCustomizationIpV6Generator{
-					&types.CustomizationFixedIpV6{
-						IpAddress:  network.ipv6Address,
-						SubnetMask: int32(network.ipv6PrefixLength),
-					},
-				}
-				ipv6Spec.Gateway = []string{network.ipv6Gateway}
-			}
-			ipSetting.IpV6Spec = ipv6Spec
-
-			// network config
-			config := types.CustomizationAdapterMapping{
-				Adapter: ipSetting,
+			config, err := network.customizationAdapterMapping()
+			if err != nil {
+				return err
 			}
 			networkConfigs = append(networkConfigs, config)
 		}
@@ -2045,11 +3170,6 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 
 	var task *object.Task
 	if vm.template == "" {
-		var mds mo.Datastore
-		if err = datastore.Properties(context.TODO(), datastore.Reference(), []string{"name"}, &mds); err != nil {
-			return err
-		}
-		log.Printf("[DEBUG] datastore: %#v", mds.Name)
 		scsi, err := object.SCSIControllerTypes().CreateSCSIController("scsi")
 		if err != nil {
 			log.Printf("[ERROR] %s", err)
@@ -2060,16 +3180,31 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 			Device:    scsi,
 		})
 
-		configSpec.Files = &types.VirtualMachineFileInfo{VmPathName: fmt.Sprintf("[%s]", mds.Name)}
+		if pod != nil {
+			// Storage DRS fills in the datastore for the virtual machine files.
+			configSpec.Files = &types.VirtualMachineFileInfo{}
+			datastore, err = applyStorageDrsPlacement(c, buildStoragePlacementSpecPodCreate(folder, resourcePool, pod, configSpec))
+			if err != nil {
+				return fmt.Errorf("error creating virtual machine through storage DRS: %s", err)
+			}
+		} else {
+			var mds mo.Datastore
+			if err = datastore.Properties(context.TODO(), datastore.Reference(), []string{"name"}, &mds); err != nil {
+				return err
+			}
+			log.Printf("[DEBUG] datastore: %#v", mds.Name)
+
+			configSpec.Files = &types.VirtualMachineFileInfo{VmPathName: fmt.Sprintf("[%s]", mds.Name)}
 
-		task, err = folder.CreateVM(context.TODO(), configSpec, resourcePool, nil)
-		if err != nil {
-			log.Printf("[ERROR] %s", err)
-		}
+			task, err = folder.CreateVM(context.TODO(), configSpec, resourcePool, nil)
+			if err != nil {
+				log.Printf("[ERROR] %s", err)
+			}
 
-		err = task.Wait(context.TODO())
-		if err != nil {
-			log.Printf("[ERROR] %s", err)
+			err = task.Wait(context.TODO())
+			if err != nil {
+				log.Printf("[ERROR] %s", err)
+			}
 		}
 
 	} else {
@@ -2096,15 +3231,24 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		}
 		log.Printf("[DEBUG] clone spec: %v", cloneSpec)
 
-		task, err = template.Clone(context.TODO(), folder, vm.name, cloneSpec)
-		if err != nil {
-			return err
+		if pod != nil {
+			datastore, err = applyStorageDrsPlacement(c, buildStoragePlacementSpecPodClone(folder, template, vm.name, pod, cloneSpec))
+			if err != nil {
+				return fmt.Errorf("error cloning virtual machine through storage DRS: %s", err)
+			}
+		} else {
+			task, err = template.Clone(context.TODO(), folder, vm.name, cloneSpec)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	err = task.Wait(context.TODO())
-	if err != nil {
-		log.Printf("[ERROR] %s", err)
+	if task != nil {
+		err = task.Wait(context.TODO())
+		if err != nil {
+			log.Printf("[ERROR] %s", err)
+		}
 	}
 
 	newVM, err := finder.VirtualMachine(context.TODO(), vm.Path())
@@ -2112,6 +3256,9 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		return err
 	}
 	log.Printf("[DEBUG] new vm: %v", newVM)
+	// Record the virtual machine now, so that the caller can still find it if
+	// a later step, such as customization, fails.
+	vm.moid = newVM.Reference().Value
 
 	devices, err := newVM.Device(context.TODO())
 	if err != nil {
@@ -2177,59 +3324,32 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 
 	if vm.skipCustomization || vm.template == "" {
 		log.Printf("[DEBUG] VM customization skipped")
+	} else if vm.customizationSpecName != "" {
+		if len(vm.hostname) == 0 {
+			vm.hostname = vm.name
+		}
+		customSpec, err := storedCustomizationSpecForVirtualMachine(c, vm.customizationSpecName, strings.Split(vm.hostname, ".")[0], networkConfigs)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[DEBUG] VM customization starting with stored spec %q", vm.customizationSpecName)
+		cw, err = customizeVirtualMachine(c, newVM, customSpec, vm.customizeTimeout)
+		if err != nil {
+			return err
+		}
+	} else if vm.cloudInit != nil && vm.cloudInit.skipLinuxPrep && !strings.HasPrefix(template_mo.Config.GuestId, "win") {
+		log.Printf("[DEBUG] VM customization skipped, cloud-init will configure the guest")
 	} else {
 		var identity_options types.BaseCustomizationIdentitySettings
 		if strings.HasPrefix(template_mo.Config.GuestId, "win") {
-			var timeZone int
-			if vm.timeZone == "Etc/UTC" {
-				vm.timeZone = "085"
-			}
-			timeZone, err := strconv.Atoi(vm.timeZone)
-			if err != nil {
-				return fmt.Errorf("Error converting TimeZone: %s", err)
-			}
-
-			guiUnattended := types.CustomizationGuiUnattended{
-				AutoLogon:      false,
-				AutoLogonCount: 1,
-				TimeZone:       int32(timeZone),
-			}
-
-			customIdentification := types.CustomizationIdentification{}
-
 			if len(vm.hostname) == 0 {
 				vm.hostname = vm.name
 			}
 
-			userData := types.CustomizationUserData{
-				ComputerName: &types.CustomizationFixedName{
-					Name: strings.Split(vm.hostname, ".")[0],
-				},
-				ProductId: vm.windowsOptionalConfig.productKey,
-				FullName:  "terraform",
-				OrgName:   "terraform",
-			}
-
-			if vm.windowsOptionalConfig.domainUserPassword != "" && vm.windowsOptionalConfig.domainUser != "" && vm.windowsOptionalConfig.domain != "" {
-				customIdentification.DomainAdminPassword = &types.CustomizationPassword{
-					PlainText: true,
-					Value:     vm.windowsOptionalConfig.domainUserPassword,
-				}
-				customIdentification.DomainAdmin = vm.windowsOptionalConfig.domainUser
-				customIdentification.JoinDomain = vm.windowsOptionalConfig.domain
-			}
-
-			if vm.windowsOptionalConfig.adminPassword != "" {
-				guiUnattended.Password = &types.CustomizationPassword{
-					PlainText: true,
-					Value:     vm.windowsOptionalConfig.adminPassword,
-				}
-			}
-
-			identity_options = &types.CustomizationSysprep{
-				GuiUnattended:  guiUnattended,
-				Identification: customIdentification,
-				UserData:       userData,
+			identity_options, err = vm.windowsOptionalConfig.sysprepIdentity(strings.Split(vm.hostname, ".")[0], vm.timeZone)
+			if err != nil {
+				return err
 			}
 		} else {
 
@@ -2256,15 +3376,9 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 			},
 			NicSettingMap: networkConfigs,
 		}
-		log.Printf("[DEBUG] custom spec: %v", customSpec)
 
 		log.Printf("[DEBUG] VM customization starting")
-		cw = newVirtualMachineCustomizationWaiter(c, newVM)
-		taskb, err := newVM.Customize(context.TODO(), customSpec)
-		if err != nil {
-			return err
-		}
-		_, err = taskb.WaitForResult(context.TODO(), nil)
+		cw, err = customizeVirtualMachine(c, newVM, customSpec, vm.customizeTimeout)
 		if err != nil {
 			return err
 		}
@@ -2295,34 +3409,39 @@ func (vm *virtualMachine) setupVirtualMachine(c *govmomi.Client) error {
 		}
 	}
 
-	vm.moid = newVM.Reference().Value
 	return nil
 }
 
-func getNetworkName(c *govmomi.Client, vm *object.VirtualMachine, nic types.BaseVirtualEthernetCard) (string, error) {
+// getNetworkName returns the name and managed object ID of the network that
+// backs the supplied network adapter. Distributed port groups are looked up
+// via their switch UUID and port group key.
+func getNetworkName(c *govmomi.Client, vm *object.VirtualMachine, nic types.BaseVirtualEthernetCard) (string, string, error) {
 	backingInfo := nic.GetVirtualEthernetCard().Backing
-	var deviceName string
-	switch backingInfo.(type) {
+	var deviceName, id string
+	switch backing := backingInfo.(type) {
 	case *types.VirtualEthernetCardNetworkBackingInfo:
-		deviceName = backingInfo.(*types.VirtualEthernetCardNetworkBackingInfo).DeviceName
-		break
+		deviceName = backing.DeviceName
+		if backing.Network != nil {
+			id = backing.Network.Value
+		}
 	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
-		portInfo := backingInfo.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo).Port
+		portInfo := backing.Port
 		log.Printf("network Port %#v", portInfo)
-		o := object.NewDistributedVirtualPortgroup(c.Client, types.ManagedObjectReference{
-			Type:  "DistributedVirtualPortgroup",
-			Value: portInfo.PortgroupKey,
-		})
-		var dvp mo.DistributedVirtualPortgroup
-		err := o.Properties(context.TODO(), o.Reference(), []string{"name", "config.distributedVirtualSwitch"}, &dvp)
+		pg, err := dvPortgroupFromKey(c, portInfo.SwitchUuid, portInfo.PortgroupKey)
 		if err != nil {
 			log.Printf("[ERROR]: Error retrieving portgroup %v", err)
-			return "", err
+			return "", "", err
+		}
+		props, err := dvPortgroupProperties(pg)
+		if err != nil {
+			log.Printf("[ERROR]: Error retrieving portgroup properties %v", err)
+			return "", "", err
 		}
-		deviceName = dvp.Name
+		deviceName = props.Name
+		id = pg.Reference().Value
 	}
 	log.Printf("network Port DeviceName %#v", deviceName)
-	return deviceName, nil
+	return deviceName, id, nil
 }
 
 // Suppress Diff on equal ip
@@ -1,11 +1,13 @@
 package vsphere
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
 
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/find"
 )
 
 func resourceVSphereVirtualMachineMigrateState(
@@ -15,10 +17,18 @@ func resourceVSphereVirtualMachineMigrateState(
 		return is, nil
 	}
 
+	var err error
 	switch v {
 	case 0:
 		log.Println("[INFO] Found Compute Instance State v0; migrating to v1")
-		is, err := migrateVSphereVirtualMachineStateV0toV1(is)
+		is, err = migrateVSphereVirtualMachineStateV0toV1(is)
+		if err != nil {
+			return is, err
+		}
+		fallthrough
+	case 1:
+		log.Println("[INFO] Found Compute Instance State v1; migrating to v2")
+		is, err = migrateVSphereVirtualMachineStateV1toV2(is, meta)
 		if err != nil {
 			return is, err
 		}
@@ -60,3 +70,55 @@ func migrateVSphereVirtualMachineStateV0toV1(is *terraform.InstanceState) (*terr
 	log.Printf("[DEBUG] Attributes after migration: %#v", is.Attributes)
 	return is, nil
 }
+
+// migrateVSphereVirtualMachineStateV1toV2 changes the ID of the virtual
+// machine from its path relative to the datacenter's VM folder to its UUID.
+//
+// The UUID is taken from the uuid attribute if it has already been read into
+// state, otherwise the virtual machine is looked up by its old path.
+func migrateVSphereVirtualMachineStateV1toV2(is *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	if is.Empty() {
+		log.Println("[DEBUG] Empty VSphere Virtual Machine State; nothing to migrate.")
+		return is, nil
+	}
+
+	log.Printf("[DEBUG] ID before migration: %s", is.ID)
+
+	if uuid := is.Attributes["uuid"]; uuid != "" {
+		is.ID = uuid
+		log.Printf("[DEBUG] ID after migration: %s", is.ID)
+		return is, nil
+	}
+
+	if meta == nil {
+		return is, fmt.Errorf("cannot migrate virtual machine %q: no uuid in state and no client available to look it up", is.ID)
+	}
+	client := meta.(*VSphereClient).vimClient
+	dc, err := getDatacenter(client, is.Attributes["datacenter"])
+	if err != nil {
+		return is, fmt.Errorf("cannot locate datacenter for virtual machine %q: %s", is.ID, err)
+	}
+	finder := find.NewFinder(client.Client, true)
+	finder = finder.SetDatacenter(dc)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	vm, err := finder.VirtualMachine(ctx, is.ID)
+	if err != nil {
+		return is, fmt.Errorf("cannot locate virtual machine %q: %s", is.ID, err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return is, fmt.Errorf("error fetching properties for virtual machine %q: %s", is.ID, err)
+	}
+	if props.Config == nil {
+		return is, fmt.Errorf("virtual machine %q has no configuration", is.ID)
+	}
+
+	is.ID = props.Config.Uuid
+	if is.Attributes == nil {
+		is.Attributes = make(map[string]string)
+	}
+	is.Attributes["uuid"] = props.Config.Uuid
+	log.Printf("[DEBUG] ID after migration: %s", is.ID)
+	return is, nil
+}
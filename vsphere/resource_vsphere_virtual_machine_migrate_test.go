@@ -11,18 +11,23 @@ func TestVSphereVirtualMachineMigrateState(t *testing.T) {
 		StateVersion int
 		Attributes   map[string]string
 		Expected     map[string]string
+		ExpectedID   string
 		Meta         interface{}
 	}{
 		"skip_customization before 0.6.16": {
 			StateVersion: 0,
-			Attributes:   map[string]string{},
+			Attributes: map[string]string{
+				"uuid": "42010a8c-0000-0000-0000-000000000000",
+			},
 			Expected: map[string]string{
 				"skip_customization": "false",
 			},
 		},
 		"enable_disk_uuid before 0.6.16": {
 			StateVersion: 0,
-			Attributes:   map[string]string{},
+			Attributes: map[string]string{
+				"uuid": "42010a8c-0000-0000-0000-000000000000",
+			},
 			Expected: map[string]string{
 				"enable_disk_uuid": "false",
 			},
@@ -34,6 +39,7 @@ func TestVSphereVirtualMachineMigrateState(t *testing.T) {
 				"disk.5678.size":            "0",
 				"disk.9999.size":            "0",
 				"disk.9999.controller_type": "ide",
+				"uuid":                      "42010a8c-0000-0000-0000-000000000000",
 			},
 			Expected: map[string]string{
 				"disk.1234.size":            "0",
@@ -44,6 +50,26 @@ func TestVSphereVirtualMachineMigrateState(t *testing.T) {
 				"disk.9999.controller_type": "ide",
 			},
 		},
+		"path ID to UUID": {
+			StateVersion: 1,
+			Attributes: map[string]string{
+				"uuid": "42010a8c-0000-0000-0000-000000000000",
+			},
+			Expected: map[string]string{
+				"uuid": "42010a8c-0000-0000-0000-000000000000",
+			},
+			ExpectedID: "42010a8c-0000-0000-0000-000000000000",
+		},
+		"path ID to UUID from v0": {
+			StateVersion: 0,
+			Attributes: map[string]string{
+				"uuid": "42010a8c-0000-0000-0000-000000000000",
+			},
+			Expected: map[string]string{
+				"skip_customization": "false",
+			},
+			ExpectedID: "42010a8c-0000-0000-0000-000000000000",
+		},
 	}
 
 	for tn, tc := range cases {
@@ -58,6 +84,10 @@ func TestVSphereVirtualMachineMigrateState(t *testing.T) {
 			t.Fatalf("bad: %s, err: %#v", tn, err)
 		}
 
+		if tc.ExpectedID != "" && is.ID != tc.ExpectedID {
+			t.Fatalf("bad: %s\n\n expected ID: %q\n got: %q", tn, tc.ExpectedID, is.ID)
+		}
+
 		for k, v := range tc.Expected {
 			if is.Attributes[k] != v {
 				t.Fatalf(
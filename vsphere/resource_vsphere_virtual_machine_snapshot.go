@@ -1,10 +1,11 @@
 package vsphere
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/vmware/govmomi/vim25/types"
@@ -14,7 +15,11 @@ func resourceVSphereVirtualMachineSnapshot() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceVSphereVirtualMachineSnapshotCreate,
 		Read:   resourceVSphereVirtualMachineSnapshotRead,
+		Update: resourceVSphereVirtualMachineSnapshotUpdate,
 		Delete: resourceVSphereVirtualMachineSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceVSphereVirtualMachineSnapshotImport,
+		},
 
 		Schema: map[string]*schema.Schema{
 			"virtual_machine_uuid": {
@@ -52,6 +57,31 @@ func resourceVSphereVirtualMachineSnapshot() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
+			"revert_on_destroy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"revert_trigger": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"creation_time": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"power_state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"quiesced": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"parent_snapshot_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -62,25 +92,28 @@ func resourceVSphereVirtualMachineSnapshotCreate(d *schema.ResourceData, meta in
 	if err != nil {
 		return fmt.Errorf("Error while getting the VirtualMachine :%s", err)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout) // This is 5 mins
-	defer cancel()
-	task, err := vm.CreateSnapshot(ctx, d.Get("snapshot_name").(string), d.Get("description").(string), d.Get("memory").(bool), d.Get("quiesce").(bool))
-	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
-	defer tcancel()
-	taskInfo, err := task.WaitForResult(tctx, nil)
-	if err != nil {
-		log.Printf("[DEBUG] Error While Creating the Task for Create Snapshot: %v", err)
-		return fmt.Errorf(" Error While Creating the Task for Create Snapshot: %s", err)
-	}
-	log.Printf("[DEBUG] Task created for Create Snapshot: %v", task)
+	id, err := createVirtualMachineSnapshot(vm, d.Get("snapshot_name").(string), d.Get("description").(string), d.Get("memory").(bool), d.Get("quiesce").(bool))
 	if err != nil {
-		log.Printf("[DEBUG] Error While waiting for the Task for Create Snapshot: %v", err)
-		return fmt.Errorf(" Error While waiting for the Task for Create Snapshot: %s", err)
+		log.Printf("[DEBUG] Error While Creating Snapshot: %v", err)
+		return fmt.Errorf(" Error While Creating Snapshot: %s", err)
 	}
 	log.Printf("[DEBUG] Create Snapshot completed %v", d.Get("snapshot_name").(string))
-	log.Println("[DEBUG] Managed Object Reference: " + taskInfo.Result.(types.ManagedObjectReference).Value)
-	d.SetId(taskInfo.Result.(types.ManagedObjectReference).Value)
-	return nil
+	log.Println("[DEBUG] Managed Object Reference: " + id)
+	d.SetId(id)
+	return resourceVSphereVirtualMachineSnapshotRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineSnapshotUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	// A change to revert_trigger reverts the virtual machine to this snapshot.
+	// The value itself has no meaning beyond signalling the revert.
+	if d.HasChange("revert_trigger") {
+		log.Printf("[DEBUG] Reverting virtual machine %q to snapshot %q", d.Get("virtual_machine_uuid").(string), d.Id())
+		if err := revertVirtualMachineToSnapshot(client, d.Id()); err != nil {
+			return fmt.Errorf("error reverting to snapshot: %s", err)
+		}
+	}
+	return resourceVSphereVirtualMachineSnapshotRead(d, meta)
 }
 
 func resourceVSphereVirtualMachineSnapshotDelete(d *schema.ResourceData, meta interface{}) error {
@@ -94,6 +127,12 @@ func resourceVSphereVirtualMachineSnapshotDelete(d *schema.ResourceData, meta in
 		log.Printf("[DEBUG] Error While finding the Snapshot: %v", err)
 		return nil
 	}
+	if d.Get("revert_on_destroy").(bool) {
+		log.Printf("[DEBUG] Reverting virtual machine %q to snapshot %q before deleting it", d.Get("virtual_machine_uuid").(string), d.Id())
+		if err := revertVirtualMachineToSnapshot(client, d.Id()); err != nil {
+			return fmt.Errorf("error reverting to snapshot: %s", err)
+		}
+	}
 	log.Printf("[DEBUG] Deleting snapshot with name: %v", d.Get("snapshot_name").(string))
 	var consolidatePtr *bool
 	var removeChildren bool
@@ -111,19 +150,9 @@ func resourceVSphereVirtualMachineSnapshotDelete(d *schema.ResourceData, meta in
 	} else {
 		removeChildren = false
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout) // This is 5 mins
-	defer cancel()
-	task, err := vm.RemoveSnapshot(ctx, d.Id(), removeChildren, consolidatePtr)
-	if err != nil {
-		log.Printf("[DEBUG] Error While Creating the Task for Delete Snapshot: %v", err)
-		return fmt.Errorf("Error While Creating the Task for Delete Snapshot: %s", err)
-	}
-	log.Printf("[DEBUG] Task created for Delete Snapshot: %v", task)
-
-	err = task.Wait(ctx)
-	if err != nil {
-		log.Printf("[DEBUG] Error While waiting for the Task of Delete Snapshot: %v", err)
-		return fmt.Errorf("Error While waiting for the Task of Delete Snapshot: %s", err)
+	if err := removeVirtualMachineSnapshot(vm, d.Id(), removeChildren, *consolidatePtr); err != nil {
+		log.Printf("[DEBUG] Error While Deleting Snapshot: %v", err)
+		return fmt.Errorf("Error While Deleting Snapshot: %s", err)
 	}
 	log.Printf("[DEBUG] Delete Snapshot completed %v", d.Get("snapshot_name").(string))
 
@@ -134,20 +163,65 @@ func resourceVSphereVirtualMachineSnapshotRead(d *schema.ResourceData, meta inte
 	client := meta.(*VSphereClient).vimClient
 	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
 	if err != nil {
-		return fmt.Errorf("Error while getting the VirtualMachine :%s", err)
-	}
-	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout) // This is 5 mins
-	defer cancel()
-	snapshot, err := vm.FindSnapshot(ctx, d.Id())
-	if err != nil {
-		if strings.Contains(err.Error(), "No snapshots for this VM") || strings.Contains(err.Error(), "snapshot \""+d.Get("snapshot_name").(string)+"\" not found") {
-			log.Printf("[DEBUG] Error While finding the Snapshot: %v", err)
+		if isVirtualMachineNotFoundError(err) {
+			log.Printf("[DEBUG] Virtual machine for snapshot %q is gone: %v", d.Id(), err)
 			d.SetId("")
 			return nil
 		}
-		log.Printf("[DEBUG] Error While finding the Snapshot: %v", err)
-		return fmt.Errorf("Error while finding the Snapshot :%s", err)
+		return fmt.Errorf("Error while getting the VirtualMachine :%s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+	}
+	snapshot, parent := findVirtualMachineSnapshotInTree(props, d.Id())
+	if snapshot == nil {
+		log.Printf("[DEBUG] Snapshot %q not found in snapshot tree of virtual machine %q", d.Id(), vm.InventoryPath)
+		d.SetId("")
+		return nil
 	}
-	log.Printf("[DEBUG] Snapshot found: %v", snapshot)
+	log.Printf("[DEBUG] Snapshot found: %v", snapshot.Name)
+
+	d.Set("snapshot_name", snapshot.Name)
+	d.Set("description", snapshot.Description)
+	d.Set("creation_time", snapshot.CreateTime.Format(time.RFC3339))
+	d.Set("power_state", string(snapshot.State))
+	d.Set("quiesced", snapshot.Quiesced)
+	var parentID string
+	if parent != nil {
+		parentID = parent.Snapshot.Value
+	}
+	d.Set("parent_snapshot_id", parentID)
 	return nil
 }
+
+func resourceVSphereVirtualMachineSnapshotImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// Our subject is the UUID of the virtual machine, followed by the managed
+	// object ID of the snapshot, separated by a colon.
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.New("import ID must be in the form VM_UUID:SNAPSHOT_ID")
+	}
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualMachineFromUUID(client, parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("error locating virtual machine: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get properties for virtual machine: %s", err)
+	}
+	snapshot, _ := findVirtualMachineSnapshotInTree(props, parts[1])
+	if snapshot == nil {
+		return nil, fmt.Errorf("snapshot %q not found on virtual machine %q", parts[1], vm.InventoryPath)
+	}
+
+	// A snapshot is only in the powered on state if it includes the memory of
+	// the virtual machine, so the state tells us whether memory was included.
+	d.Set("virtual_machine_uuid", parts[0])
+	d.Set("memory", snapshot.State == types.VirtualMachinePowerStatePoweredOn)
+	d.Set("quiesce", snapshot.Quiesced)
+	d.Set("revert_on_destroy", false)
+	d.SetId(parts[1])
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,278 @@
+package vsphere
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/object"
+)
+
+// virtualMachineSnapshotPolicyTimeFormat is the format of the timestamp that
+// is appended to the name prefix to make the name of each snapshot taken by
+// vsphere_virtual_machine_snapshot_policy.
+const virtualMachineSnapshotPolicyTimeFormat = "20060102T150405Z"
+
+func resourceVSphereVirtualMachineSnapshotPolicy() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVSphereVirtualMachineSnapshotPolicyCreate,
+		Read:   resourceVSphereVirtualMachineSnapshotPolicyRead,
+		Update: resourceVSphereVirtualMachineSnapshotPolicyUpdate,
+		Delete: resourceVSphereVirtualMachineSnapshotPolicyDelete,
+
+		Schema: map[string]*schema.Schema{
+			"virtual_machine_uuid": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The UUID of the virtual machine to take snapshots of.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name_prefix": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "The prefix of the name of each snapshot. Snapshots matching this prefix are subject to pruning.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"description": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The description of each snapshot.",
+				Optional:    true,
+			},
+			"memory": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Include the memory of the virtual machine in each snapshot.",
+				Optional:    true,
+				Default:     false,
+			},
+			"quiesce": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Quiesce the file system of the virtual machine with VMware Tools before each snapshot.",
+				Optional:    true,
+				Default:     false,
+			},
+			"keep_count": &schema.Schema{
+				Type:         schema.TypeInt,
+				Description:  "The number of snapshots matching name_prefix to keep. 0 keeps all snapshots.",
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"max_age": &schema.Schema{
+				Type:         schema.TypeString,
+				Description:  "The maximum age of snapshots matching name_prefix, as a duration such as 168h. Empty keeps snapshots of any age.",
+				Optional:     true,
+				ValidateFunc: validateSnapshotPolicyMaxAge,
+			},
+			"remove_children": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Remove the entire snapshot subtree of each pruned snapshot, unless the subtree holds snapshots that are kept.",
+				Optional:    true,
+				Default:     false,
+			},
+			"consolidate": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Consolidate the delta disks of each pruned snapshot into their parent.",
+				Optional:    true,
+				Default:     true,
+			},
+			"remove_on_destroy": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Remove all snapshots matching name_prefix when this resource is destroyed.",
+				Optional:    true,
+				Default:     false,
+			},
+			"trigger": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "An arbitrary value. A new snapshot is taken and old snapshots are pruned whenever this value changes.",
+				Optional:    true,
+			},
+			"latest_snapshot_id": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the most recent snapshot matching name_prefix.",
+				Computed:    true,
+			},
+			"latest_snapshot_name": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The name of the most recent snapshot matching name_prefix.",
+				Computed:    true,
+			},
+			"snapshot_ids": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The managed object IDs of all snapshots matching name_prefix, oldest first.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereVirtualMachineSnapshotPolicyCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	if err := takeVirtualMachineSnapshotPolicySnapshot(d, vm); err != nil {
+		return err
+	}
+	d.SetId(fmt.Sprintf("%s:%s", d.Get("virtual_machine_uuid").(string), d.Get("name_prefix").(string)))
+	if err := pruneVirtualMachineSnapshotPolicySnapshots(d, vm); err != nil {
+		return err
+	}
+	return resourceVSphereVirtualMachineSnapshotPolicyRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineSnapshotPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		if isVirtualMachineNotFoundError(err) {
+			log.Printf("[DEBUG] Virtual machine for snapshot policy %q is gone: %v", d.Id(), err)
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+	}
+
+	snapshots := virtualMachineSnapshotsWithPrefix(props, d.Get("name_prefix").(string))
+	var latestID, latestName string
+	if len(snapshots) > 0 {
+		latestID = snapshots[len(snapshots)-1].Snapshot.Value
+		latestName = snapshots[len(snapshots)-1].Name
+	}
+	d.Set("latest_snapshot_id", latestID)
+	d.Set("latest_snapshot_name", latestName)
+	if err := d.Set("snapshot_ids", snapshotTreeIDs(snapshots)); err != nil {
+		return fmt.Errorf("error setting snapshot_ids: %s", err)
+	}
+	return nil
+}
+
+func resourceVSphereVirtualMachineSnapshotPolicyUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	// Only a change to trigger takes a new snapshot. Changes to the retention
+	// settings only re-apply the pruning.
+	if d.HasChange("trigger") {
+		if err := takeVirtualMachineSnapshotPolicySnapshot(d, vm); err != nil {
+			return err
+		}
+	}
+	if err := pruneVirtualMachineSnapshotPolicySnapshots(d, vm); err != nil {
+		return err
+	}
+	return resourceVSphereVirtualMachineSnapshotPolicyRead(d, meta)
+}
+
+func resourceVSphereVirtualMachineSnapshotPolicyDelete(d *schema.ResourceData, meta interface{}) error {
+	if !d.Get("remove_on_destroy").(bool) {
+		log.Printf("[DEBUG] Leaving snapshots of policy %q in place", d.Id())
+		d.SetId("")
+		return nil
+	}
+	client := meta.(*VSphereClient).vimClient
+	vm, err := virtualMachineFromUUID(client, d.Get("virtual_machine_uuid").(string))
+	if err != nil {
+		if isVirtualMachineNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("cannot locate virtual machine: %s", err)
+	}
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+	}
+	snapshots := virtualMachineSnapshotsWithPrefix(props, d.Get("name_prefix").(string))
+	if err := removeVirtualMachineSnapshotPolicySnapshots(d, vm, snapshotTreeIDs(snapshots), nil); err != nil {
+		return err
+	}
+	d.SetId("")
+	return nil
+}
+
+// takeVirtualMachineSnapshotPolicySnapshot takes a snapshot of the virtual
+// machine, named after the name_prefix of the policy and the current time.
+func takeVirtualMachineSnapshotPolicySnapshot(d *schema.ResourceData, vm *object.VirtualMachine) error {
+	name := d.Get("name_prefix").(string) + time.Now().UTC().Format(virtualMachineSnapshotPolicyTimeFormat)
+	log.Printf("[DEBUG] Taking snapshot %q of virtual machine %q", name, vm.InventoryPath)
+	if _, err := createVirtualMachineSnapshot(vm, name, d.Get("description").(string), d.Get("memory").(bool), d.Get("quiesce").(bool)); err != nil {
+		return fmt.Errorf("error taking snapshot %q: %s", name, err)
+	}
+	return nil
+}
+
+// pruneVirtualMachineSnapshotPolicySnapshots removes the snapshots matching
+// the name_prefix of the policy that fall outside of its keep_count and
+// max_age settings.
+func pruneVirtualMachineSnapshotPolicySnapshots(d *schema.ResourceData, vm *object.VirtualMachine) error {
+	props, err := virtualMachineProperties(vm)
+	if err != nil {
+		return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+	}
+	// max_age has been validated already.
+	var maxAge time.Duration
+	if v := d.Get("max_age").(string); v != "" {
+		maxAge, _ = time.ParseDuration(v)
+	}
+	snapshots := virtualMachineSnapshotsWithPrefix(props, d.Get("name_prefix").(string))
+	expired := expiredVirtualMachineSnapshots(snapshots, d.Get("keep_count").(int), maxAge, time.Now())
+	// The list is sorted oldest first and the expired snapshots are always the
+	// oldest ones, so the rest of the list is kept.
+	retained := snapshots[len(expired):]
+	return removeVirtualMachineSnapshotPolicySnapshots(d, vm, snapshotTreeIDs(expired), snapshotTreeIDs(retained))
+}
+
+// removeVirtualMachineSnapshotPolicySnapshots removes the snapshots with the
+// supplied managed object IDs, using the remove_children and consolidate
+// settings of the policy. Snapshots that are already gone, for example as the
+// child of a snapshot removed earlier with remove_children, are skipped.
+//
+// The snapshots taken by a policy form a chain, so remove_children only
+// applies to snapshots whose subtree holds none of the snapshots in retained.
+// Other snapshots are removed on their own, keeping their children.
+func removeVirtualMachineSnapshotPolicySnapshots(d *schema.ResourceData, vm *object.VirtualMachine, ids, retained []string) error {
+	for _, id := range ids {
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+		}
+		node, _ := findVirtualMachineSnapshotInTree(props, id)
+		if node == nil {
+			continue
+		}
+		removeChildren := d.Get("remove_children").(bool)
+		if removeChildren && snapshotSubtreeContains(node, retained) {
+			log.Printf("[DEBUG] Snapshot %q has retained snapshots below it, keeping its children", id)
+			removeChildren = false
+		}
+		log.Printf("[DEBUG] Removing snapshot %q from virtual machine %q", id, vm.InventoryPath)
+		if err := removeVirtualMachineSnapshot(vm, id, removeChildren, d.Get("consolidate").(bool)); err != nil {
+			return fmt.Errorf("error removing snapshot %q: %s", id, err)
+		}
+	}
+	return nil
+}
+
+// validateSnapshotPolicyMaxAge checks that max_age is a valid, positive
+// duration.
+func validateSnapshotPolicyMaxAge(v interface{}, k string) ([]string, []error) {
+	d, err := time.ParseDuration(v.(string))
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	if d <= 0 {
+		return nil, []error{fmt.Errorf("%s must be a positive duration", k)}
+	}
+	return nil, nil
+}
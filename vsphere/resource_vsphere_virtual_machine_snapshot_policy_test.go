@@ -0,0 +1,140 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccResourceVSphereVirtualMachineSnapshotPolicy_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineSnapshotPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResourceVSphereVirtualMachineSnapshotPolicyConfig("one", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_snapshot_policy.policy", "snapshot_ids.#", "1"),
+					resource.TestCheckResourceAttrSet("vsphere_virtual_machine_snapshot_policy.policy", "latest_snapshot_id"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineSnapshotPolicyConfig("two", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_snapshot_policy.policy", "snapshot_ids.#", "2"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineSnapshotPolicyConfig("three", 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_snapshot_policy.policy", "snapshot_ids.#", "2"),
+				),
+			},
+			{
+				Config: testAccResourceVSphereVirtualMachineSnapshotPolicyConfig("three", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("vsphere_virtual_machine_snapshot_policy.policy", "snapshot_ids.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResourceVSphereVirtualMachineSnapshotPolicyConfig(trigger string, keepCount int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_addr" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "trigger" {
+  default = "%s"
+}
+
+variable "keep_count" {
+  default = "%d"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_addr}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = true
+}
+
+resource "vsphere_virtual_machine_snapshot_policy" "policy" {
+  virtual_machine_uuid = "${vsphere_virtual_machine.vm.uuid}"
+  name_prefix          = "terraform-test-"
+  description          = "Managed by Terraform"
+  keep_count           = "${var.keep_count}"
+  remove_on_destroy    = true
+  trigger              = "${var.trigger}"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		trigger,
+		keepCount,
+	)
+}
@@ -2,6 +2,7 @@ package vsphere
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -24,8 +25,25 @@ func TestAccResourceVSphereVirtualMachineSnapshot_Basic(t *testing.T) {
 					testAccCheckVirtualMachineSnapshotExists("vsphere_virtual_machine_snapshot.snapshot"),
 					resource.TestCheckResourceAttr(
 						"vsphere_virtual_machine_snapshot.snapshot", "snapshot_name", "terraform-test-snapshot"),
+					resource.TestCheckResourceAttr(
+						"vsphere_virtual_machine_snapshot.snapshot", "power_state", "poweredOn"),
+					resource.TestCheckResourceAttr(
+						"vsphere_virtual_machine_snapshot.snapshot", "parent_snapshot_id", ""),
+					resource.TestCheckResourceAttrSet(
+						"vsphere_virtual_machine_snapshot.snapshot", "creation_time"),
 				),
 			},
+			resource.TestStep{
+				ResourceName:      "vsphere_virtual_machine_snapshot.snapshot",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"remove_children",
+					"consolidate",
+					"revert_trigger",
+				},
+				ImportStateIdFunc: testAccResourceVSphereVirtualMachineSnapshotImportStateIDFunc("vsphere_virtual_machine_snapshot.snapshot"),
+			},
 			resource.TestStep{
 				Config: testAccResourceVSphereVirtualMachineSnapshotConfig(false),
 				Check: resource.ComposeTestCheckFunc(
@@ -36,6 +54,33 @@ func TestAccResourceVSphereVirtualMachineSnapshot_Basic(t *testing.T) {
 	})
 }
 
+func TestAccResourceVSphereVirtualMachineSnapshot_revertTrigger(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccResourceVSphereVirtualMachineSnapshotPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccResourceVSphereVirtualMachineSnapshotConfigRevertTrigger(true, "one"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineSnapshotExists("vsphere_virtual_machine_snapshot.snapshot"),
+					testAccCheckVirtualMachineSnapshotIsCurrent("vsphere_virtual_machine_snapshot.snapshot"),
+				),
+			},
+			resource.TestStep{
+				Config: testAccResourceVSphereVirtualMachineSnapshotConfigRevertTrigger(true, "two"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVirtualMachineSnapshotIsCurrent("vsphere_virtual_machine_snapshot.snapshot"),
+					resource.TestCheckResourceAttr(
+						"vsphere_virtual_machine_snapshot.snapshot", "revert_trigger", "two"),
+				),
+			},
+		},
+	})
+}
+
 func testAccResourceVSphereVirtualMachineSnapshotPreCheck(t *testing.T) {
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
 		t.Skip("set VSPHERE_DATACENTER to run vsphere_virtual_machine_snapshot acceptance tests")
@@ -94,6 +139,46 @@ func testAccCheckVirtualMachineSnapshotExists(n string) resource.TestCheckFunc {
 	}
 }
 
+// testAccCheckVirtualMachineSnapshotIsCurrent checks that the snapshot is the
+// current snapshot of its virtual machine, which is the case right after it
+// has been taken or reverted to.
+func testAccCheckVirtualMachineSnapshotIsCurrent(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		vm, err := virtualMachineFromUUID(client, rs.Primary.Attributes["virtual_machine_uuid"])
+		if err != nil {
+			return err
+		}
+		props, err := virtualMachineProperties(vm)
+		if err != nil {
+			return fmt.Errorf("cannot get properties for virtual machine: %s", err)
+		}
+		if props.Snapshot == nil || props.Snapshot.CurrentSnapshot == nil {
+			return errors.New("virtual machine has no current snapshot")
+		}
+		if props.Snapshot.CurrentSnapshot.Value != rs.Primary.ID {
+			return fmt.Errorf("expected current snapshot to be %q, got %q", rs.Primary.ID, props.Snapshot.CurrentSnapshot.Value)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineSnapshotImportStateIDFunc returns the
+// import ID of a snapshot, in the form VM_UUID:SNAPSHOT_ID.
+func testAccResourceVSphereVirtualMachineSnapshotImportStateIDFunc(n string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return "", fmt.Errorf("Not found: %s", n)
+		}
+		return fmt.Sprintf("%s:%s", rs.Primary.Attributes["virtual_machine_uuid"], rs.Primary.ID), nil
+	}
+}
+
 func testAccCheckVirtualMachineHasNoSnapshots(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -124,6 +209,10 @@ func testAccCheckVirtualMachineHasNoSnapshots(n string) resource.TestCheckFunc {
 }
 
 func testAccResourceVSphereVirtualMachineSnapshotConfig(enabled bool) string {
+	return testAccResourceVSphereVirtualMachineSnapshotConfigRevertTrigger(enabled, "")
+}
+
+func testAccResourceVSphereVirtualMachineSnapshotConfigRevertTrigger(enabled bool, trigger string) string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -165,6 +254,10 @@ variable "snapshot_enabled" {
   default = "%t"
 }
 
+variable "revert_trigger" {
+  default = "%s"
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name          = "terraform-test"
   datacenter    = "${var.datacenter}"
@@ -197,6 +290,7 @@ resource "vsphere_virtual_machine_snapshot" "snapshot" {
   description          = "Managed by Terraform"
   memory               = true
   quiesce              = true
+  revert_trigger       = "${var.revert_trigger}"
 }
 `,
 		os.Getenv("VSPHERE_DATACENTER"),
@@ -209,5 +303,6 @@ resource "vsphere_virtual_machine_snapshot" "snapshot" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		enabled,
+		trigger,
 	)
 }
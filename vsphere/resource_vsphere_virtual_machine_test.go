@@ -6,13 +6,17 @@ import (
 	"net"
 	"os"
 	"path"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -21,6 +25,7 @@ const (
 	testAccResourceVSphereVirtualMachineDiskNameLazy      = "terraform-test-extra-lazy"
 	testAccResourceVSphereVirtualMachineDiskNameThin      = "terraform-test-extra-thin"
 	testAccResourceVSphereVirtualMachineDiskNameExtraVmdk = "terraform-test-vm-extra-disk.vmdk"
+	testAccResourceVSphereVirtualMachineDiskNameGrow      = "terraform-test-extra-grow"
 	testAccResourceVSphereVirtualMachineStaticMacAddr     = "06:5c:89:2b:a0:64"
 	testAccResourceVSphereVirtualMachineAnnotation        = "Managed by Terraform"
 )
@@ -201,6 +206,245 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				},
 			},
 		},
+		{
+			"rename",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "name", "terraform-test"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigRenamed(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckIDIsUUID(),
+							testAccResourceVSphereVirtualMachineCheckName("terraform-test-renamed"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"move to folder",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckFolder(""),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigInFolder(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckIDIsUUID(),
+							testAccResourceVSphereVirtualMachineCheckFolder("terraform-test-vms"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"grow disk",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(1),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckDiskSize(testAccResourceVSphereVirtualMachineDiskNameGrow, 1),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigGrowDisk(2),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckDiskSize(testAccResourceVSphereVirtualMachineDiskNameGrow, 2),
+						),
+					},
+					{
+						Config:      testAccResourceVSphereVirtualMachineConfigGrowDisk(1),
+						ExpectError: regexp.MustCompile("only increases in size are supported"),
+					},
+				},
+			},
+		},
+		{
+			"vapp properties",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheckVApp(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigVApp(os.Getenv("VSPHERE_VAPP_PROPERTY"), "terraform-test-one"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckVAppProperty(os.Getenv("VSPHERE_VAPP_PROPERTY"), "terraform-test-one"),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigVApp(os.Getenv("VSPHERE_VAPP_PROPERTY"), "terraform-test-two"),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckVAppProperty(os.Getenv("VSPHERE_VAPP_PROPERTY"), "terraform-test-two"),
+						),
+					},
+					{
+						Config:      testAccResourceVSphereVirtualMachineConfigVApp("terraform.test.undefined", "terraform-test-two"),
+						ExpectError: regexp.MustCompile("is not defined"),
+					},
+				},
+			},
+		},
+		{
+			"cloud-init",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigCloudInit(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckExtraConfig("guestinfo.metadata.encoding", cloudInitEncodingGzipBase64),
+							testAccResourceVSphereVirtualMachineCheckExtraConfig("guestinfo.userdata.encoding", cloudInitEncodingGzipBase64),
+						),
+					},
+				},
+			},
+		},
+		{
+			"stored customization spec",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccSkipIfEsxi(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigCustomizationSpec(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "customization_spec_name", "terraform-test-vm-spec"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"provider customization defaults",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigCustomizationDefaults(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "domain", "terraform.example.com"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "time_zone", "America/Toronto"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"storage vmotion",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheckStorageVMotion(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckDatastore(os.Getenv("VSPHERE_DATASTORE")),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigStorageVMotion(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckIDIsUUID(),
+							testAccResourceVSphereVirtualMachineCheckDatastore(os.Getenv("VSPHERE_DATASTORE2")),
+						),
+					},
+				},
+			},
+		},
+		{
+			"vmotion to resource pool",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheckResourcePoolVMotion(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckIDIsUUID(),
+							testAccResourceVSphereVirtualMachineCheckResourcePool("vsphere_resource_pool.resource_pool"),
+						),
+					},
+				},
+			},
+		},
 		{
 			"attach existing vmdk",
 			resource.TestCase{
@@ -272,6 +516,28 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				},
 			},
 		},
+		{
+			"dual-stack, multiple ipv6 addresses with slaac",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigMultipleIPv6(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckNet("fd00::2", "32", "fd00::1"),
+							testAccResourceVSphereVirtualMachineCheckNet("fd00::3", "32", "fd00::1"),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.0.ipv6_mode", "slaac"),
+						),
+					},
+				},
+			},
+		},
 		{
 			"static mac",
 			resource.TestCase{
@@ -339,6 +605,43 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				},
 			},
 		},
+		{
+			"windows template, sysprep options",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigWindowsSysprepOptions(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded(),
+						),
+					},
+				},
+			},
+		},
+		{
+			"windows template, invalid time zone",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config:      strings.Replace(testAccResourceVSphereVirtualMachineConfigWindowsSysprepOptions(), `"035"`, `"America/New_York"`, 1),
+						ExpectError: regexp.MustCompile("must be a numeric Microsoft time zone index"),
+					},
+				},
+			},
+		},
 		{
 			"dhcp only, don't wait for guest net",
 			resource.TestCase{
@@ -426,18 +729,177 @@ func TestAccResourceVSphereVirtualMachine(t *testing.T) {
 				},
 			},
 		},
-	}
-
-	for _, tc := range testAccResourceVSphereVirtualMachineCases {
-		t.Run(tc.name, func(t *testing.T) {
-			tp = t
-			resource.Test(t, tc.testCase)
-		})
-	}
-}
-
-func testAccResourceVSphereVirtualMachinePreCheck(t *testing.T) {
-	// Note that VSPHERE_USE_LINKED_CLONE is also a variable and its presence
+		{
+			"distributed port group",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheckDVPortgroup(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigDVPortgroup(false),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckDVPortgroupBacking(),
+							resource.TestCheckResourceAttr("vsphere_virtual_machine.vm", "network_interface.0.label", "terraform-test-pg"),
+						),
+					},
+				},
+			},
+		},
+		{
+			"distributed port group by ID",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheckDVPortgroup(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigDVPortgroup(true),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							testAccResourceVSphereVirtualMachineCheckDVPortgroupBacking(),
+							resource.TestCheckResourceAttrPair(
+								"vsphere_virtual_machine.vm", "network_interface.0.label",
+								"vsphere_distributed_port_group.pg", "id",
+							),
+						),
+					},
+				},
+			},
+		},
+		{
+			"datastore cluster",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheckDatastoreCluster(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigDatastoreCluster(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+							resource.TestCheckResourceAttrPair(
+								"vsphere_virtual_machine.vm", "datastore_id",
+								"vsphere_nas_datastore.datastore", "id",
+							),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+						),
+					},
+					{
+						ResourceName:      "vsphere_virtual_machine.vm",
+						ImportState:       true,
+						ImportStateVerify: true,
+						ImportStateVerifyIgnore: []string{
+							"cluster",
+							"datacenter",
+							"disk",
+							"domain",
+							"resource_pool",
+							"time_zone",
+						},
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							props, err := testGetVirtualMachineProperties(s, "vm")
+							if err != nil {
+								return "", err
+							}
+							return props.Config.Uuid, nil
+						},
+						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+						),
+					},
+				},
+			},
+		},
+		{
+			"import by path",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVirtualMachinePreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVirtualMachineCheckExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVirtualMachineCheckExists(true),
+						),
+					},
+					{
+						ResourceName: "vsphere_virtual_machine.vm",
+						ImportState:  true,
+						ImportStateIdFunc: func(s *terraform.State) (string, error) {
+							vm, err := testGetVirtualMachine(s, "vm")
+							if err != nil {
+								return "", err
+							}
+							return vm.InventoryPath, nil
+						},
+						ImportStateCheck: func(s []*terraform.InstanceState) error {
+							if len(s) != 1 {
+								return fmt.Errorf("expected 1 imported resource, got %d", len(s))
+							}
+							if s[0].Attributes["disk.#"] == "0" {
+								return errors.New("expected imported virtual machine to have disks")
+							}
+							for k, v := range s[0].Attributes {
+								if strings.HasSuffix(k, ".keep_on_remove") && v != "true" {
+									return fmt.Errorf("expected imported disk attribute %s to be true", k)
+								}
+							}
+							return nil
+						},
+						Config: testAccResourceVSphereVirtualMachineConfigBasic(),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereVirtualMachineCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereVirtualMachinePreCheck(t *testing.T) {
+	// Note that VSPHERE_USE_LINKED_CLONE is also a variable and its presence
 	// speeds up tests greatly, but it's not a necessary variable, so we don't
 	// enforce it here.
 	if os.Getenv("VSPHERE_DATACENTER") == "" {
@@ -472,6 +934,63 @@ func testAccResourceVSphereVirtualMachinePreCheck(t *testing.T) {
 	}
 }
 
+// testAccResourceVSphereVirtualMachinePreCheckDatastoreCluster checks for the
+// additional variables required to run the datastore cluster tests. The
+// datastore cluster is backed by a NAS datastore, so the NAS datastore
+// variables are required.
+func testAccResourceVSphereVirtualMachinePreCheckDatastoreCluster(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_virtual_machine datastore cluster acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NAS_HOST") == "" {
+		t.Skip("set VSPHERE_NAS_HOST to run vsphere_virtual_machine datastore cluster acceptance tests")
+	}
+	if os.Getenv("VSPHERE_NFS_PATH") == "" {
+		t.Skip("set VSPHERE_NFS_PATH to run vsphere_virtual_machine datastore cluster acceptance tests")
+	}
+}
+
+// testAccResourceVSphereVirtualMachinePreCheckStorageVMotion checks for the
+// additional variables required to run the storage vMotion tests.
+func testAccResourceVSphereVirtualMachinePreCheckStorageVMotion(t *testing.T) {
+	if os.Getenv("VSPHERE_DATASTORE2") == "" {
+		t.Skip("set VSPHERE_DATASTORE2 to run vsphere_virtual_machine storage vMotion acceptance tests")
+	}
+}
+
+// testAccResourceVSphereVirtualMachinePreCheckVApp checks for the additional
+// variables required to run the vApp property tests.
+func testAccResourceVSphereVirtualMachinePreCheckVApp(t *testing.T) {
+	if os.Getenv("VSPHERE_TEMPLATE_VAPP") == "" {
+		t.Skip("set VSPHERE_TEMPLATE_VAPP to run vsphere_virtual_machine vApp property acceptance tests")
+	}
+	if os.Getenv("VSPHERE_VAPP_PROPERTY") == "" {
+		t.Skip("set VSPHERE_VAPP_PROPERTY to run vsphere_virtual_machine vApp property acceptance tests")
+	}
+}
+
+// testAccResourceVSphereVirtualMachinePreCheckResourcePoolVMotion checks for
+// the additional variables required to run the resource pool vMotion tests.
+func testAccResourceVSphereVirtualMachinePreCheckResourcePoolVMotion(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_virtual_machine resource pool vMotion acceptance tests")
+	}
+}
+
+// testAccResourceVSphereVirtualMachinePreCheckDVPortgroup checks for the
+// additional variables required to run the distributed port group tests.
+func testAccResourceVSphereVirtualMachinePreCheckDVPortgroup(t *testing.T) {
+	testAccSkipIfEsxi(t)
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_virtual_machine distributed port group acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC1") == "" {
+		t.Skip("set VSPHERE_HOST_NIC1 to run vsphere_virtual_machine distributed port group acceptance tests")
+	}
+}
+
 func testAccResourceVSphereVirtualMachineCheckExists(expected bool) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		_, err := testGetVirtualMachine(s, "vm")
@@ -591,6 +1110,143 @@ func testAccResourceVSphereVirtualMachineCheckFolder(expected string) resource.T
 	}
 }
 
+// testAccResourceVSphereVirtualMachineCheckName checks to make sure a
+// virtual machine's name matches the name supplied with expected.
+func testAccResourceVSphereVirtualMachineCheckName(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		if props.Name != expected {
+			return fmt.Errorf("expected name to be %s, got %s", expected, props.Name)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckIDIsUUID checks to make sure that
+// the ID of the virtual machine resource is its UUID.
+func testAccResourceVSphereVirtualMachineCheckIDIsUUID() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tVars, err := testClientVariablesForResource(s, "vsphere_virtual_machine.vm")
+		if err != nil {
+			return err
+		}
+		if tVars.resourceID != tVars.resourceAttributes["uuid"] {
+			return fmt.Errorf("expected ID to be %s, got %s", tVars.resourceAttributes["uuid"], tVars.resourceID)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDiskSize checks to make sure the
+// disk with the supplied name has the expected size, in GB.
+func testAccResourceVSphereVirtualMachineCheckDiskSize(name string, expected int64) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, dev := range props.Config.Hardware.Device {
+			disk, ok := dev.(*types.VirtualDisk)
+			if !ok {
+				continue
+			}
+			backing, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+			if !ok || !strings.HasSuffix(backing.FileName, "/"+name+".vmdk") {
+				continue
+			}
+			if actual := disk.CapacityInKB / 1024 / 1024; actual != expected {
+				return fmt.Errorf("expected disk %s to be %d GB, got %d GB", name, expected, actual)
+			}
+			return nil
+		}
+		return fmt.Errorf("could not find disk %s", name)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckExtraConfig checks to make sure
+// the extraConfig option with the supplied key is set to expected.
+func testAccResourceVSphereVirtualMachineCheckExtraConfig(key, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, v := range props.Config.ExtraConfig {
+			ov := v.GetOptionValue()
+			if ov.Key != key {
+				continue
+			}
+			if actual := fmt.Sprintf("%v", ov.Value); actual != expected {
+				return fmt.Errorf("expected extraConfig %q to be %q, got %q", key, expected, actual)
+			}
+			return nil
+		}
+		return fmt.Errorf("could not find extraConfig %q", key)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckVAppProperty checks to make sure
+// the vApp property with the supplied ID is set to expected.
+func testAccResourceVSphereVirtualMachineCheckVAppProperty(id, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		for _, p := range vAppConfigProperties(props.Config.VAppConfig) {
+			if p.Id != id {
+				continue
+			}
+			if p.Value != expected {
+				return fmt.Errorf("expected vApp property %q to be %q, got %q", id, expected, p.Value)
+			}
+			return nil
+		}
+		return fmt.Errorf("could not find vApp property %q", id)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDatastore checks to make sure a
+// virtual machine's configuration lives on the datastore named by expected.
+func testAccResourceVSphereVirtualMachineCheckDatastore(expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		var dsPath object.DatastorePath
+		if !dsPath.FromString(props.Summary.Config.VmPathName) {
+			return fmt.Errorf("could not parse datastore path %q", props.Summary.Config.VmPathName)
+		}
+		if dsPath.Datastore != expected {
+			return fmt.Errorf("expected datastore to be %s, got %s", expected, dsPath.Datastore)
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckResourcePool checks to make sure a
+// virtual machine is in the resource pool of the supplied resource address.
+func testAccResourceVSphereVirtualMachineCheckResourcePool(resAddr string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		rs, ok := s.RootModule().Resources[resAddr]
+		if !ok {
+			return fmt.Errorf("%s not found in state", resAddr)
+		}
+		if props.ResourcePool == nil || props.ResourcePool.Value != rs.Primary.ID {
+			return fmt.Errorf("expected resource pool to be %s, got %v", rs.Primary.ID, props.ResourcePool)
+		}
+		return nil
+	}
+}
+
 // testAccResourceVSphereVirtualMachineCheckExistingVmdk is a check to make
 // sure that the appropriate disk is attached in the existing VMDK test.
 func testAccResourceVSphereVirtualMachineCheckExistingVmdk() resource.TestCheckFunc {
@@ -736,44 +1392,686 @@ func testAccResourceVSphereVirtualMachineCheckAnnotation() resource.TestCheckFun
 	}
 }
 
-// testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded is a check
-// to ensure that events have been received for customization success on a VM.
-func testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded() resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		client := testAccProvider.Meta().(*VSphereClient).vimClient
-		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeCustomizationSucceeded})
-		if err != nil {
-			return err
-		}
-		if len(actual) < 1 {
-			return errors.New("customization success event was not received")
-		}
-		return nil
-	}
+// testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded is a check
+// to ensure that events have been received for customization success on a VM.
+func testAccResourceVSphereVirtualMachineCheckCustomizationSucceeded() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		client := testAccProvider.Meta().(*VSphereClient).vimClient
+		actual, err := selectEventsForReference(client, vm.Reference(), []string{eventTypeCustomizationSucceeded})
+		if err != nil {
+			return err
+		}
+		if len(actual) < 1 {
+			return errors.New("customization success event was not received")
+		}
+		return nil
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckTags is a check to ensure that any
+// tags that have been created with supplied resource name have been attached
+// to the virtual machine.
+func testAccResourceVSphereVirtualMachineCheckTags(tagResName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		vm, err := testGetVirtualMachine(s, "vm")
+		if err != nil {
+			return err
+		}
+		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
+		if err != nil {
+			return err
+		}
+		return testObjectHasTags(s, tagsClient, vm, tagResName)
+	}
+}
+
+// testAccResourceVSphereVirtualMachineCheckDVPortgroupBacking checks to make
+// sure that the first network adapter of a VM is backed by the port group
+// created in the test configuration.
+func testAccResourceVSphereVirtualMachineCheckDVPortgroupBacking() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		props, err := testGetVirtualMachineProperties(s, "vm")
+		if err != nil {
+			return err
+		}
+		pg, err := testGetDVPortgroupProperties(s, "pg")
+		if err != nil {
+			return err
+		}
+		devices := object.VirtualDeviceList(props.Config.Hardware.Device).SelectByType((*types.VirtualEthernetCard)(nil))
+		if len(devices) < 1 {
+			return errors.New("no network adapters found on VM")
+		}
+		backing, ok := devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().Backing.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo)
+		if !ok {
+			return fmt.Errorf("unexpected network adapter backing type: %T", devices[0].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().Backing)
+		}
+		if backing.Port.PortgroupKey != pg.Key {
+			return fmt.Errorf("expected port group key to be %q, got %q", pg.Key, backing.Port.PortgroupKey)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVirtualMachineConfigBasic() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigRenamed() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test-renamed"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigStorageVMotion() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE2"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigResourcePoolVMotion() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_resource_pool" "resource_pool" {
+  name                    = "terraform-test-vm-pool"
+  parent_resource_pool_id = "${data.vsphere_host.esxi_host.resource_pool_id}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.cluster}/Resources/${vsphere_resource_pool.resource_pool.name}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigGrowDisk(size int) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "disk_name_grow" {
+  default = "%s"
+}
+
+variable "disk_size_grow" {
+  default = "%d"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  disk {
+    size = "${var.disk_size_grow}"
+    type = "thin"
+    name = "${var.disk_name_grow}"
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		testAccResourceVSphereVirtualMachineDiskNameGrow,
+		size,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigCloudInit() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  cloud_init {
+    metadata = "instance-id: terraform-test"
+    userdata = "#cloud-config\nhostname: terraform-test\n"
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigVApp(key, value string) string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "vapp_property_key" {
+  default = "%s"
 }
 
-// testAccResourceVSphereVirtualMachineCheckTags is a check to ensure that any
-// tags that have been created with supplied resource name have been attached
-// to the virtual machine.
-func testAccResourceVSphereVirtualMachineCheckTags(tagResName string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		vm, err := testGetVirtualMachine(s, "vm")
-		if err != nil {
-			return err
-		}
-		tagsClient, err := testAccProvider.Meta().(*VSphereClient).TagsClient()
-		if err != nil {
-			return err
-		}
-		return testObjectHasTags(s, tagsClient, vm, tagResName)
-	}
+variable "vapp_property_value" {
+  default = "%s"
 }
 
-func testAccResourceVSphereVirtualMachineConfigBasic() string {
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  vapp {
+    properties = "${map(var.vapp_property_key, var.vapp_property_value)}"
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE_VAPP"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		key,
+		value,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigCustomizationSpec() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -815,6 +2113,14 @@ variable "linked_clone" {
   default = "%s"
 }
 
+resource "vsphere_customization_spec" "spec" {
+  name = "terraform-test-vm-spec"
+
+  linux_options {
+    domain = "example.com"
+  }
+}
+
 resource "vsphere_virtual_machine" "vm" {
   name          = "terraform-test"
   datacenter    = "${var.datacenter}"
@@ -837,6 +2143,8 @@ resource "vsphere_virtual_machine" "vm" {
     iops      = 500
   }
 
+  customization_spec_name = "${vsphere_customization_spec.spec.name}"
+
   linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
 }
 `,
@@ -853,6 +2161,22 @@ resource "vsphere_virtual_machine" "vm" {
 	)
 }
 
+func testAccResourceVSphereVirtualMachineConfigCustomizationDefaults() string {
+	return fmt.Sprintf(`
+provider "vsphere" {
+  customization_defaults {
+    dns_servers  = ["%s"]
+    dns_suffixes = ["terraform.example.com"]
+    domain       = "terraform.example.com"
+    time_zone    = "America/Toronto"
+  }
+}
+%s`,
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		testAccResourceVSphereVirtualMachineConfigBasic(),
+	)
+}
+
 func testAccResourceVSphereVirtualMachineConfigBeefy() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -1460,9 +2784,177 @@ resource "vsphere_virtual_machine" "vm" {
     ipv4_address       = "${var.ipv4_address}"
     ipv4_prefix_length = "${var.ipv4_prefix}"
     ipv4_gateway       = "${var.ipv4_gateway}"
-    ipv6_address       = "fd00::2"
-    ipv6_prefix_length = "32"
-    ipv6_gateway       = "fd00::1"
+    ipv6_address       = "fd00::2"
+    ipv6_prefix_length = "32"
+    ipv6_gateway       = "fd00::1"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigMultipleIPv6() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
+    ipv6_addresses     = ["fd00::2/32", "fd00::3/32"]
+    ipv6_gateway       = "fd00::1"
+    ipv6_mode          = "slaac"
+  }
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_IPV4_PREFIX"),
+		os.Getenv("VSPHERE_IPV4_GATEWAY"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigStaticMAC() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "ipv4_address" {
+  default = "%s"
+}
+
+variable "ipv4_prefix" {
+  default = "%s"
+}
+
+variable "ipv4_gateway" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+variable "static_mac_addr" {
+  default = "%s"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label              = "${var.network_label}"
+    mac_address        = "${var.static_mac_addr}"
+    ipv4_address       = "${var.ipv4_address}"
+    ipv4_prefix_length = "${var.ipv4_prefix}"
+    ipv4_gateway       = "${var.ipv4_gateway}"
   }
 
   disk {
@@ -1484,10 +2976,11 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		testAccResourceVSphereVirtualMachineStaticMacAddr,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigStaticMAC() string {
+func testAccResourceVSphereVirtualMachineConfigWithAnnotation() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -1529,8 +3022,8 @@ variable "linked_clone" {
   default = "%s"
 }
 
-variable "static_mac_addr" {
-  default = "%s"
+variable "annotation" {
+	default = "%s"
 }
 
 resource "vsphere_virtual_machine" "vm" {
@@ -1538,13 +3031,13 @@ resource "vsphere_virtual_machine" "vm" {
   datacenter    = "${var.datacenter}"
   cluster       = "${var.cluster}"
   resource_pool = "${var.resource_pool}"
+  annotation    = "${var.annotation}"
 
   vcpu   = 2
   memory = 1024
 
   network_interface {
     label              = "${var.network_label}"
-    mac_address        = "${var.static_mac_addr}"
     ipv4_address       = "${var.ipv4_address}"
     ipv4_prefix_length = "${var.ipv4_prefix}"
     ipv4_gateway       = "${var.ipv4_gateway}"
@@ -1569,11 +3062,11 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_DATASTORE"),
 		os.Getenv("VSPHERE_TEMPLATE"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
-		testAccResourceVSphereVirtualMachineStaticMacAddr,
+		testAccResourceVSphereVirtualMachineAnnotation,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigWithAnnotation() string {
+func testAccResourceVSphereVirtualMachineConfigWindows() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -1615,19 +3108,14 @@ variable "linked_clone" {
   default = "%s"
 }
 
-variable "annotation" {
-	default = "%s"
-}
-
 resource "vsphere_virtual_machine" "vm" {
   name          = "terraform-test"
   datacenter    = "${var.datacenter}"
   cluster       = "${var.cluster}"
   resource_pool = "${var.resource_pool}"
-  annotation    = "${var.annotation}"
 
-  vcpu   = 2
-  memory = 1024
+  vcpu   = 4
+  memory = 4096
 
   network_interface {
     label              = "${var.network_label}"
@@ -1642,6 +3130,10 @@ resource "vsphere_virtual_machine" "vm" {
     iops      = 500
   }
 
+  windows_opt_config {
+    admin_password = "VMw4re"
+  }
+
   linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
 }
 `,
@@ -1653,13 +3145,12 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_IPV4_PREFIX"),
 		os.Getenv("VSPHERE_IPV4_GATEWAY"),
 		os.Getenv("VSPHERE_DATASTORE"),
-		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_TEMPLATE_WINDOWS"),
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
-		testAccResourceVSphereVirtualMachineAnnotation,
 	)
 }
 
-func testAccResourceVSphereVirtualMachineConfigWindows() string {
+func testAccResourceVSphereVirtualMachineConfigWindowsSysprepOptions() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
   default = "%s"
@@ -1723,8 +3214,16 @@ resource "vsphere_virtual_machine" "vm" {
     iops      = 500
   }
 
+  time_zone = "035"
+
   windows_opt_config {
-    admin_password = "VMw4re"
+    admin_password        = "VMw4re"
+    full_name             = "Terraform Test"
+    organization_name     = "HashiCorp"
+    workgroup             = "TERRAFORM"
+    auto_logon            = true
+    auto_logon_count      = 2
+    run_once_command_list = ["cmd.exe /c echo terraform > C:\\terraform.txt"]
   }
 
   linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
@@ -1742,7 +3241,6 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
-
 func testAccResourceVSphereVirtualMachineConfigDHCPNoWait() string {
 	return fmt.Sprintf(`
 variable "datacenter" {
@@ -2013,3 +3511,425 @@ resource "vsphere_virtual_machine" "vm" {
 		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
 	)
 }
+
+func testAccResourceVSphereVirtualMachineConfigDVPortgroup(useID bool) string {
+	label := "${vsphere_distributed_port_group.pg.name}"
+	if useID {
+		label = "${vsphere_distributed_port_group.pg.id}"
+	}
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "network_interface" {
+  default = "%s"
+}
+
+variable "datastore" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "linked_clone" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+
+  host {
+    host_system_id = "${data.vsphere_host.host.id}"
+    devices        = ["${var.network_interface}"]
+  }
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.uuid}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name          = "terraform-test"
+  datacenter    = "${var.datacenter}"
+  cluster       = "${var.cluster}"
+  resource_pool = "${var.resource_pool}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label = "%s"
+  }
+
+  wait_for_guest_net = false
+
+  disk {
+    datastore = "${var.datastore}"
+    template  = "${var.template}"
+    iops      = 500
+  }
+
+  linked_clone = "${var.linked_clone != "" ? "true" : "false" }"
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_HOST_NIC1"),
+		os.Getenv("VSPHERE_DATASTORE"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_USE_LINKED_CLONE"),
+		label,
+	)
+}
+
+func testAccResourceVSphereVirtualMachineConfigDatastoreCluster() string {
+	return fmt.Sprintf(`
+variable "datacenter" {
+  default = "%s"
+}
+
+variable "cluster" {
+  default = "%s"
+}
+
+variable "resource_pool" {
+  default = "%s"
+}
+
+variable "network_label" {
+  default = "%s"
+}
+
+variable "template" {
+  default = "%s"
+}
+
+variable "esxi_host" {
+  default = "%s"
+}
+
+variable "nfs_host" {
+  default = "%s"
+}
+
+variable "nfs_path" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "dc" {
+  name = "${var.datacenter}"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "${var.esxi_host}"
+  datacenter_id = "${data.vsphere_datacenter.dc.id}"
+}
+
+resource "vsphere_datastore_cluster" "datastore_cluster" {
+  name                  = "terraform-datastore-cluster-test"
+  datacenter_id         = "${data.vsphere_datacenter.dc.id}"
+  sdrs_automation_level = "automated"
+}
+
+resource "vsphere_nas_datastore" "datastore" {
+  name                 = "terraform-test-nas"
+  host_system_ids      = ["${data.vsphere_host.esxi_host.id}"]
+  datastore_cluster_id = "${vsphere_datastore_cluster.datastore_cluster.id}"
+
+  type         = "NFS"
+  remote_hosts = ["${var.nfs_host}"]
+  remote_path  = "${var.nfs_path}"
+}
+
+resource "vsphere_virtual_machine" "vm" {
+  name                 = "terraform-test"
+  datacenter           = "${var.datacenter}"
+  cluster              = "${var.cluster}"
+  resource_pool        = "${var.resource_pool}"
+  datastore_cluster_id = "${vsphere_nas_datastore.datastore.datastore_cluster_id}"
+
+  vcpu   = 2
+  memory = 1024
+
+  network_interface {
+    label = "${var.network_label}"
+  }
+
+  disk {
+    template = "${var.template}"
+  }
+
+  skip_customization = true
+  wait_for_guest_net = false
+}
+`,
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_CLUSTER"),
+		os.Getenv("VSPHERE_RESOURCE_POOL"),
+		os.Getenv("VSPHERE_NETWORK_LABEL"),
+		os.Getenv("VSPHERE_TEMPLATE"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+		os.Getenv("VSPHERE_NAS_HOST"),
+		os.Getenv("VSPHERE_NFS_PATH"),
+	)
+}
+
+func testVirtualMachineDisk(key int32, datastore string) *types.VirtualDisk {
+	ds := types.ManagedObjectReference{Type: "Datastore", Value: datastore}
+	return &types.VirtualDisk{
+		VirtualDevice: types.VirtualDevice{
+			Key: key,
+			Backing: &types.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{
+					Datastore: &ds,
+				},
+			},
+		},
+	}
+}
+
+type testExpandVMInPlaceRelocateSpec struct {
+	Name string
+
+	changes    []virtualMachineDiskChange
+	datastores map[int]types.ManagedObjectReference
+	expected   types.VirtualMachineRelocateSpec
+}
+
+func (tc *testExpandVMInPlaceRelocateSpec) Test(t *testing.T) {
+	pool := types.ManagedObjectReference{Type: "ResourcePool", Value: "resgroup-1"}
+	devices := object.VirtualDeviceList{
+		testVirtualMachineDisk(2000, "datastore-1"),
+		testVirtualMachineDisk(2001, "datastore-1"),
+	}
+	actual, err := expandVMInPlaceRelocateSpec(pool, devices, tc.changes, tc.datastores, map[int32]bool{2001: true})
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	tc.expected.Pool = &pool
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestExpandVMInPlaceRelocateSpec(t *testing.T) {
+	ds2 := types.ManagedObjectReference{Type: "Datastore", Value: "datastore-2"}
+	cases := []testExpandVMInPlaceRelocateSpec{
+		{
+			Name: "compute only",
+			expected: types.VirtualMachineRelocateSpec{
+				DiskMoveType: "moveAllDiskBackingsAndAllowSharing",
+			},
+		},
+		{
+			Name: "template disk moved, other disks pinned",
+			changes: []virtualMachineDiskChange{
+				{
+					old: map[string]interface{}{"key": 0, "template": "tpl", "datastore": "ds1"},
+					new: map[string]interface{}{"key": 0, "template": "tpl", "datastore": "ds2"},
+				},
+			},
+			datastores: map[int]types.ManagedObjectReference{0: ds2},
+			expected: types.VirtualMachineRelocateSpec{
+				Datastore:    &ds2,
+				DiskMoveType: "moveAllDiskBackingsAndAllowSharing",
+				Disk: []types.VirtualMachineRelocateSpecDiskLocator{
+					{DiskId: 2000, Datastore: ds2},
+					{DiskId: 2001, Datastore: types.ManagedObjectReference{Type: "Datastore", Value: "datastore-1"}},
+				},
+			},
+		},
+		{
+			Name: "extra disk moved",
+			changes: []virtualMachineDiskChange{
+				{
+					old: map[string]interface{}{"key": 2001, "template": "", "datastore": "ds1"},
+					new: map[string]interface{}{"key": 2001, "template": "", "datastore": "ds2"},
+				},
+			},
+			datastores: map[int]types.ManagedObjectReference{0: ds2},
+			expected: types.VirtualMachineRelocateSpec{
+				DiskMoveType: "moveAllDiskBackingsAndAllowSharing",
+				Disk: []types.VirtualMachineRelocateSpecDiskLocator{
+					{DiskId: 2001, Datastore: ds2},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestFlattenImportedExtraConfig(t *testing.T) {
+	extraConfig := []types.BaseOptionValue{
+		&types.OptionValue{Key: "disk.EnableUUID", Value: "TRUE"},
+		&types.OptionValue{Key: "disk.enableUUID", Value: "TRUE"},
+		&types.OptionValue{Key: "uuid.bios", Value: "42 1c 6e 29 a7 5e 2a 4b-b0 7d 52 e1 0c 8b 6a 05"},
+		&types.OptionValue{Key: "vc.uuid", Value: "50 1c 1f 3c 08 c5 3b d7-8b 6d 2e a4 e7 7f 15 0c"},
+		&types.OptionValue{Key: "guestinfo.metadata", Value: "e30K"},
+		&types.OptionValue{Key: "scsi0:0.redo", Value: ""},
+		&types.OptionValue{Key: "tools.guest.desktop.autolock", Value: "FALSE"},
+		&types.OptionValue{Key: "isolation.tools.copy.disable", Value: "TRUE"},
+	}
+	expected := map[string]interface{}{
+		"isolation.tools.copy.disable": "TRUE",
+	}
+	if actual := flattenImportedExtraConfig(extraConfig); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestValidateVirtualMachineDiskChangesKeepsState(t *testing.T) {
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"disk": resourceVSphereVirtualMachine().Schema["disk"],
+		},
+		Update: func(d *schema.ResourceData, meta interface{}) error {
+			return validateVirtualMachineDiskChanges(d)
+		},
+	}
+	disk := func(size int) map[string]interface{} {
+		return map[string]interface{}{
+			"name":            "terraform-test-extra",
+			"datastore":       "datastore1",
+			"size":            size,
+			"type":            "eager_zeroed",
+			"controller_type": "scsi",
+		}
+	}
+
+	d := r.Data(nil)
+	d.SetId("42")
+	if err := d.Set("disk", []interface{}{disk(10)}); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	state := d.State()
+
+	raw, err := config.NewRawConfig(map[string]interface{}{
+		"disk": []interface{}{disk(5)},
+	})
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	diff, err := r.Diff(state, terraform.NewResourceConfig(raw))
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+
+	newState, err := r.Apply(state, diff, nil)
+	testMatchError(t, err, regexp.MustCompile("cannot be shrunk from 10 GB to 5 GB"))
+	disks := r.Data(newState).Get("disk").(*schema.Set).List()
+	if len(disks) != 1 {
+		t.Fatalf("expected 1 disk in state, got %d", len(disks))
+	}
+	if actual := disks[0].(map[string]interface{})["size"].(int); actual != 10 {
+		t.Fatalf("expected disk size in state to be 10, got %d", actual)
+	}
+}
+
+type testVirtualMachineRelocationTarget struct {
+	Name string
+
+	config          map[string]interface{}
+	expectedCluster string
+	expectedPool    string
+}
+
+func (tc *testVirtualMachineRelocationTarget) Test(t *testing.T) {
+	vmSchema := resourceVSphereVirtualMachine().Schema
+	var cluster, pool string
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"cluster":       vmSchema["cluster"],
+			"resource_pool": vmSchema["resource_pool"],
+		},
+		Update: func(d *schema.ResourceData, meta interface{}) error {
+			cluster, pool = virtualMachineRelocationTarget(d)
+			return nil
+		},
+	}
+
+	d := r.Data(nil)
+	d.SetId("42")
+	d.Set("cluster", "cluster1")
+	d.Set("resource_pool", "cluster1/Resources/pool1")
+	state := d.State()
+
+	raw, err := config.NewRawConfig(tc.config)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	diff, err := r.Diff(state, terraform.NewResourceConfig(raw))
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if _, err := r.Apply(state, diff, nil); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if cluster != tc.expectedCluster || pool != tc.expectedPool {
+		t.Fatalf("expected cluster %q and resource pool %q, got %q and %q", tc.expectedCluster, tc.expectedPool, cluster, pool)
+	}
+}
+
+func TestVirtualMachineRelocationTarget(t *testing.T) {
+	cases := []testVirtualMachineRelocationTarget{
+		{
+			Name: "cluster only",
+			config: map[string]interface{}{
+				"cluster": "cluster2",
+			},
+			expectedCluster: "cluster2",
+		},
+		{
+			Name: "cluster and resource pool",
+			config: map[string]interface{}{
+				"cluster":       "cluster2",
+				"resource_pool": "cluster2/Resources/pool2",
+			},
+			expectedCluster: "cluster2",
+			expectedPool:    "cluster2/Resources/pool2",
+		},
+		{
+			Name: "resource pool only",
+			config: map[string]interface{}{
+				"resource_pool": "cluster1/Resources/pool2",
+			},
+			expectedCluster: "cluster1",
+			expectedPool:    "cluster1/Resources/pool2",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
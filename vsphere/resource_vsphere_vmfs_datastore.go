@@ -33,6 +33,18 @@ Additionally, there was an error removing the created datastore:
 You will need to remove this datastore manually before trying again.
 `
 
+// formatVmfsDatastoreCreateRollbackErrorDatastoreCluster defines the verbose
+// error for moving a datastore to a datastore cluster on creation where
+// rollback was not possible.
+const formatVmfsDatastoreCreateRollbackErrorDatastoreCluster = `
+WARNING: Dangling resource!
+There was an error moving your datastore to the desired datastore cluster %q:
+%s
+Additionally, there was an error removing the created datastore:
+%s
+You will need to remove this datastore manually before trying again.
+`
+
 // formatVmfsDatastoreCreateRollbackErrorUpdate defines the verbose error for extending a
 // disk on creation where rollback is not possible.
 const formatVmfsDatastoreCreateRollbackErrorUpdate = `
@@ -69,10 +81,17 @@ func resourceVSphereVmfsDatastore() *schema.Resource {
 			Required:    true,
 		},
 		"folder": &schema.Schema{
-			Type:        schema.TypeString,
-			Description: "The path to the datastore folder to put the datastore in.",
-			Optional:    true,
-			StateFunc:   normalizeFolderPath,
+			Type:          schema.TypeString,
+			Description:   "The path to the datastore folder to put the datastore in.",
+			Optional:      true,
+			StateFunc:     normalizeFolderPath,
+			ConflictsWith: []string{"datastore_cluster_id"},
+		},
+		"datastore_cluster_id": &schema.Schema{
+			Type:          schema.TypeString,
+			Description:   "The managed object ID of the datastore cluster to place the datastore in.",
+			Optional:      true,
+			ConflictsWith: []string{"folder"},
 		},
 		"disks": &schema.Schema{
 			Type:        schema.TypeList,
@@ -146,6 +165,19 @@ func resourceVSphereVmfsDatastoreCreate(d *schema.ResourceData, meta interface{}
 		}
 	}
 
+	// Move the datastore into the datastore cluster, if specified.
+	if podID := d.Get("datastore_cluster_id").(string); podID != "" {
+		if err := moveDatastoreToStoragePod(client, ds, podID); err != nil {
+			if remErr := removeDatastore(dss, ds); remErr != nil {
+				// We could not destroy the created datastore and there is now a dangling
+				// resource. We need to instruct the user to remove the datastore
+				// manually.
+				return fmt.Errorf(formatVmfsDatastoreCreateRollbackErrorDatastoreCluster, podID, err, remErr)
+			}
+			return fmt.Errorf("could not move datastore to datastore cluster %q: %s", podID, err)
+		}
+	}
+
 	// Apply any pending tags now
 	if tagsClient != nil {
 		if err := processTagDiff(tagsClient, d, ds); err != nil {
@@ -200,12 +232,20 @@ func resourceVSphereVmfsDatastoreRead(d *schema.ResourceData, meta interface{})
 		return err
 	}
 
-	// Set the folder
-	folder, err := rootPathParticleDatastore.SplitRelativeFolder(ds.InventoryPath)
-	if err != nil {
-		return fmt.Errorf("error parsing datastore path %q: %s", ds.InventoryPath, err)
+	// Set the folder or datastore cluster. A datastore that is a member of a
+	// datastore cluster has the cluster as its parent, and the folder is left
+	// blank as the two options conflict.
+	if props.Parent != nil && props.Parent.Type == "StoragePod" {
+		d.Set("datastore_cluster_id", props.Parent.Value)
+		d.Set("folder", "")
+	} else {
+		folder, err := rootPathParticleDatastore.SplitRelativeFolder(ds.InventoryPath)
+		if err != nil {
+			return fmt.Errorf("error parsing datastore path %q: %s", ds.InventoryPath, err)
+		}
+		d.Set("datastore_cluster_id", "")
+		d.Set("folder", normalizeFolderPath(folder))
 	}
-	d.Set("folder", normalizeFolderPath(folder))
 
 	// We also need to update the disk list from the summary.
 	var disks []string
@@ -255,11 +295,17 @@ func resourceVSphereVmfsDatastoreUpdate(d *schema.ResourceData, meta interface{}
 		}
 	}
 
-	// Update folder if necessary
-	if d.HasChange("folder") {
-		folder := d.Get("folder").(string)
-		if err := moveDatastoreToFolder(client, ds, folder); err != nil {
-			return fmt.Errorf("Could not move datastore to folder %q: %s", folder, err)
+	// Update folder or datastore cluster if necessary
+	if d.HasChange("folder") || d.HasChange("datastore_cluster_id") {
+		if podID := d.Get("datastore_cluster_id").(string); podID != "" {
+			if err := moveDatastoreToStoragePod(client, ds, podID); err != nil {
+				return fmt.Errorf("Could not move datastore to datastore cluster %q: %s", podID, err)
+			}
+		} else {
+			folder := d.Get("folder").(string)
+			if err := moveDatastoreToFolder(client, ds, folder); err != nil {
+				return fmt.Errorf("Could not move datastore to folder %q: %s", folder, err)
+			}
 		}
 	}
 
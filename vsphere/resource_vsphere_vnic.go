@@ -0,0 +1,217 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func resourceVSphereVNic() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"host_system_id": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the host to set the VMkernel network adapter up on.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"device": &schema.Schema{
+			Type:        schema.TypeString,
+			Description: "The device name of the VMkernel network adapter, ie: vmk1.",
+			Computed:    true,
+		},
+		"services": &schema.Schema{
+			Type:        schema.TypeSet,
+			Description: "The services to enable on this VMkernel network adapter. Can be any of vmotion, faultToleranceLogging, vSphereReplication, vSphereReplicationNFC, management, vsan, or vSphereProvisioning.",
+			Optional:    true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(hostVirtualNicManagerNicTypeAllowedValues, false),
+			},
+		},
+	}
+	mergeSchema(s, schemaHostVirtualNicSpec())
+
+	return &schema.Resource{
+		Create: resourceVSphereVNicCreate,
+		Read:   resourceVSphereVNicRead,
+		Update: resourceVSphereVNicUpdate,
+		Delete: resourceVSphereVNicDelete,
+		Schema: s,
+	}
+}
+
+func resourceVSphereVNicCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+	portgroup := d.Get("portgroup").(string)
+	dvpgID := d.Get("distributed_port_group").(string)
+	if portgroup == "" && dvpgID == "" {
+		return errors.New("one of portgroup or distributed_port_group must be specified")
+	}
+
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	spec, err := expandHostVirtualNicSpec(d)
+	if err != nil {
+		return err
+	}
+	if dvpgID != "" {
+		conn, err := dvPortgroupPortConnection(client, dvpgID)
+		if err != nil {
+			return fmt.Errorf("error loading distributed port group: %s", err)
+		}
+		spec.DistributedVirtualPort = conn
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	device, err := ns.AddVirtualNic(ctx, portgroup, *spec)
+	if err != nil {
+		return fmt.Errorf("error adding VMkernel network adapter: %s", err)
+	}
+
+	saveHostVirtualNicID(d, hsID, device)
+
+	// Enable any requested services now that we have the device name.
+	if err := resourceVSphereVNicProcessServiceDiff(d, meta, device); err != nil {
+		return err
+	}
+
+	return resourceVSphereVNicRead(d, meta)
+}
+
+func resourceVSphereVNicRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := virtualNicIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	nic, err := hostVirtualNicFromDevice(client, ns, device)
+	if err != nil {
+		if isHostVirtualNicNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error fetching VMkernel network adapter data: %s", err)
+	}
+
+	d.Set("host_system_id", hsID)
+	d.Set("device", nic.Device)
+	d.Set("portgroup", nic.Portgroup)
+	if conn := nic.Spec.DistributedVirtualPort; conn != nil {
+		pg, err := dvPortgroupFromKey(client, conn.SwitchUuid, conn.PortgroupKey)
+		if err != nil {
+			return fmt.Errorf("error loading distributed port group: %s", err)
+		}
+		d.Set("distributed_port_group", pg.Reference().Value)
+	}
+
+	if err := flattenHostVirtualNicSpec(d, &nic.Spec); err != nil {
+		return fmt.Errorf("error setting resource data: %s", err)
+	}
+
+	vnm, err := hostVirtualNicManagerFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host virtual NIC manager: %s", err)
+	}
+	services, err := hostVirtualNicServices(vnm, device)
+	if err != nil {
+		return fmt.Errorf("error reading services for VMkernel network adapter: %s", err)
+	}
+	if err := d.Set("services", sliceStringsToInterfaces(services)); err != nil {
+		return fmt.Errorf("error setting services: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereVNicUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := virtualNicIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	spec, err := expandHostVirtualNicSpec(d)
+	if err != nil {
+		return err
+	}
+	// The port group and TCP/IP stack cannot be changed on an existing
+	// adapter, and are ForceNew, so we clear them from the update spec.
+	spec.Portgroup = ""
+	spec.NetStackInstanceKey = ""
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := ns.UpdateVirtualNic(ctx, device, *spec); err != nil {
+		return fmt.Errorf("error updating VMkernel network adapter: %s", err)
+	}
+
+	if err := resourceVSphereVNicProcessServiceDiff(d, meta, device); err != nil {
+		return err
+	}
+
+	return resourceVSphereVNicRead(d, meta)
+}
+
+func resourceVSphereVNicDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	hsID, device, err := virtualNicIDsFromResourceID(d)
+	if err != nil {
+		return err
+	}
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return fmt.Errorf("error loading host network system: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := ns.RemoveVirtualNic(ctx, device); err != nil {
+		return fmt.Errorf("error deleting VMkernel network adapter: %s", err)
+	}
+
+	return nil
+}
+
+// resourceVSphereVNicProcessServiceDiff enables and disables services on a
+// VMkernel network adapter based on the diff of the services attribute.
+func resourceVSphereVNicProcessServiceDiff(d *schema.ResourceData, meta interface{}, device string) error {
+	if !d.HasChange("services") {
+		return nil
+	}
+	client := meta.(*VSphereClient).vimClient
+	vnm, err := hostVirtualNicManagerFromHostSystemID(client, d.Get("host_system_id").(string))
+	if err != nil {
+		return fmt.Errorf("error loading host virtual NIC manager: %s", err)
+	}
+	o, n := d.GetChange("services")
+	oldServices := o.(*schema.Set)
+	newServices := n.(*schema.Set)
+	for _, service := range oldServices.Difference(newServices).List() {
+		if err := disableHostVirtualNicService(vnm, device, service.(string)); err != nil {
+			return fmt.Errorf("error disabling service %q on %s: %s", service, device, err)
+		}
+	}
+	for _, service := range newServices.Difference(oldServices).List() {
+		if err := enableHostVirtualNicService(vnm, device, service.(string)); err != nil {
+			return fmt.Errorf("error enabling service %q on %s: %s", service, device, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package vsphere
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccResourceVSphereVNic(t *testing.T) {
+	var tp *testing.T
+	testAccResourceVSphereVNicCases := []struct {
+		name     string
+		testCase resource.TestCase
+	}{
+		{
+			"standard port group, static IPv4",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVNicPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNicExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNicConfigStatic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckIPv4(false, os.Getenv("VSPHERE_VNIC_IPV4_ADDRESS")),
+						),
+					},
+				},
+			},
+		},
+		{
+			"standard port group, DHCP",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVNicPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNicExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNicConfigDHCP(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckIPv4(true, ""),
+						),
+					},
+				},
+			},
+		},
+		{
+			"static IPv4, then change MTU",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVNicPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNicExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNicConfigStatic(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVNicConfigMTU(9000),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckMTU(9000),
+						),
+					},
+				},
+			},
+		},
+		{
+			"distributed port group",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVNicPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNicExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNicConfigDVPortgroup(),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckDVPortgroup(),
+						),
+					},
+				},
+			},
+		},
+		{
+			"services",
+			resource.TestCase{
+				PreCheck: func() {
+					testAccPreCheck(tp)
+					testAccResourceVSphereVNicPreCheck(tp)
+				},
+				Providers:    testAccProviders,
+				CheckDestroy: testAccResourceVSphereVNicExists(false),
+				Steps: []resource.TestStep{
+					{
+						Config: testAccResourceVSphereVNicConfigServices([]string{"vmotion"}),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckServices([]string{"vmotion"}),
+						),
+					},
+					{
+						Config: testAccResourceVSphereVNicConfigServices([]string{"vSphereProvisioning", "vSphereReplication"}),
+						Check: resource.ComposeTestCheckFunc(
+							testAccResourceVSphereVNicExists(true),
+							testAccResourceVSphereVNicCheckServices([]string{"vSphereProvisioning", "vSphereReplication"}),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testAccResourceVSphereVNicCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp = t
+			resource.Test(t, tc.testCase)
+		})
+	}
+}
+
+func testAccResourceVSphereVNicPreCheck(t *testing.T) {
+	if os.Getenv("VSPHERE_HOST_NIC0") == "" {
+		t.Skip("set VSPHERE_HOST_NIC0 to run vsphere_vnic acceptance tests")
+	}
+	if os.Getenv("VSPHERE_HOST_NIC1") == "" {
+		t.Skip("set VSPHERE_HOST_NIC1 to run vsphere_vnic acceptance tests")
+	}
+	if os.Getenv("VSPHERE_ESXI_HOST") == "" {
+		t.Skip("set VSPHERE_ESXI_HOST to run vsphere_vnic acceptance tests")
+	}
+	if os.Getenv("VSPHERE_VNIC_IPV4_ADDRESS") == "" {
+		t.Skip("set VSPHERE_VNIC_IPV4_ADDRESS to run vsphere_vnic acceptance tests")
+	}
+	if os.Getenv("VSPHERE_VNIC_IPV4_NETMASK") == "" {
+		t.Skip("set VSPHERE_VNIC_IPV4_NETMASK to run vsphere_vnic acceptance tests")
+	}
+}
+
+func testAccResourceVSphereVNicExists(expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		_, err := testGetVNic(s, "vnic")
+		if err != nil {
+			if isHostVirtualNicNotFoundError(err) && expected == false {
+				// Expected missing
+				return nil
+			}
+			return err
+		}
+		if expected == false {
+			return fmt.Errorf("expected VMkernel network adapter to still be missing")
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNicCheckIPv4(dhcp bool, address string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		nic, err := testGetVNic(s, "vnic")
+		if err != nil {
+			return err
+		}
+		if nic.Spec.Ip.Dhcp != dhcp {
+			return fmt.Errorf("expected DHCP to be %t, got %t", dhcp, nic.Spec.Ip.Dhcp)
+		}
+		if !dhcp && nic.Spec.Ip.IpAddress != address {
+			return fmt.Errorf("expected IPv4 address to be %q, got %q", address, nic.Spec.Ip.IpAddress)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNicCheckMTU(expected int32) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		nic, err := testGetVNic(s, "vnic")
+		if err != nil {
+			return err
+		}
+		if nic.Spec.Mtu != expected {
+			return fmt.Errorf("expected MTU to be %d, got %d", expected, nic.Spec.Mtu)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNicCheckDVPortgroup() resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		nic, err := testGetVNic(s, "vnic")
+		if err != nil {
+			return err
+		}
+		if nic.Spec.DistributedVirtualPort == nil {
+			return fmt.Errorf("expected VMkernel network adapter to be connected to a distributed port group")
+		}
+		pg, err := testGetDVPortgroupProperties(s, "pg")
+		if err != nil {
+			return err
+		}
+		if nic.Spec.DistributedVirtualPort.PortgroupKey != pg.Key {
+			return fmt.Errorf("expected port group key to be %q, got %q", pg.Key, nic.Spec.DistributedVirtualPort.PortgroupKey)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNicCheckServices(expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tVars, err := testClientVariablesForResource(s, "vsphere_vnic.vnic")
+		if err != nil {
+			return err
+		}
+		hsID, device, err := splitHostVirtualNicID(tVars.resourceID)
+		if err != nil {
+			return err
+		}
+		vnm, err := hostVirtualNicManagerFromHostSystemID(tVars.client, hsID)
+		if err != nil {
+			return err
+		}
+		actual, err := hostVirtualNicServices(vnm, device)
+		if err != nil {
+			return err
+		}
+		sort.Strings(expected)
+		sort.Strings(actual)
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("expected services to be %#v, got %#v", expected, actual)
+		}
+		return nil
+	}
+}
+
+func testAccResourceVSphereVNicConfigBase() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+variable "host_nic1" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_host_virtual_switch" "switch" {
+  name           = "vSwitchTerraformTest"
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+
+  network_adapters = ["${var.host_nic0}", "${var.host_nic1}"]
+  active_nics      = ["${var.host_nic0}", "${var.host_nic1}"]
+  standby_nics     = []
+}
+
+resource "vsphere_host_port_group" "pg" {
+  name                = "PGTerraformTest"
+  host_system_id      = "${data.vsphere_host.esxi_host.id}"
+  virtual_switch_name = "${vsphere_host_virtual_switch.switch.name}"
+}
+`, os.Getenv("VSPHERE_HOST_NIC0"), os.Getenv("VSPHERE_HOST_NIC1"), os.Getenv("VSPHERE_DATACENTER"), os.Getenv("VSPHERE_ESXI_HOST"))
+}
+
+func testAccResourceVSphereVNicConfigStatic() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  portgroup      = "${vsphere_host_port_group.pg.name}"
+
+  ipv4 {
+    ip      = "%s"
+    netmask = "%s"
+  }
+}
+`,
+		testAccResourceVSphereVNicConfigBase(),
+		os.Getenv("VSPHERE_VNIC_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_VNIC_IPV4_NETMASK"),
+	)
+}
+
+func testAccResourceVSphereVNicConfigDHCP() string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  portgroup      = "${vsphere_host_port_group.pg.name}"
+
+  ipv4 {
+    dhcp = true
+  }
+}
+`,
+		testAccResourceVSphereVNicConfigBase(),
+	)
+}
+
+func testAccResourceVSphereVNicConfigMTU(mtu int) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  portgroup      = "${vsphere_host_port_group.pg.name}"
+  mtu            = %d
+
+  ipv4 {
+    ip      = "%s"
+    netmask = "%s"
+  }
+}
+`,
+		testAccResourceVSphereVNicConfigBase(),
+		mtu,
+		os.Getenv("VSPHERE_VNIC_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_VNIC_IPV4_NETMASK"),
+	)
+}
+
+func testAccResourceVSphereVNicConfigServices(services []string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id = "${data.vsphere_host.esxi_host.id}"
+  portgroup      = "${vsphere_host_port_group.pg.name}"
+  services       = %s
+
+  ipv4 {
+    ip      = "%s"
+    netmask = "%s"
+  }
+}
+`,
+		testAccResourceVSphereVNicConfigBase(),
+		testAccResourceVSphereVNicServicesList(services),
+		os.Getenv("VSPHERE_VNIC_IPV4_ADDRESS"),
+		os.Getenv("VSPHERE_VNIC_IPV4_NETMASK"),
+	)
+}
+
+func testAccResourceVSphereVNicServicesList(services []string) string {
+	var quoted []string
+	for _, s := range services {
+		quoted = append(quoted, fmt.Sprintf("%q", s))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(quoted, ", "))
+}
+
+func testAccResourceVSphereVNicConfigDVPortgroup() string {
+	return fmt.Sprintf(`
+variable "host_nic0" {
+  default = "%s"
+}
+
+data "vsphere_datacenter" "datacenter" {
+  name = "%s"
+}
+
+data "vsphere_host" "esxi_host" {
+  name          = "%s"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+}
+
+resource "vsphere_distributed_virtual_switch" "dvs" {
+  name          = "terraform-test-dvs"
+  datacenter_id = "${data.vsphere_datacenter.datacenter.id}"
+
+  host {
+    host_system_id = "${data.vsphere_host.esxi_host.id}"
+    devices        = ["${var.host_nic0}"]
+  }
+}
+
+resource "vsphere_distributed_port_group" "pg" {
+  name                            = "terraform-test-pg"
+  distributed_virtual_switch_uuid = "${vsphere_distributed_virtual_switch.dvs.uuid}"
+}
+
+resource "vsphere_vnic" "vnic" {
+  host_system_id         = "${data.vsphere_host.esxi_host.id}"
+  distributed_port_group = "${vsphere_distributed_port_group.pg.id}"
+
+  ipv4 {
+    dhcp = true
+  }
+}
+`,
+		os.Getenv("VSPHERE_HOST_NIC0"),
+		os.Getenv("VSPHERE_DATACENTER"),
+		os.Getenv("VSPHERE_ESXI_HOST"),
+	)
+}
@@ -0,0 +1,118 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var storageDrsPodConfigInfoBehaviorAllowedValues = []string{
+	string(types.StorageDrsPodConfigInfoBehaviorManual),
+	string(types.StorageDrsPodConfigInfoBehaviorAutomated),
+}
+
+// schemaStorageDrsPodConfigSpec returns schema items for resources that need
+// to work with a StorageDrsPodConfigSpec.
+func schemaStorageDrsPodConfigSpec() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"sdrs_enabled": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Enable Storage DRS for this datastore cluster.",
+		},
+		"sdrs_automation_level": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(types.StorageDrsPodConfigInfoBehaviorManual),
+			Description:  "The default automation level for all virtual machines in this datastore cluster. Can be one of manual or automated.",
+			ValidateFunc: validation.StringInSlice(storageDrsPodConfigInfoBehaviorAllowedValues, false),
+		},
+		"sdrs_default_intra_vm_affinity": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "When true, all disks in a single virtual machine will be kept on the same datastore.",
+		},
+		"sdrs_load_balance_interval": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      480,
+			Description:  "The storage DRS poll interval, in minutes.",
+			ValidateFunc: validation.IntBetween(60, 43200),
+		},
+		"sdrs_space_utilization_threshold": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      80,
+			Description:  "The threshold, in percent of used space, that storage DRS uses to make decisions to migrate VMs out of a datastore.",
+			ValidateFunc: validation.IntBetween(50, 100),
+		},
+		"sdrs_io_load_balance_enabled": &schema.Schema{
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Enable I/O load balancing for this datastore cluster.",
+		},
+		"sdrs_io_latency_threshold": &schema.Schema{
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      15,
+			Description:  "The I/O latency threshold, in milliseconds, that storage DRS uses to make recommendations to move disks from this datastore.",
+			ValidateFunc: validation.IntBetween(5, 100),
+		},
+	}
+}
+
+// expandStorageDrsPodConfigSpec reads certain ResourceData keys and returns a
+// StorageDrsPodConfigSpec.
+func expandStorageDrsPodConfigSpec(d *schema.ResourceData) *types.StorageDrsPodConfigSpec {
+	obj := &types.StorageDrsPodConfigSpec{
+		Enabled:                boolPtr(d.Get("sdrs_enabled").(bool)),
+		DefaultVmBehavior:      d.Get("sdrs_automation_level").(string),
+		DefaultIntraVmAffinity: boolPtr(d.Get("sdrs_default_intra_vm_affinity").(bool)),
+		LoadBalanceInterval:    int32(d.Get("sdrs_load_balance_interval").(int)),
+		IoLoadBalanceEnabled:   boolPtr(d.Get("sdrs_io_load_balance_enabled").(bool)),
+		SpaceLoadBalanceConfig: &types.StorageDrsSpaceLoadBalanceConfig{
+			SpaceThresholdMode:        string(types.StorageDrsSpaceLoadBalanceConfigSpaceThresholdModeUtilization),
+			SpaceUtilizationThreshold: int32(d.Get("sdrs_space_utilization_threshold").(int)),
+		},
+		IoLoadBalanceConfig: &types.StorageDrsIoLoadBalanceConfig{
+			IoLatencyThreshold: int32(d.Get("sdrs_io_latency_threshold").(int)),
+		},
+	}
+	return obj
+}
+
+// flattenStorageDrsPodConfigInfo reads various fields from a
+// StorageDrsPodConfigInfo into the passed in ResourceData.
+func flattenStorageDrsPodConfigInfo(d *schema.ResourceData, obj types.StorageDrsPodConfigInfo) error {
+	d.Set("sdrs_enabled", obj.Enabled)
+	d.Set("sdrs_automation_level", obj.DefaultVmBehavior)
+	if obj.DefaultIntraVmAffinity != nil {
+		d.Set("sdrs_default_intra_vm_affinity", *obj.DefaultIntraVmAffinity)
+	}
+	d.Set("sdrs_load_balance_interval", obj.LoadBalanceInterval)
+	d.Set("sdrs_io_load_balance_enabled", obj.IoLoadBalanceEnabled)
+	if obj.SpaceLoadBalanceConfig != nil {
+		d.Set("sdrs_space_utilization_threshold", obj.SpaceLoadBalanceConfig.SpaceUtilizationThreshold)
+	}
+	if obj.IoLoadBalanceConfig != nil {
+		d.Set("sdrs_io_latency_threshold", obj.IoLoadBalanceConfig.IoLatencyThreshold)
+	}
+	return nil
+}
+
+// expandStorageDrsConfigSpec reads certain ResourceData keys and returns a
+// StorageDrsConfigSpec.
+func expandStorageDrsConfigSpec(d *schema.ResourceData) types.StorageDrsConfigSpec {
+	return types.StorageDrsConfigSpec{
+		PodConfigSpec: expandStorageDrsPodConfigSpec(d),
+	}
+}
+
+// flattenStorageDrsConfigInfo reads various fields from a
+// StorageDrsConfigInfo into the passed in ResourceData.
+func flattenStorageDrsConfigInfo(d *schema.ResourceData, obj types.StorageDrsConfigInfo) error {
+	return flattenStorageDrsPodConfigInfo(d, obj.PodConfig)
+}
@@ -0,0 +1,172 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// storagePodFromID locates a StoragePod (datastore cluster) by its managed
+// object reference ID.
+func storagePodFromID(client *govmomi.Client, id string) (*object.StoragePod, error) {
+	finder := find.NewFinder(client.Client, false)
+
+	ref := types.ManagedObjectReference{
+		Type:  "StoragePod",
+		Value: id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	obj, err := finder.ObjectReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*object.StoragePod), nil
+}
+
+// storagePodFromAbsolutePath returns an *object.StoragePod from a given
+// absolute inventory path. If no such datastore cluster is found, an
+// appropriate error will be returned.
+func storagePodFromAbsolutePath(client *govmomi.Client, path string) (*object.StoragePod, error) {
+	finder := find.NewFinder(client.Client, false)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.DatastoreCluster(ctx, path)
+}
+
+// storagePodProperties is a convenience method that wraps fetching the
+// StoragePod MO from its higher-level object.
+func storagePodProperties(pod *object.StoragePod) (*mo.StoragePod, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	var props mo.StoragePod
+	if err := pod.Properties(ctx, pod.Reference(), nil, &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// createStoragePod creates a datastore cluster in the supplied datastore
+// folder with the supplied name.
+func createStoragePod(f *object.Folder, name string) (*object.StoragePod, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	pod, err := f.CreateStoragePod(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	// CreateStoragePod returns a nil pod if we are not connected to vCenter.
+	if pod == nil {
+		return nil, errors.New(errVirtualCenterOnly)
+	}
+	return pod, nil
+}
+
+// reconfigureStoragePod applies the supplied Storage DRS configuration to a
+// datastore cluster. The changes are applied incrementally - items not set in
+// the spec are left unchanged.
+func reconfigureStoragePod(client *govmomi.Client, pod *object.StoragePod, spec types.StorageDrsConfigSpec) error {
+	srm := object.NewStorageResourceManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := srm.ConfigureStorageDrsForPod(ctx, pod, spec, true)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// deleteStoragePod destroys the supplied datastore cluster.
+func deleteStoragePod(pod *object.StoragePod) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := pod.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// moveStoragePodToFolder moves a datastore cluster to a given relative
+// datastore folder path. "Relative" here means relative to a datacenter,
+// which is discovered from the current datastore cluster path.
+func moveStoragePodToFolder(client *govmomi.Client, pod *object.StoragePod, relative string) error {
+	folder, err := datastoreFolderFromObject(client, pod, relative)
+	if err != nil {
+		return err
+	}
+	return moveObjectToFolder(pod.Reference(), folder)
+}
+
+// storagePodHasChildren checks to see if a datastore cluster has any child
+// datastores.
+func storagePodHasChildren(pod *object.StoragePod) (bool, error) {
+	return folderHasChildren(pod.Folder)
+}
+
+// recommendStoragePodPlacement asks Storage DRS for a placement
+// recommendation for the supplied StoragePlacementSpec, and returns the top
+// recommendation.
+func recommendStoragePodPlacement(client *govmomi.Client, sps types.StoragePlacementSpec) (*types.ClusterRecommendation, error) {
+	srm := object.NewStorageResourceManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	result, err := srm.RecommendDatastores(ctx, sps)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Recommendations) < 1 {
+		if result.DrsFault != nil {
+			return nil, fmt.Errorf("no storage DRS recommendations were found: %s", result.DrsFault.Reason)
+		}
+		return nil, errors.New("no storage DRS recommendations were found for the requested placement")
+	}
+	log.Printf("[DEBUG] Using storage DRS recommendation %q (reason: %s)", result.Recommendations[0].Key, result.Recommendations[0].ReasonText)
+	return &result.Recommendations[0], nil
+}
+
+// storagePodRecommendationDatastore returns the destination datastore of the
+// placement action in the supplied Storage DRS recommendation.
+func storagePodRecommendationDatastore(client *govmomi.Client, rec *types.ClusterRecommendation) (*object.Datastore, error) {
+	for _, action := range rec.Action {
+		if spa, ok := action.(*types.StoragePlacementAction); ok {
+			return datastoreFromID(client, spa.Destination.Value)
+		}
+	}
+	return nil, fmt.Errorf("storage DRS recommendation %q has no placement action", rec.Key)
+}
+
+// storagePodRecommendationApplyTimeout is the time that
+// applyStoragePodRecommendation waits for the recommendation to be applied.
+// The task carries out the operation of the original placement spec, which
+// can be a full clone of a template.
+const storagePodRecommendationApplyTimeout = time.Minute * 120
+
+// applyStoragePodRecommendation applies the supplied Storage DRS
+// recommendation, waiting for up to storagePodRecommendationApplyTimeout for
+// the resulting task to complete.
+func applyStoragePodRecommendation(client *govmomi.Client, rec *types.ClusterRecommendation) error {
+	srm := object.NewStorageResourceManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := srm.ApplyStorageDrsRecommendation(ctx, []string{rec.Key})
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), storagePodRecommendationApplyTimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 // sliceInterfacesToStrings converts an interface slice to a string slice. The
@@ -58,3 +59,69 @@ func byteToMB(n interface{}) interface{} {
 	}
 	panic(fmt.Errorf("non-integer type %T for value", n))
 }
+
+// getBoolPolicy reads a bool from the supplied ResourceData key and returns a
+// non-inherited BoolPolicy. If the value has not been set, nil is returned,
+// which denotes inheritance of the value from the parent object.
+func getBoolPolicy(d *schema.ResourceData, key string) *types.BoolPolicy {
+	v, ok := d.GetOkExists(key)
+	if !ok {
+		return nil
+	}
+	return &types.BoolPolicy{
+		Value: boolPtr(v.(bool)),
+	}
+}
+
+// setBoolPolicy sets the value of a BoolPolicy to the supplied ResourceData
+// key. Nil policies and values are ignored.
+func setBoolPolicy(d *schema.ResourceData, key string, p *types.BoolPolicy) {
+	if p == nil || p.Value == nil {
+		return
+	}
+	d.Set(key, *p.Value)
+}
+
+// getStringPolicy reads a string from the supplied ResourceData key and
+// returns a non-inherited StringPolicy. If the value has not been set, nil is
+// returned, which denotes inheritance of the value from the parent object.
+func getStringPolicy(d *schema.ResourceData, key string) *types.StringPolicy {
+	v, ok := d.GetOk(key)
+	if !ok {
+		return nil
+	}
+	return &types.StringPolicy{
+		Value: v.(string),
+	}
+}
+
+// setStringPolicy sets the value of a StringPolicy to the supplied
+// ResourceData key. Nil policies are ignored.
+func setStringPolicy(d *schema.ResourceData, key string, p *types.StringPolicy) {
+	if p == nil {
+		return
+	}
+	d.Set(key, p.Value)
+}
+
+// getLongPolicy reads an integer from the supplied ResourceData key and
+// returns a non-inherited LongPolicy. If the value has not been set, nil is
+// returned, which denotes inheritance of the value from the parent object.
+func getLongPolicy(d *schema.ResourceData, key string) *types.LongPolicy {
+	v, ok := d.GetOkExists(key)
+	if !ok {
+		return nil
+	}
+	return &types.LongPolicy{
+		Value: int64(v.(int)),
+	}
+}
+
+// setLongPolicy sets the value of a LongPolicy to the supplied ResourceData
+// key. Nil policies are ignored.
+func setLongPolicy(d *schema.ResourceData, key string, p *types.LongPolicy) {
+	if p == nil {
+		return
+	}
+	d.Set(key, p.Value)
+}
@@ -185,6 +185,8 @@ func tagTypeForObject(obj object.Reference) (string, error) {
 		return vSphereTagTypeVirtualMachine, nil
 	case *object.Datastore:
 		return vSphereTagTypeDatastore, nil
+	case *object.StoragePod:
+		return vSphereTagTypeStoragePod, nil
 	case *object.Network:
 		return vSphereTagTypeNetwork, nil
 	case *object.Folder:
@@ -193,12 +195,16 @@ func tagTypeForObject(obj object.Reference) (string, error) {
 		return vSphereTagTypeVmwareDistributedVirtualSwitch, nil
 	case *object.DistributedVirtualSwitch:
 		return vSphereTagTypeDistributedVirtualSwitch, nil
+	case *object.DistributedVirtualPortgroup:
+		return vSphereTagTypeDistributedVirtualPortgroup, nil
 	case *object.Datacenter:
 		return vSphereTagTypeDatacenter, nil
 	case *object.ClusterComputeResource:
 		return vSphereTagTypeClusterComputeResource, nil
 	case *object.HostSystem:
 		return vSphereTagTypeHostSystem, nil
+	case *object.ResourcePool:
+		return vSphereTagTypeResourcePool, nil
 	}
 	return "", fmt.Errorf("unsupported type for tagging: %T", obj)
 }
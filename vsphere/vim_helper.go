@@ -47,6 +47,17 @@ func isManagedObjectNotFoundError(err error) bool {
 	return false
 }
 
+// isFileAlreadyExistsError checks an error to see if it's of the
+// FileAlreadyExists type.
+func isFileAlreadyExistsError(err error) bool {
+	if f, ok := vimSoapFault(err); ok {
+		if _, ok := f.(types.FileAlreadyExists); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // isResourceInUseError checks an error to see if it's of the
 // ResourceInUse type.
 func isResourceInUseError(err error) bool {
@@ -0,0 +1,174 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// virtualDiskTypesFromAPI maps the disk types reported by the virtual disk
+// manager to the values of the type attribute of vsphere_virtual_disk.
+var virtualDiskTypesFromAPI = map[string]string{
+	"thin":             "thin",
+	"preallocated":     "lazy",
+	"eagerZeroedThick": "eagerZeroedThick",
+}
+
+// virtualDiskAdapterTypesFromAPI maps the controller types reported by a
+// datastore browser search to the values of the adapter_type attribute of
+// vsphere_virtual_disk.
+var virtualDiskAdapterTypesFromAPI = map[string]string{
+	"VirtualIDEController":      "ide",
+	"VirtualBusLogicController": "busLogic",
+	"VirtualLsiLogicController": "lsiLogic",
+}
+
+// virtualDiskExtendTimeout is the time that extendVirtualDisk waits for the
+// extension task to complete. Eager zeroing writes out all of the added
+// space, which takes a while for large extensions.
+const virtualDiskExtendTimeout = time.Minute * 60
+
+// extendVirtualDisk grows the virtual disk at the supplied datastore path to
+// the supplied capacity, in GB. eagerZero controls whether or not the added
+// space is zeroed out. The extension task is waited on for up to
+// virtualDiskExtendTimeout.
+func extendVirtualDisk(client *govmomi.Client, name string, dc *object.Datacenter, size int, eagerZero bool) error {
+	req := types.ExtendVirtualDisk_Task{
+		This:          *client.Client.ServiceContent.VirtualDiskManager,
+		Name:          name,
+		NewCapacityKb: int64(1024 * 1024 * size),
+		EagerZero:     &eagerZero,
+	}
+	if dc != nil {
+		ref := dc.Reference()
+		req.Datacenter = &ref
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	res, err := methods.ExtendVirtualDisk_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+
+	t := object.NewTask(client.Client, res.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), virtualDiskExtendTimeout)
+	defer tcancel()
+	return t.Wait(tctx)
+}
+
+// virtualDiskMoveTimeout is the time that moveVirtualDisk waits for the move
+// task to complete. A move across datastores copies the entire disk.
+const virtualDiskMoveTimeout = time.Minute * 60
+
+// moveVirtualDisk moves the virtual disk at the datastore path src to the
+// datastore path dst, creating the destination directory if it does not
+// exist. The move can be within a datastore or across datastores. The move
+// task is waited on for up to virtualDiskMoveTimeout.
+func moveVirtualDisk(client *govmomi.Client, src, dst string, dc *object.Datacenter) error {
+	var dstPath object.DatastorePath
+	if !dstPath.FromString(dst) {
+		return fmt.Errorf("invalid datastore path %q", dst)
+	}
+	if dir := path.Dir(dstPath.Path); dir != "." {
+		dirPath := object.DatastorePath{Datastore: dstPath.Datastore, Path: dir}
+		fm := object.NewFileManager(client.Client)
+		ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+		defer cancel()
+		if err := fm.MakeDirectory(ctx, dirPath.String(), dc, true); err != nil && !isFileAlreadyExistsError(err) {
+			return fmt.Errorf("error creating directory %q: %s", dirPath.String(), err)
+		}
+	}
+
+	vdm := object.NewVirtualDiskManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vdm.MoveVirtualDisk(ctx, src, dc, dst, dc, false)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), virtualDiskMoveTimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// searchVirtualDisk searches a datastore for the virtual disk at the supplied
+// path relative to the datastore, and returns its file information. A nil
+// result with no error means the disk could not be found.
+func searchVirtualDisk(ds *object.Datastore, vmdkPath string) (*types.VmDiskFileInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	b, err := ds.Browser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// `Datastore.Stat` does not allow to query `VmDiskFileQuery`. Instead, we
+	// search the datastore manually.
+	spec := types.HostDatastoreBrowserSearchSpec{
+		Query: []types.BaseFileQuery{&types.VmDiskFileQuery{Details: &types.VmDiskFileQueryFlags{
+			CapacityKb:     true,
+			DiskType:       true,
+			ControllerType: types.NewBool(true),
+		}}},
+		Details: &types.FileQueryFlags{
+			FileSize:     true,
+			FileType:     true,
+			Modification: true,
+			FileOwner:    types.NewBool(true),
+		},
+		MatchPattern: []string{path.Base(vmdkPath)},
+	}
+
+	task, err := b.SearchDatastore(ctx, ds.Path(path.Dir(vmdkPath)), &spec)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		if info != nil && info.Error != nil {
+			if _, ok := info.Error.Fault.(*types.FileNotFound); ok {
+				return nil, nil
+			}
+		}
+		return nil, err
+	}
+
+	res := info.Result.(types.HostDatastoreBrowserSearchResults)
+	switch len(res.File) {
+	case 0:
+		return nil, nil
+	case 1:
+		return res.File[0].(*types.VmDiskFileInfo), nil
+	}
+	return nil, errors.New("Datastore search did not return exactly one result")
+}
+
+// virtualDiskType returns the provisioning type of the virtual disk at the
+// supplied datastore path, as read from the disk descriptor, translated to a
+// value for the type attribute of vsphere_virtual_disk.
+func virtualDiskType(client *govmomi.Client, name string, dc *object.Datacenter) (string, error) {
+	vdm := object.NewVirtualDiskManager(client.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	info, err := vdm.QueryVirtualDiskInfo(ctx, name, dc, false)
+	if err != nil {
+		return "", err
+	}
+	if len(info) < 1 {
+		return "", fmt.Errorf("no disk information returned for %q", name)
+	}
+	t, ok := virtualDiskTypesFromAPI[info[0].DiskType]
+	if !ok {
+		return "", fmt.Errorf("unsupported disk type %q for %q", info[0].DiskType, name)
+	}
+	return t, nil
+}
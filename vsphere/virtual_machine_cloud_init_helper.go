@@ -0,0 +1,164 @@
+package vsphere
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// cloudInitEncodingBase64 is the encoding for cloud-init data that is
+	// base64 encoded.
+	cloudInitEncodingBase64 = "base64"
+
+	// cloudInitEncodingGzipBase64 is the encoding for cloud-init data that is
+	// gzip compressed, then base64 encoded.
+	cloudInitEncodingGzipBase64 = "gzip+base64"
+)
+
+var cloudInitEncodingAllowedValues = []string{
+	cloudInitEncodingBase64,
+	cloudInitEncodingGzipBase64,
+}
+
+// cloudInitGuestInfoKeys maps the attributes of the cloud_init block to the
+// guestinfo keys that the VMware datasource of cloud-init reads them from.
+// Each key has a companion key with an .encoding suffix.
+var cloudInitGuestInfoKeys = map[string]string{
+	"metadata":   "guestinfo.metadata",
+	"userdata":   "guestinfo.userdata",
+	"vendordata": "guestinfo.vendordata",
+}
+
+// cloudInitConfig holds the cloud-init data to pass to a virtual machine
+// through guestinfo.
+type cloudInitConfig struct {
+	data          map[string]string
+	encoding      string
+	skipLinuxPrep bool
+}
+
+// expandCloudInitConfig reads the cloud_init block of a virtual machine into
+// a cloudInitConfig.
+func expandCloudInitConfig(raw map[string]interface{}) *cloudInitConfig {
+	c := &cloudInitConfig{
+		data:          make(map[string]string),
+		encoding:      raw["encoding"].(string),
+		skipLinuxPrep: raw["skip_linux_prep"].(bool),
+	}
+	for attr := range cloudInitGuestInfoKeys {
+		if v, ok := raw[attr].(string); ok && v != "" {
+			c.data[attr] = v
+		}
+	}
+	return c
+}
+
+// extraConfig returns the guestinfo extraConfig options that carry the
+// cloud-init data, encoded with the configured encoding.
+func (c *cloudInitConfig) extraConfig() ([]types.BaseOptionValue, error) {
+	var attrs []string
+	for attr := range c.data {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	var ov []types.BaseOptionValue
+	for _, attr := range attrs {
+		key := cloudInitGuestInfoKeys[attr]
+		encoded, err := encodeCloudInitData(c.data[attr], c.encoding)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding cloud-init %s: %s", attr, err)
+		}
+		ov = append(ov, &types.OptionValue{Key: key, Value: encoded})
+		ov = append(ov, &types.OptionValue{Key: key + ".encoding", Value: c.encoding})
+	}
+	return ov, nil
+}
+
+// encodeCloudInitData encodes cloud-init data with the supplied encoding.
+func encodeCloudInitData(data, encoding string) (string, error) {
+	switch encoding {
+	case cloudInitEncodingBase64:
+		return base64.StdEncoding.EncodeToString([]byte(data)), nil
+	case cloudInitEncodingGzipBase64:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write([]byte(data)); err != nil {
+			return "", err
+		}
+		if err := w.Close(); err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+	}
+	return "", fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+// decodeCloudInitData decodes cloud-init data that was encoded with the
+// supplied encoding.
+func decodeCloudInitData(data, encoding string) (string, error) {
+	switch encoding {
+	case cloudInitEncodingBase64:
+		b, err := base64.StdEncoding.DecodeString(data)
+		return string(b), err
+	case cloudInitEncodingGzipBase64:
+		b, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return "", err
+		}
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		b, err = ioutil.ReadAll(r)
+		return string(b), err
+	}
+	return "", fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+// flattenImportedCloudInit returns the cloud_init block for an imported
+// virtual machine, decoded from its guestinfo extraConfig options. nil is
+// returned if the virtual machine has no cloud-init data, or if the data
+// uses an encoding this resource does not support.
+func flattenImportedCloudInit(extraConfig []types.BaseOptionValue) []interface{} {
+	values := make(map[string]string)
+	for _, v := range extraConfig {
+		ov := v.GetOptionValue()
+		if s, ok := ov.Value.(string); ok {
+			values[ov.Key] = s
+		}
+	}
+
+	result := map[string]interface{}{
+		"skip_linux_prep": true,
+	}
+	var encoding string
+	for attr, key := range cloudInitGuestInfoKeys {
+		data, ok := values[key]
+		if !ok {
+			continue
+		}
+		e := values[key+".encoding"]
+		if encoding != "" && e != encoding {
+			return nil
+		}
+		encoding = e
+		decoded, err := decodeCloudInitData(data, e)
+		if err != nil {
+			return nil
+		}
+		result[attr] = decoded
+	}
+	if encoding == "" {
+		return nil
+	}
+	result["encoding"] = encoding
+	return []interface{}{result}
+}
@@ -0,0 +1,82 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const testCloudInitUserData = `#cloud-config
+hostname: terraform-test
+`
+
+func TestCloudInitDataRoundTrip(t *testing.T) {
+	for _, encoding := range cloudInitEncodingAllowedValues {
+		t.Run(encoding, func(t *testing.T) {
+			encoded, err := encodeCloudInitData(testCloudInitUserData, encoding)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			decoded, err := decodeCloudInitData(encoded, encoding)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			if decoded != testCloudInitUserData {
+				t.Fatalf("expected %q, got %q", testCloudInitUserData, decoded)
+			}
+		})
+	}
+}
+
+func TestCloudInitConfigExtraConfig(t *testing.T) {
+	c := expandCloudInitConfig(map[string]interface{}{
+		"metadata":        "",
+		"userdata":        testCloudInitUserData,
+		"vendordata":      "",
+		"encoding":        cloudInitEncodingBase64,
+		"skip_linux_prep": true,
+	})
+	actual, err := c.extraConfig()
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	expected := []types.BaseOptionValue{
+		&types.OptionValue{Key: "guestinfo.userdata", Value: "I2Nsb3VkLWNvbmZpZwpob3N0bmFtZTogdGVycmFmb3JtLXRlc3QK"},
+		&types.OptionValue{Key: "guestinfo.userdata.encoding", Value: cloudInitEncodingBase64},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestFlattenImportedCloudInit(t *testing.T) {
+	c := expandCloudInitConfig(map[string]interface{}{
+		"metadata":        "instance-id: terraform-test",
+		"userdata":        testCloudInitUserData,
+		"encoding":        cloudInitEncodingGzipBase64,
+		"skip_linux_prep": true,
+	})
+	ov, err := c.extraConfig()
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	ov = append(ov, &types.OptionValue{Key: "guestinfo.appInfo", Value: "unrelated"})
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"metadata":        "instance-id: terraform-test",
+			"userdata":        testCloudInitUserData,
+			"encoding":        cloudInitEncodingGzipBase64,
+			"skip_linux_prep": true,
+		},
+	}
+	actual := flattenImportedCloudInit(ov)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+
+	if actual := flattenImportedCloudInit(nil); actual != nil {
+		t.Fatalf("expected nil, got %#v", actual)
+	}
+}
@@ -4,16 +4,47 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"net"
+	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/progress"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
+// uuidNotFoundError is an error type that is returned when a virtual machine
+// cannot be found by its UUID.
+type uuidNotFoundError struct {
+	uuid string
+}
+
+// newUUIDNotFoundError returns a new uuidNotFoundError for the supplied UUID.
+func newUUIDNotFoundError(uuid string) *uuidNotFoundError {
+	return &uuidNotFoundError{
+		uuid: uuid,
+	}
+}
+
+// Error implements error for uuidNotFoundError.
+func (e *uuidNotFoundError) Error() string {
+	return fmt.Sprintf("virtual machine with UUID %q not found", e.uuid)
+}
+
+// isVirtualMachineNotFoundError checks an error to see if it indicates that a
+// virtual machine could not be found, either by UUID or by its managed object
+// reference.
+func isVirtualMachineNotFoundError(err error) bool {
+	if _, ok := err.(*uuidNotFoundError); ok {
+		return true
+	}
+	return isManagedObjectNotFoundError(err)
+}
+
 // virtualMachineFromUUID locates a virtualMachine by its UUID.
 func virtualMachineFromUUID(client *govmomi.Client, uuid string) (*object.VirtualMachine, error) {
 	search := object.NewSearchIndex(client.Client)
@@ -26,7 +57,7 @@ func virtualMachineFromUUID(client *govmomi.Client, uuid string) (*object.Virtua
 	}
 
 	if result == nil {
-		return nil, fmt.Errorf("virtual machine with UUID %q not found", uuid)
+		return nil, newUUIDNotFoundError(uuid)
 	}
 
 	// We need to filter our object through finder to ensure that the
@@ -69,6 +100,46 @@ func virtualMachineFromManagedObjectID(client *govmomi.Client, id string) (*obje
 	return vm.(*object.VirtualMachine), nil
 }
 
+// moveVirtualMachineToFolder moves a virtual machine to a given relative VM
+// folder path. "Relative" here means relative to a datacenter, which is
+// discovered from the current virtual machine path.
+func moveVirtualMachineToFolder(client *govmomi.Client, vm *object.VirtualMachine, relative string) error {
+	folder, err := vmFolderFromObject(client, vm, relative)
+	if err != nil {
+		return err
+	}
+	return moveObjectToFolder(vm.Reference(), folder)
+}
+
+// virtualMachineFromAbsolutePath locates a virtualMachine by its full
+// inventory path.
+func virtualMachineFromAbsolutePath(client *govmomi.Client, path string) (*object.VirtualMachine, error) {
+	finder := find.NewFinder(client.Client, false)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	return finder.VirtualMachine(ctx, path)
+}
+
+// virtualMachineUUIDFromManagedObjectID returns the UUID of the virtual
+// machine with the supplied managed object reference ID. This is the same
+// UUID that virtualMachineFromUUID searches on.
+func virtualMachineUUIDFromManagedObjectID(client *govmomi.Client, id string) (string, error) {
+	vm, err := virtualMachineFromManagedObjectID(client, id)
+	if err != nil {
+		return "", err
+	}
+	var props mo.VirtualMachine
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	if err := vm.Properties(ctx, vm.Reference(), []string{"config.uuid"}, &props); err != nil {
+		return "", err
+	}
+	if props.Config == nil {
+		return "", fmt.Errorf("virtual machine %q has no configuration", id)
+	}
+	return props.Config.Uuid, nil
+}
+
 // virtualMachineProperties is a convenience method that wraps fetching the
 // VirtualMachine MO from its higher-level object.
 func virtualMachineProperties(vm *object.VirtualMachine) (*mo.VirtualMachine, error) {
@@ -145,3 +216,107 @@ func waitForGuestVMNet(client *govmomi.Client, vm *object.VirtualMachine) error
 
 	return nil
 }
+
+// relocateVirtualMachine migrates a virtual machine to the compute and storage
+// placement described in the supplied relocate spec. The wait for the task is
+// bounded by the supplied timeout, and progress is logged as the migration
+// runs.
+func relocateVirtualMachine(vm *object.VirtualMachine, spec types.VirtualMachineRelocateSpec, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.Relocate(ctx, spec, types.VirtualMachineMovePriorityDefaultPriority)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), timeout)
+	defer tcancel()
+	_, err = task.WaitForResult(tctx, newTaskProgressLogger(fmt.Sprintf("Relocating virtual machine %q", vm.InventoryPath)))
+	return err
+}
+
+// taskProgressLogger is a progress.Sinker that logs the progress of a task.
+type taskProgressLogger struct {
+	prefix string
+}
+
+// newTaskProgressLogger returns a new taskProgressLogger that logs progress
+// reports with the supplied prefix.
+func newTaskProgressLogger(prefix string) *taskProgressLogger {
+	return &taskProgressLogger{
+		prefix: prefix,
+	}
+}
+
+// Sink implements progress.Sinker for taskProgressLogger. The channel is
+// closed by the task waiter once the task completes.
+func (l *taskProgressLogger) Sink() chan<- progress.Report {
+	ch := make(chan progress.Report)
+	go func() {
+		var last float32 = -1
+		for r := range ch {
+			if r.Percentage() == last {
+				continue
+			}
+			last = r.Percentage()
+			log.Printf("[DEBUG] %s: %.0f%% complete", l.prefix, last)
+		}
+	}()
+	return ch
+}
+
+// customizeVirtualMachine starts the guest customization of a virtual machine
+// with the supplied spec and waits for the customization task to complete.
+// The returned waiter is created before the task is started, and completes
+// once the guest has finished customizing after the next power on, or once
+// the supplied timeout has passed.
+func customizeVirtualMachine(client *govmomi.Client, vm *object.VirtualMachine, spec types.CustomizationSpec, timeout time.Duration) (*virtualMachineCustomizationWaiter, error) {
+	log.Printf("[DEBUG] Customization spec for virtual machine %q: %#v", vm.InventoryPath, spec)
+	cw := newVirtualMachineCustomizationWaiter(client, vm, timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.Customize(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := task.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// powerOnVirtualMachine powers on a virtual machine and waits for the
+// operation to complete.
+func powerOnVirtualMachine(vm *object.VirtualMachine) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.PowerOn(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// powerOffVirtualMachine powers off a virtual machine and waits for the
+// operation to complete. This is a hard power off, and does not shut down
+// the guest.
+func powerOffVirtualMachine(vm *object.VirtualMachine) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// destroyVirtualMachine destroys a virtual machine, deleting all of its files.
+// The virtual machine needs to be powered off.
+func destroyVirtualMachine(vm *object.VirtualMachine) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
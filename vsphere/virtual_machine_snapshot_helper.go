@@ -0,0 +1,199 @@
+package vsphere
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+const (
+	// virtualMachineSnapshotCreateTimeout is the time that
+	// createVirtualMachineSnapshot waits for the snapshot task to complete.
+	// Including memory writes out the full memory of the virtual machine, and
+	// quiescing waits on VMware Tools in the guest.
+	virtualMachineSnapshotCreateTimeout = time.Minute * 30
+
+	// virtualMachineSnapshotRemoveTimeout is the time that
+	// removeVirtualMachineSnapshot waits for the removal task to complete.
+	// Consolidating the delta disks of a snapshot copies all changes made
+	// since it was taken into the parent disks.
+	virtualMachineSnapshotRemoveTimeout = time.Minute * 60
+)
+
+// createVirtualMachineSnapshot takes a snapshot of the supplied virtual
+// machine and returns the managed object ID of the new snapshot. The snapshot
+// task is waited on for up to virtualMachineSnapshotCreateTimeout.
+func createVirtualMachineSnapshot(vm *object.VirtualMachine, name, description string, memory, quiesce bool) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.CreateSnapshot(ctx, name, description, memory, quiesce)
+	if err != nil {
+		return "", err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), virtualMachineSnapshotCreateTimeout)
+	defer tcancel()
+	info, err := task.WaitForResult(tctx, nil)
+	if err != nil {
+		return "", err
+	}
+	return info.Result.(types.ManagedObjectReference).Value, nil
+}
+
+// removeVirtualMachineSnapshot removes the snapshot with the supplied managed
+// object ID from a virtual machine. removeChildren removes the entire subtree
+// under the snapshot, and consolidate controls whether or not the delta disks
+// of the snapshot are consolidated into their parent. The removal task is
+// waited on for up to virtualMachineSnapshotRemoveTimeout.
+func removeVirtualMachineSnapshot(vm *object.VirtualMachine, id string, removeChildren, consolidate bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	task, err := vm.RemoveSnapshot(ctx, id, removeChildren, &consolidate)
+	if err != nil {
+		return err
+	}
+	tctx, tcancel := context.WithTimeout(context.Background(), virtualMachineSnapshotRemoveTimeout)
+	defer tcancel()
+	return task.Wait(tctx)
+}
+
+// findVirtualMachineSnapshotInTree searches the snapshot tree of the supplied
+// virtual machine properties for the snapshot with the supplied managed
+// object ID. The snapshot's tree node is returned, along with the node of its
+// parent snapshot, which is nil for a root snapshot. A nil node is returned
+// if the snapshot cannot be found.
+func findVirtualMachineSnapshotInTree(props *mo.VirtualMachine, id string) (*types.VirtualMachineSnapshotTree, *types.VirtualMachineSnapshotTree) {
+	if props.Snapshot == nil {
+		return nil, nil
+	}
+	return findVirtualMachineSnapshotInNodes(props.Snapshot.RootSnapshotList, nil, id)
+}
+
+// findVirtualMachineSnapshotInNodes recursively searches a list of snapshot
+// tree nodes, all children of parent, for the snapshot with the supplied
+// managed object ID.
+func findVirtualMachineSnapshotInNodes(nodes []types.VirtualMachineSnapshotTree, parent *types.VirtualMachineSnapshotTree, id string) (*types.VirtualMachineSnapshotTree, *types.VirtualMachineSnapshotTree) {
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Snapshot.Value == id {
+			return node, parent
+		}
+		if found, p := findVirtualMachineSnapshotInNodes(node.ChildSnapshotList, node, id); found != nil {
+			return found, p
+		}
+	}
+	return nil, nil
+}
+
+// snapshotSubtreeContains returns true if any of the snapshots below the
+// supplied snapshot tree node has one of the supplied managed object IDs. The
+// node itself is not checked.
+func snapshotSubtreeContains(node *types.VirtualMachineSnapshotTree, ids []string) bool {
+	for i := range node.ChildSnapshotList {
+		child := &node.ChildSnapshotList[i]
+		for _, id := range ids {
+			if child.Snapshot.Value == id {
+				return true
+			}
+		}
+		if snapshotSubtreeContains(child, ids) {
+			return true
+		}
+	}
+	return false
+}
+
+// virtualMachineSnapshotsWithPrefix returns all snapshots in the snapshot tree
+// of the supplied virtual machine properties whose name starts with prefix,
+// sorted by creation time, oldest first.
+func virtualMachineSnapshotsWithPrefix(props *mo.VirtualMachine, prefix string) []types.VirtualMachineSnapshotTree {
+	var result []types.VirtualMachineSnapshotTree
+	if props.Snapshot == nil {
+		return result
+	}
+	var walk func([]types.VirtualMachineSnapshotTree)
+	walk = func(nodes []types.VirtualMachineSnapshotTree) {
+		for _, node := range nodes {
+			if strings.HasPrefix(node.Name, prefix) {
+				result = append(result, node)
+			}
+			walk(node.ChildSnapshotList)
+		}
+	}
+	walk(props.Snapshot.RootSnapshotList)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreateTime.Before(result[j].CreateTime)
+	})
+	return result
+}
+
+// snapshotTreeIDs returns the managed object IDs of the supplied snapshot tree
+// nodes.
+func snapshotTreeIDs(nodes []types.VirtualMachineSnapshotTree) []string {
+	var ids []string
+	for _, node := range nodes {
+		ids = append(ids, node.Snapshot.Value)
+	}
+	return ids
+}
+
+// expiredVirtualMachineSnapshots returns the snapshots in the supplied list,
+// which must be sorted oldest first, that fall outside of a retention policy
+// of keeping the newest keepCount snapshots and no snapshots older than
+// maxAge, relative to now. A zero keepCount or maxAge disables that part of
+// the policy. The newest snapshot in the list is never expired.
+func expiredVirtualMachineSnapshots(snapshots []types.VirtualMachineSnapshotTree, keepCount int, maxAge time.Duration, now time.Time) []types.VirtualMachineSnapshotTree {
+	var expired []types.VirtualMachineSnapshotTree
+	for i, snapshot := range snapshots {
+		if i == len(snapshots)-1 {
+			break
+		}
+		switch {
+		case keepCount > 0 && len(snapshots)-i > keepCount:
+			expired = append(expired, snapshot)
+		case maxAge > 0 && now.Sub(snapshot.CreateTime) > maxAge:
+			expired = append(expired, snapshot)
+		}
+	}
+	return expired
+}
+
+// virtualMachineSnapshotRevertTimeout is the time that
+// revertVirtualMachineToSnapshot waits for the revert task to complete.
+// Reverting to a snapshot with memory loads the saved memory state back into
+// the virtual machine, which can take a lot longer than the default API
+// timeout for large virtual machines.
+const virtualMachineSnapshotRevertTimeout = time.Minute * 30
+
+// revertVirtualMachineToSnapshot reverts a virtual machine to the snapshot
+// with the supplied managed object ID. If the snapshot was taken while the
+// virtual machine was powered on, the virtual machine is powered on after
+// the revert. The revert task is waited on for up to
+// virtualMachineSnapshotRevertTimeout.
+func revertVirtualMachineToSnapshot(client *govmomi.Client, id string) error {
+	req := types.RevertToSnapshot_Task{
+		This: types.ManagedObjectReference{
+			Type:  "VirtualMachineSnapshot",
+			Value: id,
+		},
+		SuppressPowerOn: types.NewBool(false),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+	res, err := methods.RevertToSnapshot_Task(ctx, client.Client, &req)
+	if err != nil {
+		return err
+	}
+
+	t := object.NewTask(client.Client, res.Returnval)
+	tctx, tcancel := context.WithTimeout(context.Background(), virtualMachineSnapshotRevertTimeout)
+	defer tcancel()
+	return t.Wait(tctx)
+}
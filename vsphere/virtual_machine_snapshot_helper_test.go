@@ -0,0 +1,131 @@
+package vsphere
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testExpiredVirtualMachineSnapshots struct {
+	Name string
+
+	ages      []time.Duration
+	keepCount int
+	maxAge    time.Duration
+	expected  []string
+}
+
+func (tc *testExpiredVirtualMachineSnapshots) Test(t *testing.T) {
+	now := time.Now()
+	var snapshots []types.VirtualMachineSnapshotTree
+	for i, age := range tc.ages {
+		snapshots = append(snapshots, types.VirtualMachineSnapshotTree{
+			Snapshot:   types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: string('a' + rune(i))},
+			CreateTime: now.Add(-age),
+		})
+	}
+	actual := snapshotTreeIDs(expiredVirtualMachineSnapshots(snapshots, tc.keepCount, tc.maxAge, now))
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestExpiredVirtualMachineSnapshots(t *testing.T) {
+	cases := []testExpiredVirtualMachineSnapshots{
+		{
+			Name:     "no policy",
+			ages:     []time.Duration{3 * time.Hour, 2 * time.Hour, time.Hour},
+			expected: nil,
+		},
+		{
+			Name:      "keep count",
+			ages:      []time.Duration{3 * time.Hour, 2 * time.Hour, time.Hour},
+			keepCount: 2,
+			expected:  []string{"a"},
+		},
+		{
+			Name:     "max age",
+			ages:     []time.Duration{3 * time.Hour, 2 * time.Hour, time.Hour},
+			maxAge:   90 * time.Minute,
+			expected: []string{"a", "b"},
+		},
+		{
+			Name:      "keep count and max age",
+			ages:      []time.Duration{4 * time.Hour, 3 * time.Hour, 2 * time.Hour, time.Hour},
+			keepCount: 3,
+			maxAge:    150 * time.Minute,
+			expected:  []string{"a", "b"},
+		},
+		{
+			Name:     "newest never expires",
+			ages:     []time.Duration{3 * time.Hour, 2 * time.Hour},
+			maxAge:   time.Hour,
+			expected: []string{"a"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func testSnapshotTreeNode(id string, children ...types.VirtualMachineSnapshotTree) types.VirtualMachineSnapshotTree {
+	return types.VirtualMachineSnapshotTree{
+		Snapshot:          types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: id},
+		ChildSnapshotList: children,
+	}
+}
+
+type testSnapshotSubtreeContains struct {
+	Name string
+
+	ids      []string
+	expected bool
+}
+
+func (tc *testSnapshotSubtreeContains) Test(t *testing.T) {
+	// A chain of policy snapshots a -> b -> c, with a manual snapshot x as a
+	// second child of a.
+	node := testSnapshotTreeNode("a",
+		testSnapshotTreeNode("b", testSnapshotTreeNode("c")),
+		testSnapshotTreeNode("x"),
+	)
+	if actual := snapshotSubtreeContains(&node, tc.ids); actual != tc.expected {
+		t.Fatalf("expected %t, got %t", tc.expected, actual)
+	}
+}
+
+func TestSnapshotSubtreeContains(t *testing.T) {
+	cases := []testSnapshotSubtreeContains{
+		{
+			Name:     "no ids",
+			expected: false,
+		},
+		{
+			Name:     "node itself",
+			ids:      []string{"a"},
+			expected: false,
+		},
+		{
+			Name:     "direct child",
+			ids:      []string{"x"},
+			expected: true,
+		},
+		{
+			Name:     "deeper descendant",
+			ids:      []string{"c"},
+			expected: true,
+		},
+		{
+			Name:     "not in subtree",
+			ids:      []string{"d"},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
@@ -0,0 +1,124 @@
+package vsphere
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// windowsTimeZoneUTC is the Microsoft time zone index of GMT, which is used
+// for Windows guests when time_zone is left at its default of Etc/UTC.
+const windowsTimeZoneUTC = 85
+
+// windowsTimeZoneIndexes is the set of valid Microsoft time zone index values
+// that sysprep accepts.
+//
+// See https://msdn.microsoft.com/en-us/library/ms912391.aspx.
+var windowsTimeZoneIndexes = map[int]struct{}{
+	0: {}, 1: {}, 2: {}, 3: {}, 4: {}, 10: {}, 13: {}, 15: {}, 20: {}, 25: {},
+	30: {}, 33: {}, 35: {}, 40: {}, 45: {}, 50: {}, 55: {}, 56: {}, 60: {},
+	65: {}, 70: {}, 73: {}, 75: {}, 80: {}, 83: {}, 85: {}, 90: {}, 95: {},
+	100: {}, 105: {}, 110: {}, 113: {}, 115: {}, 120: {}, 125: {}, 130: {},
+	135: {}, 140: {}, 145: {}, 150: {}, 155: {}, 158: {}, 160: {}, 165: {},
+	170: {}, 175: {}, 180: {}, 185: {}, 190: {}, 193: {}, 195: {}, 200: {},
+	201: {}, 203: {}, 205: {}, 207: {}, 210: {}, 215: {}, 220: {}, 225: {},
+	227: {}, 230: {}, 235: {}, 240: {}, 245: {}, 250: {}, 255: {}, 260: {},
+	265: {}, 270: {}, 275: {}, 280: {}, 285: {}, 290: {}, 300: {}, 360: {},
+}
+
+// windowsTimeZoneIndex parses the time_zone of a virtual machine into a
+// Microsoft time zone index. Etc/UTC, the default, maps to GMT. An error is
+// returned if the value is not numeric or not a known index.
+func windowsTimeZoneIndex(tz string) (int32, error) {
+	if tz == "Etc/UTC" {
+		return windowsTimeZoneUTC, nil
+	}
+	i, err := strconv.Atoi(tz)
+	if err != nil {
+		return 0, fmt.Errorf("time_zone %q must be a numeric Microsoft time zone index for Windows guests", tz)
+	}
+	if _, ok := windowsTimeZoneIndexes[i]; !ok {
+		return 0, fmt.Errorf("time_zone %q is not a valid Microsoft time zone index", tz)
+	}
+	return int32(i), nil
+}
+
+// validateWindowsTimeZoneIndex is a schema.SchemaValidateFunc that checks
+// that an integer is a valid Microsoft time zone index.
+func validateWindowsTimeZoneIndex(v interface{}, k string) ([]string, []error) {
+	if _, ok := windowsTimeZoneIndexes[v.(int)]; !ok {
+		return nil, []error{fmt.Errorf("%s: %d is not a valid Microsoft time zone index", k, v.(int))}
+	}
+	return nil, nil
+}
+
+// usesSysprepIdentity returns true if a clone of a template with the supplied
+// guest ID is customized with the identity built by sysprepIdentity from the
+// Windows options and time zone of the virtual machine.
+func (vm *virtualMachine) usesSysprepIdentity(guestID string) bool {
+	return !vm.skipCustomization &&
+		vm.customizationSpecName == "" &&
+		strings.HasPrefix(guestID, "win") &&
+		vm.windowsOptionalConfig.sysprepText == ""
+}
+
+// sysprepIdentity returns the customization identity for a Windows clone
+// with the supplied computer name and time zone.
+//
+// If sysprep_text is set, its answer file is used as is, and all other
+// Windows options are ignored. Otherwise, a Sysprep identity is built from the
+// options. The domain is only joined if the domain, the domain user and its
+// password are all set.
+func (w windowsOptConfig) sysprepIdentity(computerName, timeZone string) (types.BaseCustomizationIdentitySettings, error) {
+	if w.sysprepText != "" {
+		return &types.CustomizationSysprepText{Value: w.sysprepText}, nil
+	}
+
+	tz, err := windowsTimeZoneIndex(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	sysprep := &types.CustomizationSysprep{
+		GuiUnattended: types.CustomizationGuiUnattended{
+			AutoLogon:      w.autoLogon,
+			AutoLogonCount: w.autoLogonCount,
+			TimeZone:       tz,
+		},
+		UserData: types.CustomizationUserData{
+			ComputerName: &types.CustomizationFixedName{
+				Name: computerName,
+			},
+			ProductId: w.productKey,
+			FullName:  w.fullName,
+			OrgName:   w.orgName,
+		},
+	}
+
+	if w.adminPassword != "" {
+		sysprep.GuiUnattended.Password = &types.CustomizationPassword{
+			PlainText: true,
+			Value:     w.adminPassword,
+		}
+	}
+
+	if w.domainUserPassword != "" && w.domainUser != "" && w.domain != "" {
+		sysprep.Identification.DomainAdminPassword = &types.CustomizationPassword{
+			PlainText: true,
+			Value:     w.domainUserPassword,
+		}
+		sysprep.Identification.DomainAdmin = w.domainUser
+		sysprep.Identification.JoinDomain = w.domain
+	} else if w.workgroup != "" {
+		sysprep.Identification.JoinWorkgroup = w.workgroup
+	}
+
+	if len(w.runOnceCommands) > 0 {
+		sysprep.GuiRunOnce = &types.CustomizationGuiRunOnce{
+			CommandList: w.runOnceCommands,
+		}
+	}
+	return sysprep, nil
+}
@@ -0,0 +1,199 @@
+package vsphere
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type testWindowsTimeZoneIndex struct {
+	Name string
+
+	tz          string
+	expected    int32
+	expectedErr *regexp.Regexp
+}
+
+func (tc *testWindowsTimeZoneIndex) Test(t *testing.T) {
+	actual, err := windowsTimeZoneIndex(tc.tz)
+	if err != nil && tc.expectedErr == nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if tc.expectedErr != nil {
+		testMatchError(t, err, tc.expectedErr)
+		return
+	}
+	if actual != tc.expected {
+		t.Fatalf("expected %d, got %d", tc.expected, actual)
+	}
+}
+
+func TestWindowsTimeZoneIndex(t *testing.T) {
+	cases := []testWindowsTimeZoneIndex{
+		{
+			Name:     "default",
+			tz:       "Etc/UTC",
+			expected: 85,
+		},
+		{
+			Name:     "leading zero",
+			tz:       "035",
+			expected: 35,
+		},
+		{
+			Name:        "not numeric",
+			tz:          "America/New_York",
+			expectedErr: regexp.MustCompile(`time_zone "America/New_York" must be a numeric Microsoft time zone index`),
+		},
+		{
+			Name:        "unknown index",
+			tz:          "86",
+			expectedErr: regexp.MustCompile(`time_zone "86" is not a valid Microsoft time zone index`),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+type testSysprepIdentity struct {
+	Name string
+
+	config   windowsOptConfig
+	expected types.BaseCustomizationIdentitySettings
+}
+
+func (tc *testSysprepIdentity) Test(t *testing.T) {
+	actual, err := tc.config.sysprepIdentity("win01", "Etc/UTC")
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestSysprepIdentity(t *testing.T) {
+	cases := []testSysprepIdentity{
+		{
+			Name: "workgroup and run once",
+			config: windowsOptConfig{
+				workgroup:       "TERRAFORM",
+				fullName:        "ops",
+				orgName:         "example",
+				autoLogon:       true,
+				autoLogonCount:  2,
+				adminPassword:   "secret",
+				runOnceCommands: []string{"cmd.exe /c echo hi"},
+			},
+			expected: &types.CustomizationSysprep{
+				GuiUnattended: types.CustomizationGuiUnattended{
+					AutoLogon:      true,
+					AutoLogonCount: 2,
+					TimeZone:       85,
+					Password:       &types.CustomizationPassword{PlainText: true, Value: "secret"},
+				},
+				UserData: types.CustomizationUserData{
+					ComputerName: &types.CustomizationFixedName{Name: "win01"},
+					FullName:     "ops",
+					OrgName:      "example",
+				},
+				Identification: types.CustomizationIdentification{
+					JoinWorkgroup: "TERRAFORM",
+				},
+				GuiRunOnce: &types.CustomizationGuiRunOnce{
+					CommandList: []string{"cmd.exe /c echo hi"},
+				},
+			},
+		},
+		{
+			Name: "domain join",
+			config: windowsOptConfig{
+				domain:             "corp.example.com",
+				domainUser:         "join",
+				domainUserPassword: "secret",
+				fullName:           "terraform",
+				orgName:            "terraform",
+				autoLogonCount:     1,
+			},
+			expected: &types.CustomizationSysprep{
+				GuiUnattended: types.CustomizationGuiUnattended{
+					AutoLogonCount: 1,
+					TimeZone:       85,
+				},
+				UserData: types.CustomizationUserData{
+					ComputerName: &types.CustomizationFixedName{Name: "win01"},
+					FullName:     "terraform",
+					OrgName:      "terraform",
+				},
+				Identification: types.CustomizationIdentification{
+					JoinDomain:          "corp.example.com",
+					DomainAdmin:         "join",
+					DomainAdminPassword: &types.CustomizationPassword{PlainText: true, Value: "secret"},
+				},
+			},
+		},
+		{
+			Name: "sysprep text",
+			config: windowsOptConfig{
+				sysprepText: "<unattend/>",
+				fullName:    "terraform",
+			},
+			expected: &types.CustomizationSysprepText{Value: "<unattend/>"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+type testUsesSysprepIdentity struct {
+	Name string
+
+	vm       virtualMachine
+	guestID  string
+	expected bool
+}
+
+func (tc *testUsesSysprepIdentity) Test(t *testing.T) {
+	if actual := tc.vm.usesSysprepIdentity(tc.guestID); actual != tc.expected {
+		t.Fatalf("expected %t, got %t", tc.expected, actual)
+	}
+}
+
+func TestUsesSysprepIdentity(t *testing.T) {
+	cases := []testUsesSysprepIdentity{
+		{
+			Name:     "windows template",
+			guestID:  "windows9Server64Guest",
+			expected: true,
+		},
+		{
+			Name:    "linux template",
+			guestID: "ubuntu64Guest",
+		},
+		{
+			Name:    "customization skipped",
+			vm:      virtualMachine{skipCustomization: true},
+			guestID: "windows9Server64Guest",
+		},
+		{
+			Name:    "stored customization spec",
+			vm:      virtualMachine{customizationSpecName: "win"},
+			guestID: "windows9Server64Guest",
+		},
+		{
+			Name:    "sysprep text",
+			vm:      virtualMachine{windowsOptionalConfig: windowsOptConfig{sysprepText: "<unattend/>"}},
+			guestID: "windows9Server64Guest",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
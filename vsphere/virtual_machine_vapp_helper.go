@@ -0,0 +1,111 @@
+package vsphere
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// vAppConfigProperties returns the vApp properties defined in the supplied
+// vApp configuration of a virtual machine or template. A nil slice is
+// returned if the virtual machine has no vApp configuration.
+func vAppConfigProperties(config types.BaseVmConfigInfo) []types.VAppPropertyInfo {
+	if config == nil {
+		return nil
+	}
+	return config.GetVmConfigInfo().Property
+}
+
+// expandVAppConfigSpec builds a vApp configuration spec that sets the vApp
+// properties in new, and resets the properties in old that are not in new to
+// their default values. Property keys are matched against the IDs of the
+// properties defined in the supplied vApp configuration, which is normally
+// that of the template the virtual machine is cloned from, or of the virtual
+// machine itself on update.
+//
+// An error is returned if a key is not defined in the configuration, or if
+// the property it refers to is not user configurable. A nil spec is returned
+// if there is nothing to change.
+func expandVAppConfigSpec(config types.BaseVmConfigInfo, old, new map[string]interface{}) (*types.VmConfigSpec, error) {
+	defined := make(map[string]types.VAppPropertyInfo)
+	for _, p := range vAppConfigProperties(config) {
+		defined[p.Id] = p
+	}
+
+	var keys []string
+	for k := range new {
+		keys = append(keys, k)
+	}
+	for k := range old {
+		if _, ok := new[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var specs []types.VAppPropertySpec
+	for _, k := range keys {
+		p, ok := defined[k]
+		if !ok {
+			if _, ok := new[k]; !ok {
+				// A property that has been removed from the configuration as well as
+				// from the virtual machine can be dropped silently.
+				continue
+			}
+			return nil, fmt.Errorf("vApp property %q is not defined. Defined properties: %s", k, strings.Join(vAppPropertyIDs(config), ", "))
+		}
+		if p.UserConfigurable == nil || !*p.UserConfigurable {
+			return nil, fmt.Errorf("vApp property %q is not user configurable", k)
+		}
+		value := p.DefaultValue
+		if v, ok := new[k]; ok {
+			value = v.(string)
+		}
+		specs = append(specs, types.VAppPropertySpec{
+			ArrayUpdateSpec: types.ArrayUpdateSpec{
+				Operation: types.ArrayUpdateOperationEdit,
+			},
+			Info: &types.VAppPropertyInfo{
+				Key:   p.Key,
+				Id:    p.Id,
+				Value: value,
+			},
+		})
+	}
+	if len(specs) < 1 {
+		return nil, nil
+	}
+	return &types.VmConfigSpec{Property: specs}, nil
+}
+
+// flattenVAppProperties returns the current values of the vApp properties in
+// the supplied vApp configuration whose IDs are keys in managed. Only managed
+// properties are read back, as a template can define many properties that the
+// configuration does not set.
+func flattenVAppProperties(config types.BaseVmConfigInfo, managed map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, p := range vAppConfigProperties(config) {
+		if _, ok := managed[p.Id]; !ok {
+			continue
+		}
+		value := p.Value
+		if value == "" {
+			value = p.DefaultValue
+		}
+		result[p.Id] = value
+	}
+	return result
+}
+
+// vAppPropertyIDs returns the sorted IDs of the vApp properties defined in the
+// supplied vApp configuration.
+func vAppPropertyIDs(config types.BaseVmConfigInfo) []string {
+	var ids []string
+	for _, p := range vAppConfigProperties(config) {
+		ids = append(ids, p.Id)
+	}
+	sort.Strings(ids)
+	return ids
+}
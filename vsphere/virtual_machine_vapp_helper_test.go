@@ -0,0 +1,103 @@
+package vsphere
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var testVAppConfig = &types.VmConfigInfo{
+	Property: []types.VAppPropertyInfo{
+		{Key: 0, Id: "hostname", DefaultValue: "localhost", UserConfigurable: types.NewBool(true)},
+		{Key: 1, Id: "ip0", Value: "10.0.0.10", UserConfigurable: types.NewBool(true)},
+		{Key: 2, Id: "version", Value: "1.0", UserConfigurable: types.NewBool(false)},
+	},
+}
+
+type testExpandVAppConfigSpec struct {
+	Name string
+
+	old         map[string]interface{}
+	new         map[string]interface{}
+	expected    *types.VmConfigSpec
+	expectedErr *regexp.Regexp
+}
+
+func testVAppPropertySpec(key int32, id, value string) types.VAppPropertySpec {
+	return types.VAppPropertySpec{
+		ArrayUpdateSpec: types.ArrayUpdateSpec{Operation: types.ArrayUpdateOperationEdit},
+		Info:            &types.VAppPropertyInfo{Key: key, Id: id, Value: value},
+	}
+}
+
+func (tc *testExpandVAppConfigSpec) Test(t *testing.T) {
+	actual, err := expandVAppConfigSpec(testVAppConfig, tc.old, tc.new)
+	if err != nil && tc.expectedErr == nil {
+		t.Fatalf("bad: %s", err)
+	}
+	if tc.expectedErr != nil {
+		testMatchError(t, err, tc.expectedErr)
+		return
+	}
+	if !reflect.DeepEqual(tc.expected, actual) {
+		t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+	}
+}
+
+func TestExpandVAppConfigSpec(t *testing.T) {
+	cases := []testExpandVAppConfigSpec{
+		{
+			Name: "set",
+			new:  map[string]interface{}{"hostname": "vm1", "ip0": "10.0.0.20"},
+			expected: &types.VmConfigSpec{
+				Property: []types.VAppPropertySpec{
+					testVAppPropertySpec(0, "hostname", "vm1"),
+					testVAppPropertySpec(1, "ip0", "10.0.0.20"),
+				},
+			},
+		},
+		{
+			Name: "removed property reset to default",
+			old:  map[string]interface{}{"hostname": "vm1", "ip0": "10.0.0.20"},
+			new:  map[string]interface{}{"ip0": "10.0.0.20"},
+			expected: &types.VmConfigSpec{
+				Property: []types.VAppPropertySpec{
+					testVAppPropertySpec(0, "hostname", "localhost"),
+					testVAppPropertySpec(1, "ip0", "10.0.0.20"),
+				},
+			},
+		},
+		{
+			Name:     "nothing to change",
+			old:      map[string]interface{}{"gone": "value"},
+			expected: nil,
+		},
+		{
+			Name:        "undefined property",
+			new:         map[string]interface{}{"netmask0": "255.255.255.0"},
+			expectedErr: regexp.MustCompile(`vApp property "netmask0" is not defined. Defined properties: hostname, ip0, version`),
+		},
+		{
+			Name:        "not user configurable",
+			new:         map[string]interface{}{"version": "2.0"},
+			expectedErr: regexp.MustCompile(`vApp property "version" is not user configurable`),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, tc.Test)
+	}
+}
+
+func TestFlattenVAppProperties(t *testing.T) {
+	expected := map[string]interface{}{
+		"hostname": "localhost",
+		"ip0":      "10.0.0.10",
+	}
+	actual := flattenVAppProperties(testVAppConfig, map[string]interface{}{"hostname": "", "ip0": ""})
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}